@@ -0,0 +1,181 @@
+package geobabel
+
+import "github.com/paulmach/orb"
+
+// The Append functions append to and return a caller-supplied slice, like the
+// built-in append, so that callers can reuse slices between conversions. Ends
+// are offsets into the full flat coordinates, including any that were already
+// in dst. When appending to slices of slices, slices in dst's spare capacity
+// are reused, so truncating a result to length zero and passing it as dst
+// reuses all of its memory.
+
+// AppendGeomFlatCoordsFromOrbPoint appends the flat coordinates of orbPoint to
+// dst.
+func AppendGeomFlatCoordsFromOrbPoint(dst []float64, orbPoint orb.Point) []float64 {
+	return appendGeomFlatCoordsFromOrbPoint(dst, orbPoint, nil)
+}
+
+// AppendGeomFlatCoordsFromOrbLineString appends the flat coordinates of
+// orbLineString to dst.
+func AppendGeomFlatCoordsFromOrbLineString(dst []float64, orbLineString orb.LineString) []float64 {
+	return appendGeomFlatCoordsFromOrbPoints(dst, orbLineString, nil)
+}
+
+// AppendGeomFlatCoordsFromOrbRing appends the flat coordinates of orbRing to
+// dst.
+func AppendGeomFlatCoordsFromOrbRing(dst []float64, orbRing orb.Ring) []float64 {
+	return appendGeomFlatCoordsFromOrbPoints(dst, orbRing, nil)
+}
+
+// AppendGeomFlatCoordsFromOrbPolygon appends the flat coordinates and ends of
+// orbPolygon to dst and dstEnds.
+func AppendGeomFlatCoordsFromOrbPolygon(dst []float64, dstEnds []int, orbPolygon orb.Polygon) ([]float64, []int) {
+	return appendGeomFlatCoordsFromOrbPolygon(dst, dstEnds, orbPolygon, nil)
+}
+
+// AppendGeomFlatCoordsFromOrbMultiPoint appends the flat coordinates of
+// orbMultiPoint to dst.
+func AppendGeomFlatCoordsFromOrbMultiPoint(dst []float64, orbMultiPoint orb.MultiPoint) []float64 {
+	return appendGeomFlatCoordsFromOrbPoints(dst, orbMultiPoint, nil)
+}
+
+// AppendGeomFlatCoordsFromOrbMultiLineString appends the flat coordinates and
+// ends of orbMultiLineString to dst and dstEnds.
+func AppendGeomFlatCoordsFromOrbMultiLineString(dst []float64, dstEnds []int, orbMultiLineString orb.MultiLineString) ([]float64, []int) {
+	return appendGeomFlatCoordsFromOrbMultiLineString(dst, dstEnds, orbMultiLineString, nil)
+}
+
+// AppendGeomFlatCoordsFromOrbMultiPolygon appends the flat coordinates and
+// endss of orbMultiPolygon to dst and dstEndss.
+func AppendGeomFlatCoordsFromOrbMultiPolygon(dst []float64, dstEndss [][]int, orbMultiPolygon orb.MultiPolygon) ([]float64, [][]int) {
+	return appendGeomFlatCoordsFromOrbMultiPolygon(dst, dstEndss, orbMultiPolygon, nil)
+}
+
+// AppendOrbLineStringFromGeomFlatCoords appends the points of geomFlatCoords,
+// which has stride geomStride, to dst.
+func AppendOrbLineStringFromGeomFlatCoords(dst orb.LineString, geomFlatCoords []float64, geomStride int) orb.LineString {
+	return appendOrbPointsFromGeomFlatCoords(dst, geomFlatCoords, geomStride, nil)
+}
+
+// AppendOrbRingFromGeomFlatCoords appends the points of geomFlatCoords, which
+// has stride geomStride, to dst.
+func AppendOrbRingFromGeomFlatCoords(dst orb.Ring, geomFlatCoords []float64, geomStride int) orb.Ring {
+	return appendOrbPointsFromGeomFlatCoords(dst, geomFlatCoords, geomStride, nil)
+}
+
+// AppendOrbPolygonFromGeomFlatCoords appends the rings of geomFlatCoords and
+// geomEnds, which has stride geomStride, to dst.
+func AppendOrbPolygonFromGeomFlatCoords(dst orb.Polygon, geomFlatCoords []float64, geomEnds []int, geomStride int) orb.Polygon {
+	return appendOrbPolygonFromGeomFlatCoords(dst, geomFlatCoords, 0, geomEnds, geomStride, nil)
+}
+
+// AppendOrbMultiLineStringFromGeomFlatCoords appends the line strings of
+// geomFlatCoords and geomEnds, which has stride geomStride, to dst.
+func AppendOrbMultiLineStringFromGeomFlatCoords(dst orb.MultiLineString, geomFlatCoords []float64, geomEnds []int, geomStride int) orb.MultiLineString {
+	return appendOrbMultiLineStringFromGeomFlatCoords(dst, geomFlatCoords, geomEnds, geomStride, nil)
+}
+
+// AppendOrbMultiPolygonFromGeomFlatCoords appends the polygons of
+// geomFlatCoords and geomEndss, which has stride geomStride, to dst.
+func AppendOrbMultiPolygonFromGeomFlatCoords(dst orb.MultiPolygon, geomFlatCoords []float64, geomEndss [][]int, geomStride int) orb.MultiPolygon {
+	return appendOrbMultiPolygonFromGeomFlatCoords(dst, geomFlatCoords, geomEndss, geomStride, nil)
+}
+
+// The internal append functions apply transform, if it is not nil, to each
+// point as it is copied.
+
+func appendGeomFlatCoordsFromOrbPoint(dst []float64, orbPoint orb.Point, transform TransformFunc) []float64 {
+	x, y := orbPoint[0], orbPoint[1]
+	if transform != nil {
+		x, y = transform(x, y)
+	}
+	return append(dst, x, y)
+}
+
+func appendGeomFlatCoordsFromOrbPoints[S ~[]orb.Point](dst []float64, orbPoints S, transform TransformFunc) []float64 {
+	for _, orbPoint := range orbPoints {
+		dst = appendGeomFlatCoordsFromOrbPoint(dst, orbPoint, transform)
+	}
+	return dst
+}
+
+func appendGeomFlatCoordsFromOrbPolygon(dst []float64, dstEnds []int, orbPolygon orb.Polygon, transform TransformFunc) ([]float64, []int) {
+	for _, orbRing := range orbPolygon {
+		dst = appendGeomFlatCoordsFromOrbPoints(dst, orbRing, transform)
+		dstEnds = append(dstEnds, len(dst))
+	}
+	return dst, dstEnds
+}
+
+func appendGeomFlatCoordsFromOrbMultiLineString(dst []float64, dstEnds []int, orbMultiLineString orb.MultiLineString, transform TransformFunc) ([]float64, []int) {
+	for _, orbLineString := range orbMultiLineString {
+		dst = appendGeomFlatCoordsFromOrbPoints(dst, orbLineString, transform)
+		dstEnds = append(dstEnds, len(dst))
+	}
+	return dst, dstEnds
+}
+
+func appendGeomFlatCoordsFromOrbMultiPolygon(dst []float64, dstEndss [][]int, orbMultiPolygon orb.MultiPolygon, transform TransformFunc) ([]float64, [][]int) {
+	for _, orbPolygon := range orbMultiPolygon {
+		var dstEnds []int
+		dst, dstEnds = appendGeomFlatCoordsFromOrbPolygon(dst, spareElem(dstEndss), orbPolygon, transform)
+		dstEndss = append(dstEndss, dstEnds)
+	}
+	return dst, dstEndss
+}
+
+func appendOrbPointFromGeomCoord(dst []orb.Point, geomCoord []float64, transform TransformFunc) []orb.Point {
+	x, y := geomCoord[0], geomCoord[1]
+	if transform != nil {
+		x, y = transform(x, y)
+	}
+	return append(dst, orb.Point{x, y})
+}
+
+func appendOrbPointsFromGeomFlatCoords[S ~[]orb.Point](dst S, geomFlatCoords []float64, geomStride int, transform TransformFunc) S {
+	for i := 0; i < len(geomFlatCoords); i += geomStride {
+		dst = appendOrbPointFromGeomCoord(dst, geomFlatCoords[i:i+2], transform)
+	}
+	return dst
+}
+
+func appendOrbPolygonFromGeomFlatCoords(dst orb.Polygon, geomFlatCoords []float64, geomStart int, geomEnds []int, geomStride int, transform TransformFunc) orb.Polygon {
+	for _, geomEnd := range geomEnds {
+		orbRing := appendOrbPointsFromGeomFlatCoords(spareElem(dst), geomFlatCoords[geomStart:geomEnd], geomStride, transform)
+		dst = append(dst, orbRing)
+		geomStart = geomEnd
+	}
+	return dst
+}
+
+func appendOrbMultiLineStringFromGeomFlatCoords(dst orb.MultiLineString, geomFlatCoords []float64, geomEnds []int, geomStride int, transform TransformFunc) orb.MultiLineString {
+	geomStart := 0
+	for _, geomEnd := range geomEnds {
+		orbLineString := appendOrbPointsFromGeomFlatCoords(spareElem(dst), geomFlatCoords[geomStart:geomEnd], geomStride, transform)
+		dst = append(dst, orbLineString)
+		geomStart = geomEnd
+	}
+	return dst
+}
+
+func appendOrbMultiPolygonFromGeomFlatCoords(dst orb.MultiPolygon, geomFlatCoords []float64, geomEndss [][]int, geomStride int, transform TransformFunc) orb.MultiPolygon {
+	geomStart := 0
+	for _, geomEnds := range geomEndss {
+		orbPolygon := appendOrbPolygonFromGeomFlatCoords(spareElem(dst), geomFlatCoords, geomStart, geomEnds, geomStride, transform)
+		dst = append(dst, orbPolygon)
+		if len(geomEnds) > 0 {
+			geomStart = geomEnds[len(geomEnds)-1]
+		}
+	}
+	return dst
+}
+
+// spareElem returns the element of s just beyond its length, truncated to
+// length zero, so that its memory can be reused. It returns nil if s has no
+// spare capacity.
+func spareElem[S ~[]E, E ~[]T, T any](s S) E {
+	if len(s) == cap(s) {
+		return nil
+	}
+	return s[:len(s)+1][len(s)][:0]
+}
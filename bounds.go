@@ -0,0 +1,87 @@
+package geobabel
+
+import (
+	"github.com/paulmach/orb"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geos"
+)
+
+// Empty bounds are converted to the empty bounds of the target library, and
+// GEOS represents bounds as polygons, like orb.Bound.ToPolygon. The ring of a
+// bounds polygon starts at the minimum and is counterclockwise.
+
+// NewGeomBoundsFromOrbBound returns a new *geom.Bounds converted from
+// orbBound.
+func NewGeomBoundsFromOrbBound(orbBound orb.Bound) *geom.Bounds {
+	if orbBound.IsEmpty() {
+		return geom.NewBounds(geom.XY)
+	}
+	return geom.NewBounds(geom.XY).Set(orbBound.Min[0], orbBound.Min[1], orbBound.Max[0], orbBound.Max[1])
+}
+
+// NewGEOSGeomFromOrbBound returns a new polygon converted from orbBound.
+// Degenerate bounds are converted to degenerate polygons.
+func NewGEOSGeomFromOrbBound(geosContext *geos.Context, orbBound orb.Bound, options ...Option) *geos.Geom {
+	return NewGEOSGeomFromOrbGeometry(geosContext, orbBound, options...)
+}
+
+// NewGEOSGeomFromOrbBoundE is like NewGEOSGeomFromOrbBound but returns a
+// *ConvertError if orbBound cannot be converted.
+func NewGEOSGeomFromOrbBoundE(geosContext *geos.Context, orbBound orb.Bound, options ...Option) (*geos.Geom, error) {
+	return NewGEOSGeomFromOrbGeometryE(geosContext, orbBound, options...)
+}
+
+func NewOrbBoundFromGeomBounds(geomBounds *geom.Bounds, options ...Option) orb.Bound {
+	orbBound, err := NewOrbBoundFromGeomBoundsE(geomBounds, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbBound
+}
+
+// NewOrbBoundFromGeomBoundsE returns a new orb.Bound converted from
+// geomBounds. Z and M ordinates are handled according to the ZM policy, and
+// it returns a *ConvertError wrapping ErrUnsupportedLayout if they cannot be
+// dropped.
+func NewOrbBoundFromGeomBoundsE(geomBounds *geom.Bounds, options ...Option) (orb.Bound, error) {
+	if geomBounds.IsEmpty() {
+		return orbEmptyBound(), nil
+	}
+	switch geomLayout := geomBounds.Layout(); {
+	case geomLayout == geom.XY:
+	case newConverter(options).zmPolicy == ZMPolicyDrop:
+	default:
+		return orb.Bound{}, newUnsupportedLayoutError("*geom.Bounds", geomLayout.String())
+	}
+	return orb.Bound{
+		Min: orb.Point{geomBounds.Min(0), geomBounds.Min(1)},
+		Max: orb.Point{geomBounds.Max(0), geomBounds.Max(1)},
+	}, nil
+}
+
+// NewOrbBoundFromGEOSGeom returns a new orb.Bound containing geosGeom.
+func NewOrbBoundFromGEOSGeom(geosGeom *geos.Geom) orb.Bound {
+	geosBounds := geosGeom.Bounds()
+	if geosBounds.IsEmpty() {
+		return orbEmptyBound()
+	}
+	return orb.Bound{
+		Min: orb.Point{geosBounds.MinX, geosBounds.MinY},
+		Max: orb.Point{geosBounds.MaxX, geosBounds.MaxY},
+	}
+}
+
+// orbPolygonFromOrbBound returns orbBound as a closed five-point polygon, like
+// orb's encoders, or as an empty polygon if orbBound is empty.
+func orbPolygonFromOrbBound(orbBound orb.Bound) orb.Polygon {
+	if orbBound.IsEmpty() {
+		return orb.Polygon{}
+	}
+	return orbBound.ToPolygon()
+}
+
+// orbEmptyBound returns the orb.Bound that represents empty bounds, which is
+// the one returned by orb's Bound methods for empty geometries.
+func orbEmptyBound() orb.Bound {
+	return orb.Bound{Min: orb.Point{1, 1}, Max: orb.Point{-1, -1}}
+}
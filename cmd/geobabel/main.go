@@ -0,0 +1,300 @@
+// Command geobabel converts geometries between formats.
+//
+// Usage:
+//
+//	geobabel convert [flags] [file...]
+//
+// Geometries are read from the files, or from the standard input if no files
+// are given, and written to the standard output. Text formats contain one
+// geometry per line and GeoJSON inputs may contain several concatenated
+// geometries. Binary formats contain a single geometry, so converting several
+// geometries to a binary format is an error.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/twpayne/go-geom"
+
+	"github.com/twpayne/go-geobabel"
+)
+
+// Formats.
+const (
+	formatAuto    = "auto"
+	formatEWKB    = "ewkb"
+	formatEWKT    = "ewkt"
+	formatGeoJSON = "geojson"
+	formatHexEWKB = "hexewkb"
+	formatHexWKB  = "hexwkb"
+	formatWKB     = "wkb"
+	formatWKT     = "wkt"
+)
+
+var (
+	inputFormats  = []string{formatAuto, formatEWKB, formatEWKT, formatGeoJSON, formatHexEWKB, formatHexWKB, formatWKB, formatWKT}
+	outputFormats = []string{formatEWKB, formatEWKT, formatGeoJSON, formatHexEWKB, formatHexWKB, formatWKB, formatWKT}
+)
+
+// A convertConfig is the configuration of the convert command.
+type convertConfig struct {
+	from             string
+	to               string
+	srid             int
+	maxDecimalDigits int
+	numGeometries    int
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "geobabel:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: geobabel convert [flags] [file...]")
+	}
+	switch command, args := args[0], args[1:]; command {
+	case "convert":
+		return runConvert(args, stdin, stdout, stderr)
+	default:
+		return fmt.Errorf("%s: unknown command", command)
+	}
+}
+
+func runConvert(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	var config convertConfig
+	flagSet := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	flagSet.StringVar(&config.from, "from", formatAuto, "input format ("+strings.Join(inputFormats, ", ")+")")
+	flagSet.StringVar(&config.to, "to", formatEWKT, "output format ("+strings.Join(outputFormats, ", ")+")")
+	flagSet.IntVar(&config.srid, "srid", 0, "set the SRID of output geometries")
+	flagSet.IntVar(&config.maxDecimalDigits, "max-decimal-digits", -1, "maximum decimal digits in text output, -1 for the minimum that represents each coordinate exactly")
+	switch err := flagSet.Parse(args); {
+	case errors.Is(err, flag.ErrHelp):
+		return nil
+	case err != nil:
+		return err
+	}
+	if !contains(inputFormats, config.from) {
+		return fmt.Errorf("%s: unsupported input format", config.from)
+	}
+	if !contains(outputFormats, config.to) {
+		return fmt.Errorf("%s: unsupported output format", config.to)
+	}
+
+	w := bufio.NewWriter(stdout)
+	if flagSet.NArg() == 0 {
+		if err := config.convert(w, stdin); err != nil {
+			return err
+		}
+	}
+	for _, filename := range flagSet.Args() {
+		if err := config.convertFile(w, filename); err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+	return w.Flush()
+}
+
+func (config *convertConfig) convertFile(w io.Writer, filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return config.convert(w, file)
+}
+
+// convert converts the geometries in r and writes them to w.
+func (config *convertConfig) convert(w io.Writer, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	geomTs, err := decode(config.from, data)
+	if err != nil {
+		return err
+	}
+	for _, geomT := range geomTs {
+		if config.numGeometries > 0 && (config.to == formatWKB || config.to == formatEWKB) {
+			return fmt.Errorf("%s: output format only supports a single geometry", config.to)
+		}
+		if config.srid != 0 {
+			if geomT, err = geom.SetSRID(geomT, config.srid); err != nil {
+				return err
+			}
+		}
+		output, err := config.encode(geomT)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(output); err != nil {
+			return err
+		}
+		config.numGeometries++
+	}
+	return nil
+}
+
+// decode returns the geometries in data, which has format.
+func decode(format string, data []byte) ([]geom.T, error) {
+	if format == formatAuto {
+		format = detectFormat(data)
+	}
+	switch format {
+	case formatWKB:
+		geomT, err := geobabel.NewGeomTFromWKB(data)
+		if err != nil {
+			return nil, err
+		}
+		return []geom.T{geomT}, nil
+	case formatEWKB:
+		geomT, err := geobabel.NewGeomTFromEWKB(data)
+		if err != nil {
+			return nil, err
+		}
+		return []geom.T{geomT}, nil
+	case formatGeoJSON:
+		var geomTs []geom.T
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var geoJSON json.RawMessage
+			switch err := decoder.Decode(&geoJSON); {
+			case errors.Is(err, io.EOF):
+				return geomTs, nil
+			case err != nil:
+				return nil, err
+			}
+			geomT, err := geobabel.NewGeomTFromGeoJSON(geoJSON)
+			if err != nil {
+				return nil, err
+			}
+			geomTs = append(geomTs, geomT)
+		}
+	default:
+		var geomTs []geom.T
+		for i, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			geomT, err := decodeLine(format, line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			geomTs = append(geomTs, geomT)
+		}
+		return geomTs, nil
+	}
+}
+
+// decodeLine returns the geometry in line, which has a text format.
+func decodeLine(format, line string) (geom.T, error) {
+	if format == formatAuto {
+		format = detectLineFormat(line)
+	}
+	switch format {
+	case formatHexWKB, formatHexEWKB:
+		return geobabel.NewGeomTFromHexWKB(line)
+	case formatWKT:
+		return geobabel.NewGeomTFromWKT(line)
+	default:
+		return geobabel.NewGeomTFromEWKT(line)
+	}
+}
+
+// detectFormat returns the format of data. Binary data is EWKB, and the format
+// of each line of text data is detected separately.
+func detectFormat(data []byte) string {
+	data = bytes.TrimSpace(data)
+	switch {
+	case len(data) == 0:
+		return formatAuto
+	case data[0] == 0 || data[0] == 1:
+		// WKB starts with its byte order, which is 0 or 1.
+		return formatEWKB
+	case data[0] == '{':
+		return formatGeoJSON
+	default:
+		return formatAuto
+	}
+}
+
+// detectLineFormat returns the format of line, which is hex EWKB if it only
+// contains hex digits and otherwise EWKT. EWKB and EWKT are supersets of WKB
+// and WKT.
+func detectLineFormat(line string) string {
+	if strings.Trim(strings.TrimPrefix(line, `\x`), "0123456789ABCDEFabcdef") == "" {
+		return formatHexEWKB
+	}
+	return formatEWKT
+}
+
+// encode returns geomT encoded in config's output format.
+func (config *convertConfig) encode(geomT geom.T) ([]byte, error) {
+	options := []geobabel.Option{
+		geobabel.WithMaxDecimalDigits(config.maxDecimalDigits),
+	}
+	switch config.to {
+	case formatWKB:
+		return geobabel.WKBFromGeomT(geomT, options...)
+	case formatEWKB:
+		return geobabel.EWKBFromGeomT(geomT, options...)
+	case formatHexWKB:
+		hexWKB, err := geobabel.HexWKBFromGeomT(geomT, options...)
+		if err != nil {
+			return nil, err
+		}
+		return appendNewline([]byte(hexWKB)), nil
+	case formatHexEWKB:
+		hexEWKB, err := geobabel.HexEWKBFromGeomT(geomT, options...)
+		if err != nil {
+			return nil, err
+		}
+		return appendNewline([]byte(hexEWKB)), nil
+	case formatWKT:
+		wkt, err := geobabel.WKTFromGeomT(geomT, options...)
+		if err != nil {
+			return nil, err
+		}
+		return appendNewline([]byte(wkt)), nil
+	case formatEWKT:
+		ewkt, err := geobabel.EWKTFromGeomT(geomT, options...)
+		if err != nil {
+			return nil, err
+		}
+		return appendNewline([]byte(ewkt)), nil
+	case formatGeoJSON:
+		geoJSON, err := geobabel.GeoJSONFromGeomT(geomT, options...)
+		if err != nil {
+			return nil, err
+		}
+		return appendNewline(geoJSON), nil
+	default:
+		return nil, fmt.Errorf("%s: unsupported output format", config.to)
+	}
+}
+
+func appendNewline(data []byte) []byte {
+	return append(data, '\n')
+}
+
+func contains(ss []string, s string) bool {
+	for _, e := range ss {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
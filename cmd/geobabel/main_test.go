@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunConvert(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		stdin    string
+		expected string
+	}{
+		{
+			name:     "WKTToEWKT",
+			args:     []string{"--from", "wkt", "--srid", "4326"},
+			stdin:    "POINT (1 2)\n\nLINESTRING (1 2, 3 4)\n",
+			expected: "SRID=4326;POINT (1 2)\nSRID=4326;LINESTRING (1 2, 3 4)\n",
+		},
+		{
+			name:     "WKTToHexEWKB",
+			args:     []string{"--from", "wkt", "--to", "hexewkb", "--srid", "4326"},
+			stdin:    "POINT (1 2)",
+			expected: "0101000020E6100000000000000000F03F0000000000000040\n",
+		},
+		{
+			name:     "HexEWKBToGeoJSON",
+			args:     []string{"--from", "hexewkb", "--to", "geojson"},
+			stdin:    `\x0101000020E6100000000000000000F03F0000000000000040`,
+			expected: `{"type":"Point","coordinates":[1,2]}` + "\n",
+		},
+		{
+			name:     "AutoHexWKB",
+			args:     []string{"--to", "wkt"},
+			stdin:    "0101000000000000000000f03f0000000000000040\n",
+			expected: "POINT (1 2)\n",
+		},
+		{
+			name:     "AutoEWKT",
+			stdin:    "SRID=3857;POINT (1 2)",
+			expected: "SRID=3857;POINT (1 2)\n",
+		},
+		{
+			name:     "AutoGeoJSON",
+			args:     []string{"--to", "wkt", "--max-decimal-digits", "1"},
+			stdin:    `{"type":"Point","coordinates":[1.25,2]} {"type":"Point","coordinates":[3,4]}`,
+			expected: "POINT (1.2 2)\nPOINT (3 4)\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := append([]string{"convert"}, tc.args...)
+			require.NoError(t, run(args, strings.NewReader(tc.stdin), &stdout, &stderr))
+			assert.Equal(t, tc.expected, stdout.String())
+		})
+	}
+}
+
+func TestRunConvertBinary(t *testing.T) {
+	wkb, err := hex.DecodeString("0101000000000000000000f03f0000000000000040")
+	require.NoError(t, err)
+
+	var stdout bytes.Buffer
+	require.NoError(t, run([]string{"convert", "--to", "wkt"}, bytes.NewReader(wkb), &stdout, &bytes.Buffer{}))
+	assert.Equal(t, "POINT (1 2)\n", stdout.String())
+
+	filename := filepath.Join(t.TempDir(), "point.wkb")
+	require.NoError(t, os.WriteFile(filename, wkb, 0o666))
+	stdout.Reset()
+	require.NoError(t, run([]string{"convert", "--from", "wkb", "--to", "wkb", filename}, nil, &stdout, &bytes.Buffer{}))
+	assert.Equal(t, wkb, stdout.Bytes())
+
+	stdout.Reset()
+	err = run([]string{"convert", "--from", "wkb", "--to", "wkb", filename, filename}, nil, &stdout, &bytes.Buffer{})
+	assert.EqualError(t, err, filename+": wkb: output format only supports a single geometry")
+	assert.Empty(t, stdout.Bytes())
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		args        []string
+		stdin       string
+		expectedErr string
+	}{
+		{
+			name:        "NoCommand",
+			expectedErr: "usage: geobabel convert [flags] [file...]",
+		},
+		{
+			name:        "UnknownCommand",
+			args:        []string{"frobnicate"},
+			expectedErr: "frobnicate: unknown command",
+		},
+		{
+			name:        "UnsupportedFormat",
+			args:        []string{"convert", "--to", "kml"},
+			expectedErr: "kml: unsupported output format",
+		},
+		{
+			name:        "InvalidLine",
+			args:        []string{"convert", "--from", "wkt"},
+			stdin:       "POINT (1 2)\nPOINT (",
+			expectedErr: "line 2: ",
+		},
+		{
+			name:        "SeveralGeometriesToWKB",
+			args:        []string{"convert", "--from", "wkt", "--to", "wkb"},
+			stdin:       "POINT (1 2)\nPOINT (3 4)",
+			expectedErr: "wkb: output format only supports a single geometry",
+		},
+		{
+			name:        "SeveralGeometriesToEWKB",
+			args:        []string{"convert", "--to", "ewkb"},
+			stdin:       `{"type":"Point","coordinates":[1,2]} {"type":"Point","coordinates":[3,4]}`,
+			expectedErr: "ewkb: output format only supports a single geometry",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := run(tc.args, strings.NewReader(tc.stdin), &bytes.Buffer{}, &bytes.Buffer{})
+			require.Error(t, err)
+			assert.True(t, strings.HasPrefix(err.Error(), tc.expectedErr), err.Error())
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package geobabel
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+
+	"github.com/paulmach/orb"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geos"
+)
+
+var (
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+	geomTType             = reflect.TypeOf((*geom.T)(nil)).Elem()
+	geosGeomType          = reflect.TypeOf((*geos.Geom)(nil))
+	orbGeometryType       = reflect.TypeOf((*orb.Geometry)(nil)).Elem()
+	wkbType               = reflect.TypeOf([]byte(nil))
+)
+
+// Convert returns from converted to a To.
+//
+// from may be a geom.T, a *geos.Geom, an orb.Geometry, a []byte containing
+// WKB, or any other value that implements encoding.BinaryMarshaler by
+// returning WKB. To may be geom.T, *geos.Geom, orb.Geometry, any of their
+// concrete types, []byte for WKB, or any other type that implements
+// encoding.BinaryUnmarshaler by accepting WKB. Conversions without a direct
+// path go via WKB. If from is already of the target library it is returned
+// unchanged.
+//
+// *geos.Geoms are created with the context set with WithGEOSContext. Creating a
+// context is expensive, so if none is set then converting to a *geos.Geom from
+// any other type returns a *ConvertError wrapping ErrNoGEOSContext. If To is a
+// concrete type and the converted geometry has a different type then Convert
+// returns a *ConvertError wrapping ErrUnsupportedType.
+func Convert[To any](from any, options ...Option) (To, error) {
+	var to To
+	toType := reflect.TypeOf(&to).Elem()
+	var result any
+	var err error
+	switch {
+	case toType == geosGeomType:
+		result, err = convertToGEOSGeom(from, options)
+	case toType == wkbType:
+		result, err = convertToWKB(from, options)
+	case toType.Implements(geomTType):
+		result, err = convertToGeomT(from, options)
+	case toType.Implements(orbGeometryType):
+		result, err = convertToOrbGeometry(from, options)
+	case toType.Kind() == reflect.Pointer && toType.Implements(binaryUnmarshalerType):
+		result, err = convertToBinaryUnmarshaler(from, reflect.New(toType.Elem()).Interface().(encoding.BinaryUnmarshaler), options)
+	case reflect.PointerTo(toType).Implements(binaryUnmarshalerType):
+		_, err = convertToBinaryUnmarshaler(from, any(&to).(encoding.BinaryUnmarshaler), options)
+		return to, err
+	default:
+		return to, &ConvertError{
+			Type: fmt.Sprintf("%T", from),
+			Err:  fmt.Errorf("%s: %w", toType, ErrUnsupportedType),
+		}
+	}
+	if err != nil {
+		return to, err
+	}
+	to, ok := result.(To)
+	if !ok {
+		return to, &ConvertError{
+			Type: fmt.Sprintf("%T", result),
+			Err:  fmt.Errorf("%s: %w", toType, ErrUnsupportedType),
+		}
+	}
+	return to, nil
+}
+
+func convertToBinaryUnmarshaler(from any, binaryUnmarshaler encoding.BinaryUnmarshaler, options []Option) (encoding.BinaryUnmarshaler, error) {
+	wkb, err := convertToWKB(from, options)
+	if err != nil {
+		return nil, err
+	}
+	if err := binaryUnmarshaler.UnmarshalBinary(wkb); err != nil {
+		return nil, err
+	}
+	return binaryUnmarshaler, nil
+}
+
+func convertToGeomT(from any, options []Option) (geom.T, error) {
+	switch from := from.(type) {
+	case geom.T:
+		return from, nil
+	case *geos.Geom:
+		return NewGeomTFromGEOSGeomE(from, options...)
+	case orb.Geometry:
+		return NewGeomTFromOrbGeometryE(from, options...)
+	default:
+		wkb, err := convertToWKB(from, options)
+		if err != nil {
+			return nil, err
+		}
+		return NewGeomTFromWKB(wkb, options...)
+	}
+}
+
+func convertToGEOSGeom(from any, options []Option) (*geos.Geom, error) {
+	if geosGeom, ok := from.(*geos.Geom); ok {
+		return geosGeom, nil
+	}
+	geosContext := newConverter(options).geosContext
+	if geosContext == nil {
+		return nil, &ConvertError{
+			Type: fmt.Sprintf("%T", from),
+			Err:  ErrNoGEOSContext,
+		}
+	}
+	switch from := from.(type) {
+	case geom.T:
+		return NewGEOSGeomFromGeomTE(geosContext, from, options...)
+	case orb.Geometry:
+		return NewGEOSGeomFromOrbGeometryE(geosContext, from, options...)
+	default:
+		wkb, err := convertToWKB(from, options)
+		if err != nil {
+			return nil, err
+		}
+		return NewGEOSGeomFromWKB(geosContext, wkb, options...)
+	}
+}
+
+func convertToOrbGeometry(from any, options []Option) (orb.Geometry, error) {
+	switch from := from.(type) {
+	case orb.Geometry:
+		return from, nil
+	case geom.T:
+		return NewOrbGeometryFromGeomTE(from, options...)
+	case *geos.Geom:
+		return NewOrbGeometryFromGEOSGeomE(from, options...)
+	default:
+		wkb, err := convertToWKB(from, options)
+		if err != nil {
+			return nil, err
+		}
+		return NewOrbGeometryFromWKB(wkb, options...)
+	}
+}
+
+func convertToWKB(from any, options []Option) ([]byte, error) {
+	switch from := from.(type) {
+	case []byte:
+		return from, nil
+	case geom.T:
+		return WKBFromGeomT(from, options...)
+	case *geos.Geom:
+		return WKBFromGEOSGeomE(from, options...)
+	case orb.Geometry:
+		return WKBFromOrbGeometryE(from, options...)
+	case encoding.BinaryMarshaler:
+		return from.MarshalBinary()
+	default:
+		return nil, newUnsupportedTypeError(fmt.Sprintf("%T", from))
+	}
+}
@@ -0,0 +1,165 @@
+package geobabel
+
+import (
+	"math"
+
+	"github.com/paulmach/orb"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geos"
+)
+
+// A Converter converts geometries with a fixed set of options, reusing its
+// internal buffers between conversions to reduce allocations. A Converter is
+// not safe for concurrent use.
+//
+// Only conversions to *geos.Geoms benefit from reusing buffers, as GEOS copies
+// coordinates into its own memory. Other conversions return geometries that
+// own their coordinates; use the Append functions to reuse their memory.
+type Converter struct {
+	c *converter
+}
+
+// NewConverter returns a new Converter with options.
+func NewConverter(options ...Option) *Converter {
+	return &Converter{
+		c: newConverter(options),
+	}
+}
+
+func (c *Converter) NewGEOSGeomFromGeomT(geosContext *geos.Context, geomT geom.T) *geos.Geom {
+	geosGeom, err := c.NewGEOSGeomFromGeomTE(geosContext, geomT)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromGeomTE is like the package-level NewGEOSGeomFromGeomTE but
+// reuses c's buffers.
+func (c *Converter) NewGEOSGeomFromGeomTE(geosContext *geos.Context, geomT geom.T) (*geos.Geom, error) {
+	return c.c.newGEOSGeomFromGeomTE(geosContext, geomT)
+}
+
+func (c *Converter) NewGEOSGeomsFromOrbGeometries(geosContext *geos.Context, orbGeometries []orb.Geometry) []*geos.Geom {
+	geosGeoms, err := c.NewGEOSGeomsFromOrbGeometriesE(geosContext, orbGeometries)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeoms
+}
+
+// NewGEOSGeomsFromOrbGeometriesE is like the package-level
+// NewGEOSGeomsFromOrbGeometriesE but reuses c's buffers.
+func (c *Converter) NewGEOSGeomsFromOrbGeometriesE(geosContext *geos.Context, orbGeometries []orb.Geometry) ([]*geos.Geom, error) {
+	return c.c.newGEOSGeomsFromOrbGeometries(geosContext, orbGeometries)
+}
+
+func (c *Converter) NewGEOSGeomFromOrbGeometry(geosContext *geos.Context, orbGeometry orb.Geometry) *geos.Geom {
+	geosGeom, err := c.NewGEOSGeomFromOrbGeometryE(geosContext, orbGeometry)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromOrbGeometryE is like the package-level
+// NewGEOSGeomFromOrbGeometryE but reuses c's buffers.
+func (c *Converter) NewGEOSGeomFromOrbGeometryE(geosContext *geos.Context, orbGeometry orb.Geometry) (*geos.Geom, error) {
+	return c.c.newGEOSGeomFromOrbGeometryE(geosContext, orbGeometry)
+}
+
+// A geosCoordsBuffer holds the GEOS coordinates of a geometry while it is
+// being created. GEOS copies coordinates when creating geometries, so the
+// buffer can be reused once the geometry has been created. Returned
+// coordinates remain valid until reset is called.
+type geosCoordsBuffer struct {
+	flatCoords []float64
+	coords     [][]float64
+	coordss    [][][]float64
+}
+
+// reset makes all of b's memory available for reuse.
+func (b *geosCoordsBuffer) reset() {
+	b.flatCoords = b.flatCoords[:0]
+	b.coords = b.coords[:0]
+	b.coordss = b.coordss[:0]
+}
+
+// appendFlatCoords appends flatCoords and returns them.
+func (b *geosCoordsBuffer) appendFlatCoords(flatCoords ...float64) []float64 {
+	b.flatCoords = append(b.flatCoords, flatCoords...)
+	n := len(b.flatCoords)
+	return b.flatCoords[n-len(flatCoords) : n : n]
+}
+
+// coordsSince returns the coordinates appended to b.coords since start.
+func (b *geosCoordsBuffer) coordsSince(start int) [][]float64 {
+	n := len(b.coords)
+	return b.coords[start:n:n]
+}
+
+func (b *geosCoordsBuffer) appendOrbPoints(orbPoints []orb.Point, transform TransformFunc) [][]float64 {
+	start := len(b.coords)
+	for _, orbPoint := range orbPoints {
+		x, y := orbPoint[0], orbPoint[1]
+		if transform != nil {
+			x, y = transform(x, y)
+		}
+		b.coords = append(b.coords, b.appendFlatCoords(x, y))
+	}
+	return b.coordsSince(start)
+}
+
+// appendOrbPolygon appends and returns the GEOS coordinates of the rings of
+// orbPolygon.
+func (b *geosCoordsBuffer) appendOrbPolygon(orbPolygon orb.Polygon, transform TransformFunc) [][][]float64 {
+	start := len(b.coordss)
+	for _, orbRing := range orbPolygon {
+		b.coordss = append(b.coordss, b.appendOrbPoints(orbRing, transform))
+	}
+	n := len(b.coordss)
+	return b.coordss[start:n:n]
+}
+
+// appendGeomFlatCoords appends and returns the GEOS coordinates of
+// geomFlatCoords with geosDimensions ordinates each. Where possible, the
+// returned coordinates alias geomFlatCoords. If transform is not nil then the
+// coordinates are always copied. b's buffers are grown at most once, so there
+// are no allocations per coordinate.
+func (b *geosCoordsBuffer) appendGeomFlatCoords(geomFlatCoords []float64, geomLayout geom.Layout, geosDimensions int, transform TransformFunc) [][]float64 {
+	geomStride := geomLayout.Stride()
+	n := len(geomFlatCoords) / geomStride
+	if cap(b.coords)-len(b.coords) < n {
+		b.coords = append(make([][]float64, 0, 2*cap(b.coords)+n), b.coords...)
+	}
+	if (transform != nil || geomLayout == geom.XYM && geosDimensions == 4) && cap(b.flatCoords)-len(b.flatCoords) < n*geosDimensions {
+		b.flatCoords = append(make([]float64, 0, 2*cap(b.flatCoords)+n*geosDimensions), b.flatCoords...)
+	}
+	start := len(b.coords)
+	for i := 0; i < len(geomFlatCoords); i += geomStride {
+		var geosCoord []float64
+		switch {
+		case geomLayout == geom.XYM && geosDimensions == 4:
+			geosCoord = b.appendFlatCoords(geomFlatCoords[i], geomFlatCoords[i+1], math.NaN(), geomFlatCoords[i+2])
+		case transform != nil:
+			geosCoord = b.appendFlatCoords(geomFlatCoords[i : i+geosDimensions]...)
+		default:
+			geosCoord = geomFlatCoords[i : i+geosDimensions : i+geosDimensions]
+		}
+		if transform != nil {
+			geosCoord[0], geosCoord[1] = transform(geosCoord[0], geosCoord[1])
+		}
+		b.coords = append(b.coords, geosCoord)
+	}
+	return b.coordsSince(start)
+}
+
+func (b *geosCoordsBuffer) appendGeomFlatCoordsEnds(geomFlatCoords []float64, geomStart int, geomEnds []int, geomLayout geom.Layout, geosDimensions int, transform TransformFunc) [][][]float64 {
+	start := len(b.coordss)
+	for _, geomEnd := range geomEnds {
+		b.coordss = append(b.coordss, b.appendGeomFlatCoords(geomFlatCoords[geomStart:geomEnd], geomLayout, geosDimensions, transform))
+		geomStart = geomEnd
+	}
+	n := len(b.coordss)
+	return b.coordss[start:n:n]
+}
@@ -0,0 +1,147 @@
+package geobabel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/twpayne/go-geos"
+)
+
+// Errors.
+var (
+	// ErrUnsupportedLayout is returned when a geometry has Z or M ordinates
+	// that cannot be represented by the target.
+	ErrUnsupportedLayout = errors.New("unsupported layout")
+	// ErrUnsupportedType is returned when a geometry has a type that cannot be
+	// converted.
+	ErrUnsupportedType = errors.New("unsupported type")
+	// ErrUnexpectedType is returned when a geometry does not have the type
+	// required by a typed conversion.
+	ErrUnexpectedType = errors.New("unexpected type")
+	// ErrUnsupportedSRID is returned when a projection is requested to or
+	// from an unsupported coordinate reference system.
+	ErrUnsupportedSRID = errors.New("unsupported SRID")
+	// ErrInvalidGeometry is returned when a geometry is invalid.
+	ErrInvalidGeometry = errors.New("invalid geometry")
+	// ErrNoGEOSContext is returned when a *geos.Geom must be created but no
+	// context is set with WithGEOSContext.
+	ErrNoGEOSContext = errors.New("no GEOS context")
+)
+
+// A ConvertError is an error converting a geometry.
+type ConvertError struct {
+	// Type is the type of the geometry that could not be converted.
+	Type string
+	// Path is the index of the geometry within each enclosing collection,
+	// outermost first. It is empty if the geometry is not within a
+	// collection. For validity errors, multi geometries and polygons are
+	// also considered collections of their members and rings.
+	Path []int
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ConvertError) Error() string {
+	var sb strings.Builder
+	for _, index := range e.Path {
+		fmt.Fprintf(&sb, "[%d]", index)
+	}
+	if sb.Len() > 0 {
+		sb.WriteString(": ")
+	}
+	sb.WriteString(e.Type)
+	sb.WriteString(": ")
+	sb.WriteString(e.Err.Error())
+	return sb.String()
+}
+
+func (e *ConvertError) Unwrap() error {
+	return e.Err
+}
+
+// A ValidityError describes why a geometry is invalid.
+type ValidityError struct {
+	// Reason describes the problem, for example "unclosed ring" or, for
+	// problems found by GEOS, "Self-intersection".
+	Reason string
+	// Location is the location of the problem, if known.
+	Location []float64
+}
+
+func (e *ValidityError) Error() string {
+	if e.Location == nil {
+		return fmt.Sprintf("%s: %s", ErrInvalidGeometry, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s at %v", ErrInvalidGeometry, e.Reason, e.Location)
+}
+
+func (e *ValidityError) Unwrap() error {
+	return ErrInvalidGeometry
+}
+
+// newUnsupportedTypeError returns a new error for a geometry of type typ.
+func newUnsupportedTypeError(typ string) *ConvertError {
+	return &ConvertError{
+		Type: typ,
+		Err:  ErrUnsupportedType,
+	}
+}
+
+// newUnexpectedTypeError returns a new error for a geometry of type typ that
+// was expected to have type expectedType.
+func newUnexpectedTypeError(typ, expectedType string) *ConvertError {
+	return &ConvertError{
+		Type: typ,
+		Err:  fmt.Errorf("%w: expected %s", ErrUnexpectedType, expectedType),
+	}
+}
+
+// newUnsupportedLayoutError returns a new error for a geometry of type typ
+// with layout.
+func newUnsupportedLayoutError(typ, layout string) *ConvertError {
+	return &ConvertError{
+		Type: typ,
+		Err:  fmt.Errorf("%s: %w", layout, ErrUnsupportedLayout),
+	}
+}
+
+// newValidityError returns a new error for a geometry of type typ that is
+// invalid for reason at location.
+func newValidityError(typ, reason string, location []float64) *ConvertError {
+	return &ConvertError{
+		Type: typ,
+		Err: &ValidityError{
+			Reason:   reason,
+			Location: location,
+		},
+	}
+}
+
+// withPathIndex prepends index to the path of err, which was returned when
+// converting the index-th geometry of a collection.
+func withPathIndex(err error, index int) error {
+	var convertError *ConvertError
+	if errors.As(err, &convertError) {
+		convertError.Path = append([]int{index}, convertError.Path...)
+		return convertError
+	}
+	return err
+}
+
+// recoverGEOSError recovers from a panic caused by a GEOS error and sets *errp
+// to a *ConvertError. Other panics are propagated.
+func recoverGEOSError(errp *error, typ string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	geosError, ok := r.(geos.Error)
+	if !ok {
+		panic(r)
+	}
+	*errp = &ConvertError{
+		Type: typ,
+		Err:  geosError,
+	}
+}
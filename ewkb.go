@@ -0,0 +1,78 @@
+package geobabel
+
+import (
+	"github.com/paulmach/orb"
+	"github.com/twpayne/go-geom"
+	geomewkb "github.com/twpayne/go-geom/encoding/ewkb"
+	"github.com/twpayne/go-geos"
+)
+
+// withEWKBOptions returns options with the options that encode WKB as EWKB
+// appended.
+func withEWKBOptions(options []Option) []Option {
+	return append(options[:len(options):len(options)], WithWKBFlavor(WKBFlavorExtended), WithWKBSRID(true))
+}
+
+// NewGEOSGeomFromEWKB returns a new *geos.Geom parsed from ewkb. The SRID, if
+// any, is set on the returned geometry.
+func NewGEOSGeomFromEWKB(geosContext *geos.Context, ewkb []byte, options ...Option) (*geos.Geom, error) {
+	return NewGEOSGeomFromWKB(geosContext, ewkb, options...)
+}
+
+// NewGeomTFromEWKB returns a new geom.T parsed from ewkb. The SRID, if any, is
+// set on the returned geometry.
+func NewGeomTFromEWKB(ewkb []byte, options ...Option) (geom.T, error) {
+	geomT, err := geomewkb.Unmarshal(ewkb)
+	if err != nil {
+		return nil, err
+	}
+	return newConverter(options).geomTWithLinearRings(geomNormalizeEmptyPoints(geomT)), nil
+}
+
+// NewOrbGeometryFromEWKB returns a new orb.Geometry and SRID parsed from ewkb.
+// Z and M ordinates are handled according to options.
+func NewOrbGeometryFromEWKB(ewkb []byte, options ...Option) (orb.Geometry, int, error) {
+	geomT, err := NewGeomTFromEWKB(ewkb, options...)
+	if err != nil {
+		return nil, 0, err
+	}
+	orbGeometry, err := NewOrbGeometryFromGeomTE(geomT, options...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return orbGeometry, geomT.SRID(), nil
+}
+
+// EWKBFromGEOSGeom returns geosGeom encoded as EWKB, including its SRID and Z
+// and M ordinates.
+func EWKBFromGEOSGeom(geosGeom *geos.Geom, options ...Option) ([]byte, error) {
+	return WKBFromGEOSGeomE(geosGeom, withEWKBOptions(options)...)
+}
+
+// EWKBFromGeomT returns geomT encoded as EWKB, including its SRID and Z and M
+// ordinates.
+func EWKBFromGeomT(geomT geom.T, options ...Option) ([]byte, error) {
+	return WKBFromGeomT(geomT, withEWKBOptions(options)...)
+}
+
+func EWKBFromOrbGeometry(orbGeometry orb.Geometry, srid int, options ...Option) []byte {
+	ewkb, err := EWKBFromOrbGeometryE(orbGeometry, srid, options...)
+	if err != nil {
+		panic(err)
+	}
+	return ewkb
+}
+
+// EWKBFromOrbGeometryE returns orbGeometry encoded as EWKB with srid. It
+// returns a *ConvertError if orbGeometry cannot be encoded.
+func EWKBFromOrbGeometryE(orbGeometry orb.Geometry, srid int, options ...Option) ([]byte, error) {
+	c := newConverter(withEWKBOptions(options))
+	geomT, err := c.newGeomTFromOrbGeometryE(orbGeometry)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := geom.SetSRID(geomT, c.targetSRID(srid)); err != nil {
+		return nil, err
+	}
+	return c.marshalWKB(geomT)
+}
@@ -0,0 +1,156 @@
+package geobabel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	orbgeojson "github.com/paulmach/orb/geojson"
+	"github.com/twpayne/go-geom"
+	geomgeojson "github.com/twpayne/go-geom/encoding/geojson"
+)
+
+// go-geom stores feature IDs as strings and has no foreign members. Numeric
+// orb feature IDs are formatted as strings in the same way as go-geom's
+// decoder formats them, and the ExtraMembers of orb feature collections are
+// dropped. Properties are not copied, so the converted feature's properties
+// alias the original's.
+
+// NewGeomFeatureFromOrbFeature returns a new *geomgeojson.Feature converted
+// from orbFeature.
+func NewGeomFeatureFromOrbFeature(orbFeature *orbgeojson.Feature) (*geomgeojson.Feature, error) {
+	geomID, err := geomFeatureIDFromOrbFeatureID(orbFeature.ID)
+	if err != nil {
+		return nil, err
+	}
+	geomBBox, err := geomBoundsFromOrbBBox(orbFeature.BBox)
+	if err != nil {
+		return nil, err
+	}
+	var geomT geom.T
+	if orbFeature.Geometry != nil {
+		geomT, err = NewGeomTFromOrbGeometryE(orbFeature.Geometry)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &geomgeojson.Feature{
+		ID:         geomID,
+		BBox:       geomBBox,
+		Geometry:   geomT,
+		Properties: orbFeature.Properties,
+	}, nil
+}
+
+// NewGeomFeatureCollectionFromOrbFeatureCollection returns a new
+// *geomgeojson.FeatureCollection converted from orbFeatureCollection.
+func NewGeomFeatureCollectionFromOrbFeatureCollection(orbFeatureCollection *orbgeojson.FeatureCollection) (*geomgeojson.FeatureCollection, error) {
+	geomBBox, err := geomBoundsFromOrbBBox(orbFeatureCollection.BBox)
+	if err != nil {
+		return nil, err
+	}
+	geomFeatures := make([]*geomgeojson.Feature, 0, len(orbFeatureCollection.Features))
+	for i, orbFeature := range orbFeatureCollection.Features {
+		geomFeature, err := NewGeomFeatureFromOrbFeature(orbFeature)
+		if err != nil {
+			return nil, withPathIndex(err, i)
+		}
+		geomFeatures = append(geomFeatures, geomFeature)
+	}
+	return &geomgeojson.FeatureCollection{
+		BBox:     geomBBox,
+		Features: geomFeatures,
+	}, nil
+}
+
+// NewOrbFeatureFromGeomFeature returns a new *orbgeojson.Feature converted
+// from geomFeature.
+func NewOrbFeatureFromGeomFeature(geomFeature *geomgeojson.Feature, options ...Option) (*orbgeojson.Feature, error) {
+	orbFeature := &orbgeojson.Feature{
+		Type:       "Feature",
+		BBox:       orbBBoxFromGeomBounds(geomFeature.BBox),
+		Properties: geomFeature.Properties,
+	}
+	if geomFeature.ID != "" {
+		orbFeature.ID = geomFeature.ID
+	}
+	if geomFeature.Geometry != nil {
+		orbGeometry, err := NewOrbGeometryFromGeomTE(geomFeature.Geometry, options...)
+		if err != nil {
+			return nil, err
+		}
+		orbFeature.Geometry = orbGeometry
+	}
+	return orbFeature, nil
+}
+
+// NewOrbFeatureCollectionFromGeomFeatureCollection returns a new
+// *orbgeojson.FeatureCollection converted from geomFeatureCollection.
+func NewOrbFeatureCollectionFromGeomFeatureCollection(geomFeatureCollection *geomgeojson.FeatureCollection, options ...Option) (*orbgeojson.FeatureCollection, error) {
+	orbFeatures := make([]*orbgeojson.Feature, 0, len(geomFeatureCollection.Features))
+	for i, geomFeature := range geomFeatureCollection.Features {
+		orbFeature, err := NewOrbFeatureFromGeomFeature(geomFeature, options...)
+		if err != nil {
+			return nil, withPathIndex(err, i)
+		}
+		orbFeatures = append(orbFeatures, orbFeature)
+	}
+	return &orbgeojson.FeatureCollection{
+		Type:     "FeatureCollection",
+		BBox:     orbBBoxFromGeomBounds(geomFeatureCollection.BBox),
+		Features: orbFeatures,
+	}, nil
+}
+
+// geomFeatureIDFromOrbFeatureID returns orbID as a go-geom feature ID.
+func geomFeatureIDFromOrbFeatureID(orbID interface{}) (string, error) {
+	switch orbID := orbID.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return orbID, nil
+	case float64:
+		return strconv.FormatFloat(orbID, 'f', -1, 64), nil
+	case json.Number:
+		return orbID.String(), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(orbID), nil
+	default:
+		return "", fmt.Errorf("%T: unsupported feature ID type", orbID)
+	}
+}
+
+// geomBoundsFromOrbBBox returns orbBBox as a *geom.Bounds, or nil if orbBBox
+// is empty.
+func geomBoundsFromOrbBBox(orbBBox orbgeojson.BBox) (*geom.Bounds, error) {
+	switch len(orbBBox) {
+	case 0:
+		return nil, nil
+	case 4:
+		return geom.NewBounds(geom.XY).Set(orbBBox...), nil
+	case 6:
+		return geom.NewBounds(geom.XYZ).Set(orbBBox...), nil
+	default:
+		return nil, fmt.Errorf("%d: invalid bbox length", len(orbBBox))
+	}
+}
+
+// orbBBoxFromGeomBounds returns geomBounds as an orbgeojson.BBox, or nil if
+// geomBounds is nil. Like go-geom's encoder, M ordinates are dropped.
+func orbBBoxFromGeomBounds(geomBounds *geom.Bounds) orbgeojson.BBox {
+	if geomBounds == nil {
+		return nil
+	}
+	switch geomBounds.Layout() {
+	case geom.XYZ, geom.XYZM:
+		return orbgeojson.BBox{
+			geomBounds.Min(0), geomBounds.Min(1), geomBounds.Min(2),
+			geomBounds.Max(0), geomBounds.Max(1), geomBounds.Max(2),
+		}
+	default:
+		return orbgeojson.BBox{
+			geomBounds.Min(0), geomBounds.Min(1),
+			geomBounds.Max(0), geomBounds.Max(1),
+		}
+	}
+}
@@ -1,9 +1,18 @@
 package geobabel_test
 
 import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/paulmach/orb"
+	orbgeojson "github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/project"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/twpayne/go-geom"
@@ -19,7 +28,10 @@ func TestAll(t *testing.T) {
 		geomT       geom.T
 		geosGeom    *geos.Geom
 		orbGeometry orb.Geometry
-		skipWKB     string
+		// convertedOrbGeometry is the orb.Geometry converted from geomT and
+		// geosGeom, if it differs from orbGeometry.
+		convertedOrbGeometry orb.Geometry
+		skipWKB              string
 	}{
 		{
 			name:        "Point",
@@ -123,17 +135,112 @@ func TestAll(t *testing.T) {
 				orb.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
 			},
 		},
+		{
+			name:        "EmptyPoint",
+			geomT:       geom.NewPointEmpty(geom.XY),
+			geosGeom:    geosContext.NewEmptyPoint(),
+			orbGeometry: orb.Point{math.NaN(), math.NaN()},
+		},
+		{
+			name:        "EmptyLineString",
+			geomT:       geom.NewLineStringFlat(geom.XY, []float64{}),
+			geosGeom:    geosContext.NewEmptyLineString(),
+			orbGeometry: orb.LineString{},
+		},
+		{
+			name:        "EmptyLinearRing",
+			geomT:       geom.NewLinearRingFlat(geom.XY, []float64{}),
+			geosGeom:    geosContext.NewEmptyPolygon().ExteriorRing().Clone(),
+			orbGeometry: orb.Ring{},
+			skipWKB:     "WKB does not support LinearRings",
+		},
+		{
+			name:                 "Bound",
+			geomT:                geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{{{1, 2}, {3, 2}, {3, 4}, {1, 4}, {1, 2}}}),
+			geosGeom:             geosContext.NewPolygon([][][]float64{{{1, 2}, {3, 2}, {3, 4}, {1, 4}, {1, 2}}}),
+			orbGeometry:          orb.Bound{Min: orb.Point{1, 2}, Max: orb.Point{3, 4}},
+			convertedOrbGeometry: orb.Polygon{{{1, 2}, {3, 2}, {3, 4}, {1, 4}, {1, 2}}},
+		},
+		{
+			name:        "EmptyPolygon",
+			geomT:       geom.NewPolygon(geom.XY),
+			geosGeom:    geosContext.NewEmptyPolygon(),
+			orbGeometry: orb.Polygon{},
+		},
+		{
+			name:        "EmptyMultiPoint",
+			geomT:       geom.NewMultiPoint(geom.XY),
+			geosGeom:    geosContext.NewEmptyCollection(geos.TypeIDMultiPoint),
+			orbGeometry: orb.MultiPoint{},
+		},
+		{
+			name:  "MultiPointWithEmptyPoint",
+			geomT: geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{nil, {1, 2}}),
+			geosGeom: geosContext.NewCollection(
+				geos.TypeIDMultiPoint,
+				[]*geos.Geom{
+					geosContext.NewEmptyPoint(),
+					geosContext.NewPoint([]float64{1, 2}),
+				},
+			),
+			orbGeometry: orb.MultiPoint{{math.NaN(), math.NaN()}, {1, 2}},
+		},
+		{
+			name:        "EmptyMultiLineString",
+			geomT:       geom.NewMultiLineString(geom.XY),
+			geosGeom:    geosContext.NewEmptyCollection(geos.TypeIDMultiLineString),
+			orbGeometry: orb.MultiLineString{},
+		},
+		{
+			name:        "EmptyMultiPolygon",
+			geomT:       geom.NewMultiPolygon(geom.XY),
+			geosGeom:    geosContext.NewEmptyCollection(geos.TypeIDMultiPolygon),
+			orbGeometry: orb.MultiPolygon{},
+		},
+		{
+			name:        "EmptyGeometryCollection",
+			geomT:       geom.NewGeometryCollection().MustSetLayout(geom.XY),
+			geosGeom:    geosContext.NewEmptyCollection(geos.TypeIDGeometryCollection),
+			orbGeometry: orb.Collection{},
+		},
+		{
+			name: "GeometryCollectionWithEmptyGeometries",
+			geomT: geom.NewGeometryCollection().MustPush(
+				geom.NewPointEmpty(geom.XY),
+				geom.NewLineStringFlat(geom.XY, []float64{}),
+				geom.NewPolygon(geom.XY),
+			),
+			geosGeom: geosContext.NewCollection(
+				geos.TypeIDGeometryCollection,
+				[]*geos.Geom{
+					geosContext.NewEmptyPoint(),
+					geosContext.NewEmptyLineString(),
+					geosContext.NewEmptyPolygon(),
+				},
+			),
+			orbGeometry: orb.Collection{
+				orb.Point{math.NaN(), math.NaN()},
+				orb.LineString{},
+				orb.Polygon{},
+			},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			require.True(t, tc.geosGeom.IsValid())
 			require.Equal(t, "Valid Geometry", tc.geosGeom.IsValidReason())
 
+			assert.Equal(t, tc.geomT, geobabel.NewGeomTFromGEOSGeom(tc.geosGeom))
 			assert.Equal(t, tc.geomT, geobabel.NewGeomTFromOrbGeometry(tc.orbGeometry))
 
-			assert.True(t, tc.geosGeom.Equals(geobabel.NewGEOSGeomFromOrbGeometry(geosContext, tc.orbGeometry)))
+			assert.True(t, tc.geosGeom.EqualsExact(geobabel.NewGEOSGeomFromGeomT(geosContext, tc.geomT), 0))
+			assert.True(t, tc.geosGeom.EqualsExact(geobabel.NewGEOSGeomFromOrbGeometry(geosContext, tc.orbGeometry), 0))
 
-			assert.Equal(t, tc.orbGeometry, geobabel.NewOrbGeometryFromGEOSGeom(tc.geosGeom))
-			assert.Equal(t, tc.orbGeometry, geobabel.NewOrbGeometryFromGeomT(tc.geomT))
+			convertedOrbGeometry := tc.convertedOrbGeometry
+			if convertedOrbGeometry == nil {
+				convertedOrbGeometry = tc.orbGeometry
+			}
+			assertOrbGeometryEqual(t, convertedOrbGeometry, geobabel.NewOrbGeometryFromGEOSGeom(tc.geosGeom))
+			assertOrbGeometryEqual(t, convertedOrbGeometry, geobabel.NewOrbGeometryFromGeomT(tc.geomT))
 
 			if tc.skipWKB == "" {
 				geomWKB, err := geobabel.WKBFromGeomT(tc.geomT)
@@ -155,28 +262,2564 @@ func TestAll(t *testing.T) {
 
 				geosGeom, err := geobabel.NewGEOSGeomFromWKB(geosContext, geomWKB)
 				assert.NoError(t, err)
-				assert.True(t, tc.geosGeom.Equals(geosGeom))
+				assert.True(t, tc.geosGeom.EqualsExact(geosGeom, 0))
 
 				geosGeom, err = geobabel.NewGEOSGeomFromWKB(geosContext, geosWKB)
 				assert.NoError(t, err)
-				assert.True(t, tc.geosGeom.Equals(geosGeom))
+				assert.True(t, tc.geosGeom.EqualsExact(geosGeom, 0))
 
 				geosGeom, err = geobabel.NewGEOSGeomFromWKB(geosContext, orbWKB)
 				assert.NoError(t, err)
-				assert.True(t, tc.geosGeom.Equals(geosGeom))
+				assert.True(t, tc.geosGeom.EqualsExact(geosGeom, 0))
 
 				orbGeometry, err := geobabel.NewOrbGeometryFromWKB(geomWKB)
 				assert.NoError(t, err)
-				assert.Equal(t, tc.orbGeometry, orbGeometry)
+				assertOrbGeometryEqual(t, convertedOrbGeometry, orbGeometry)
 
 				orbGeometry, err = geobabel.NewOrbGeometryFromWKB(geosWKB)
 				assert.NoError(t, err)
-				assert.Equal(t, tc.orbGeometry, orbGeometry)
+				assertOrbGeometryEqual(t, convertedOrbGeometry, orbGeometry)
 
 				orbGeometry, err = geobabel.NewOrbGeometryFromWKB(orbWKB)
 				assert.NoError(t, err)
-				assert.Equal(t, tc.orbGeometry, orbGeometry)
+				assertOrbGeometryEqual(t, convertedOrbGeometry, orbGeometry)
 			}
 		})
 	}
 }
+
+func TestGeomTGEOSGeomXYZ(t *testing.T) {
+	geosContext := geos.NewContext()
+	for _, tc := range []struct {
+		name     string
+		geomT    geom.T
+		geosGeom *geos.Geom
+	}{
+		{
+			name:     "Point",
+			geomT:    geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{1, 2, 3}),
+			geosGeom: geosContext.NewPoint([]float64{1, 2, 3}),
+		},
+		{
+			name:     "LineString",
+			geomT:    geom.NewLineString(geom.XYZ).MustSetCoords([]geom.Coord{{1, 2, 3}, {4, 5, 6}}),
+			geosGeom: geosContext.NewLineString([][]float64{{1, 2, 3}, {4, 5, 6}}),
+		},
+		{
+			name: "MultiPolygon",
+			geomT: geom.NewMultiPolygon(geom.XYZ).MustSetCoords([][][]geom.Coord{
+				{{{0, 0, 1}, {4, 0, 2}, {4, 4, 3}, {0, 0, 1}}, {{2, 1, 4}, {3, 1, 5}, {3, 2, 6}, {2, 1, 4}}},
+				{{{5, 5, 7}, {6, 5, 8}, {6, 6, 9}, {5, 5, 7}}},
+			}),
+			geosGeom: geosContext.NewCollection(
+				geos.TypeIDMultiPolygon,
+				[]*geos.Geom{
+					geosContext.NewPolygon([][][]float64{
+						{{0, 0, 1}, {4, 0, 2}, {4, 4, 3}, {0, 0, 1}},
+						{{2, 1, 4}, {3, 1, 5}, {3, 2, 6}, {2, 1, 4}},
+					}),
+					geosContext.NewPolygon([][][]float64{{{5, 5, 7}, {6, 5, 8}, {6, 6, 9}, {5, 5, 7}}}),
+				},
+			),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.geomT, geobabel.NewGeomTFromGEOSGeom(tc.geosGeom))
+			assert.True(t, tc.geosGeom.EqualsExact(geobabel.NewGEOSGeomFromGeomT(geosContext, tc.geomT), 0))
+			assert.Equal(t, tc.geomT, geobabel.NewGeomTFromGEOSGeom(geobabel.NewGEOSGeomFromGeomT(geosContext, tc.geomT)))
+		})
+	}
+}
+
+func TestZMPolicy(t *testing.T) {
+	geosContext := geos.NewContext()
+
+	geomPointXYZ := geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{1, 2, 3})
+	geosPointXYZ := geosContext.NewPoint([]float64{1, 2, 3})
+
+	assert.Equal(t, orb.Point{1, 2}, geobabel.NewOrbGeometryFromGeomT(geomPointXYZ))
+	assert.Equal(t, orb.Point{1, 2}, geobabel.NewOrbGeometryFromGEOSGeom(geosPointXYZ))
+
+	for _, zmPolicy := range []geobabel.ZMPolicy{geobabel.ZMPolicyError, geobabel.ZMPolicyKeep} {
+		_, err := geobabel.NewOrbGeometryFromGeomTE(geomPointXYZ, geobabel.WithZMPolicy(zmPolicy))
+		assert.True(t, errors.Is(err, geobabel.ErrUnsupportedLayout))
+		assert.EqualError(t, err, "*geom.Point: XYZ: unsupported layout")
+
+		_, err = geobabel.NewOrbGeometryFromGEOSGeomE(geosContext.NewCollection(
+			geos.TypeIDMultiPoint,
+			[]*geos.Geom{
+				geosContext.NewPoint([]float64{1, 2}),
+				geosContext.NewPoint([]float64{3, 4, 5}),
+			},
+		), geobabel.WithZMPolicy(zmPolicy))
+		assert.EqualError(t, err, "[1]: Point: XYZ: unsupported layout")
+	}
+
+	orbGeometry, err := geobabel.NewOrbGeometryFromGEOSGeomE(
+		geosContext.NewPoint([]float64{1, 2, math.NaN()}),
+		geobabel.WithZMPolicy(geobabel.ZMPolicyError),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, orb.Point{1, 2}, orbGeometry)
+
+	geomLineStringXYM := geom.NewLineString(geom.XYM).MustSetCoords([]geom.Coord{{1, 2, 3}, {4, 5, 6}})
+	assert.Equal(t,
+		geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {4, 5}}),
+		geobabel.NewGeomTFromGEOSGeom(geobabel.NewGEOSGeomFromGeomT(geosContext, geomLineStringXYM)),
+	)
+	_, err = geobabel.NewGEOSGeomFromGeomTE(geosContext, geomLineStringXYM, geobabel.WithZMPolicy(geobabel.ZMPolicyError))
+	assert.True(t, errors.Is(err, geobabel.ErrUnsupportedLayout))
+
+	if geos.VersionMajor == 3 && geos.VersionMinor < 12 {
+		t.Skip("GEOS does not support M")
+	}
+
+	geosGeom, err := geobabel.NewGEOSGeomFromGeomTE(geosContext, geomLineStringXYM, geobabel.WithZMPolicy(geobabel.ZMPolicyKeep))
+	require.NoError(t, err)
+	assert.Equal(t, geomLineStringXYM, geobabel.NewGeomTFromGEOSGeom(geosGeom))
+
+	geomPolygonXYZM := geom.NewPolygon(geom.XYZM).MustSetCoords([][]geom.Coord{
+		{{0, 0, 1, 2}, {1, 0, 3, 4}, {1, 1, 5, 6}, {0, 0, 1, 2}},
+	})
+	assert.Equal(t, geomPolygonXYZM, geobabel.NewGeomTFromGEOSGeom(geobabel.NewGEOSGeomFromGeomT(geosContext, geomPolygonXYZM)))
+}
+
+func TestWKBWriter(t *testing.T) {
+	geosContext := geos.NewContext()
+
+	for _, tc := range []struct {
+		name        string
+		geomT       geom.T
+		geosGeom    *geos.Geom
+		orbGeometry orb.Geometry
+	}{
+		{
+			name:        "Point",
+			geomT:       geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}).SetSRID(4326),
+			geosGeom:    geosContext.NewPoint([]float64{1, 2}).SetSRID(4326),
+			orbGeometry: orb.Point{1, 2},
+		},
+		{
+			name:        "EmptyPoint",
+			geomT:       geom.NewPointEmpty(geom.XY),
+			geosGeom:    geosContext.NewEmptyPoint(),
+			orbGeometry: orb.Point{math.NaN(), math.NaN()},
+		},
+		{
+			name: "MultiPolygon",
+			geomT: geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+				{{{2, 1}, {3, 1}, {3, 2}, {2, 1}}},
+			}),
+			geosGeom: geosContext.NewCollection(geos.TypeIDMultiPolygon, []*geos.Geom{
+				geosContext.NewPolygon([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}),
+				geosContext.NewPolygon([][][]float64{{{2, 1}, {3, 1}, {3, 2}, {2, 1}}}),
+			}),
+			orbGeometry: orb.MultiPolygon{
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+				{{{2, 1}, {3, 1}, {3, 2}, {2, 1}}},
+			},
+		},
+		{
+			name: "GeometryCollection",
+			geomT: geom.NewGeometryCollection().MustPush(
+				geom.NewPointEmpty(geom.XY),
+				geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+			),
+			geosGeom: geosContext.NewCollection(geos.TypeIDGeometryCollection, []*geos.Geom{
+				geosContext.NewEmptyPoint(),
+				geosContext.NewLineString([][]float64{{1, 2}, {3, 4}}),
+			}),
+			orbGeometry: orb.Collection{
+				orb.Point{math.NaN(), math.NaN()},
+				orb.LineString{{1, 2}, {3, 4}},
+			},
+		},
+	} {
+		for _, options := range [][]geobabel.Option{
+			nil,
+			{geobabel.WithWKBByteOrder(binary.BigEndian)},
+			{geobabel.WithWKBFlavor(geobabel.WKBFlavorExtended)},
+			{geobabel.WithWKBLayout(geom.XYZM)},
+		} {
+			geomWKB, err := geobabel.WKBFromGeomT(geom.Must(geom.SetSRID(tc.geomT, 0)), options...)
+			require.NoError(t, err)
+			assert.Equal(t, geomWKB, geobabel.WKBFromGEOSGeom(tc.geosGeom.Clone().SetSRID(0), options...), tc.name)
+			assert.Equal(t, geomWKB, geobabel.WKBFromOrbGeometry(tc.orbGeometry, options...), tc.name)
+		}
+	}
+
+	geomPointXYZ := geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{1, 2, 3}).SetSRID(4326)
+	geomPointXYM := geom.NewPoint(geom.XYM).MustSetCoords(geom.Coord{1, 2, 4})
+	for _, tc := range []struct {
+		name     string
+		geomT    geom.T
+		options  []geobabel.Option
+		expected string
+	}{
+		{
+			name:     "iso_xyz",
+			geomT:    geomPointXYZ,
+			expected: "01e9030000000000000000f03f00000000000000400000000000000840",
+		},
+		{
+			name:     "iso_xym",
+			geomT:    geomPointXYM,
+			expected: "01d1070000000000000000f03f00000000000000400000000000001040",
+		},
+		{
+			name:     "iso_srid",
+			geomT:    geomPointXYZ,
+			options:  []geobabel.Option{geobabel.WithWKBSRID(true)},
+			expected: "01e9030000000000000000f03f00000000000000400000000000000840",
+		},
+		{
+			name:     "extended_xyz",
+			geomT:    geomPointXYZ,
+			options:  []geobabel.Option{geobabel.WithWKBFlavor(geobabel.WKBFlavorExtended)},
+			expected: "0101000080000000000000f03f00000000000000400000000000000840",
+		},
+		{
+			name:     "extended_xyz_srid",
+			geomT:    geomPointXYZ,
+			options:  []geobabel.Option{geobabel.WithWKBFlavor(geobabel.WKBFlavorExtended), geobabel.WithWKBSRID(true)},
+			expected: "01010000a0e6100000000000000000f03f00000000000000400000000000000840",
+		},
+		{
+			name:     "extended_xym",
+			geomT:    geomPointXYM,
+			options:  []geobabel.Option{geobabel.WithWKBFlavor(geobabel.WKBFlavorExtended)},
+			expected: "0101000040000000000000f03f00000000000000400000000000001040",
+		},
+		{
+			name:     "big_endian",
+			geomT:    geomPointXYZ,
+			options:  []geobabel.Option{geobabel.WithWKBByteOrder(binary.BigEndian)},
+			expected: "00000003e93ff000000000000040000000000000004008000000000000",
+		},
+		{
+			name:     "layout_xy",
+			geomT:    geomPointXYZ,
+			options:  []geobabel.Option{geobabel.WithWKBLayout(geom.XY)},
+			expected: "0101000000000000000000f03f0000000000000040",
+		},
+		{
+			name:     "layout_xyzm",
+			geomT:    geomPointXYM,
+			options:  []geobabel.Option{geobabel.WithWKBLayout(geom.XYZM)},
+			expected: "01b90b0000000000000000f03f000000000000004000000000000000000000000000001040",
+		},
+		{
+			name:     "empty_point",
+			geomT:    geom.NewPointEmpty(geom.XY),
+			expected: "0101000000000000000000f87f000000000000f87f",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			wkb, err := geobabel.WKBFromGeomT(tc.geomT, tc.options...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, hex.EncodeToString(wkb))
+		})
+	}
+
+	_, err := geobabel.WKBFromGeomT(geom.NewLinearRing(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 0}, {1, 1}, {0, 0}}))
+	assert.ErrorIs(t, err, geobabel.ErrUnsupportedType)
+}
+
+func TestLinearRingPolicy(t *testing.T) {
+	geosContext := geos.NewContext()
+
+	for _, tc := range []struct {
+		name             string
+		geomT            geom.T
+		geosGeom         *geos.Geom
+		orbGeometry      orb.Geometry
+		linearRingPolicy geobabel.LinearRingPolicy
+		expected         string
+	}{
+		{
+			name:             "LineString",
+			geomT:            geom.NewLinearRing(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 0}, {1, 1}, {0, 0}}),
+			geosGeom:         geosContext.NewLinearRing([][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}),
+			orbGeometry:      orb.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}},
+			linearRingPolicy: geobabel.LinearRingPolicyLineString,
+			expected:         "010200000004000000" + "00000000000000000000000000000000" + "000000000000f03f0000000000000000" + "000000000000f03f000000000000f03f" + "00000000000000000000000000000000",
+		},
+		{
+			name:             "Polygon",
+			geomT:            geom.NewLinearRing(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 0}, {1, 1}, {0, 0}}),
+			geosGeom:         geosContext.NewLinearRing([][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}),
+			orbGeometry:      orb.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}},
+			linearRingPolicy: geobabel.LinearRingPolicyPolygon,
+			expected:         "01030000000100000004000000" + "00000000000000000000000000000000" + "000000000000f03f0000000000000000" + "000000000000f03f000000000000f03f" + "00000000000000000000000000000000",
+		},
+		{
+			name:             "EmptyLineString",
+			geomT:            geom.NewLinearRingFlat(geom.XY, []float64{}),
+			geosGeom:         geosContext.NewEmptyPolygon().ExteriorRing().Clone(),
+			orbGeometry:      orb.Ring{},
+			linearRingPolicy: geobabel.LinearRingPolicyLineString,
+			expected:         "010200000000000000",
+		},
+		{
+			name:             "EmptyPolygon",
+			geomT:            geom.NewLinearRingFlat(geom.XY, []float64{}),
+			geosGeom:         geosContext.NewEmptyPolygon().ExteriorRing().Clone(),
+			orbGeometry:      orb.Ring{},
+			linearRingPolicy: geobabel.LinearRingPolicyPolygon,
+			expected:         "010300000000000000",
+		},
+		{
+			name: "GeometryCollection",
+			geomT: geom.NewGeometryCollection().MustPush(
+				geom.NewPointEmpty(geom.XY),
+				geom.NewLinearRing(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 0}, {1, 1}, {0, 0}}),
+			),
+			geosGeom: geosContext.NewCollection(geos.TypeIDGeometryCollection, []*geos.Geom{
+				geosContext.NewEmptyPoint(),
+				geosContext.NewLinearRing([][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}),
+			}),
+			orbGeometry: orb.Collection{
+				orb.Point{math.NaN(), math.NaN()},
+				orb.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}},
+			},
+			linearRingPolicy: geobabel.LinearRingPolicyLineString,
+			expected:         "010700000002000000" + "0101000000000000000000f87f000000000000f87f" + "010200000004000000" + "00000000000000000000000000000000" + "000000000000f03f0000000000000000" + "000000000000f03f000000000000f03f" + "00000000000000000000000000000000",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := geobabel.WKBFromGeomT(tc.geomT)
+			assert.ErrorIs(t, err, geobabel.ErrUnsupportedType)
+			_, err = geobabel.WKBFromGEOSGeomE(tc.geosGeom)
+			assert.ErrorIs(t, err, geobabel.ErrUnsupportedType)
+			_, err = geobabel.WKBFromOrbGeometryE(tc.orbGeometry)
+			assert.ErrorIs(t, err, geobabel.ErrUnsupportedType)
+
+			option := geobabel.WithLinearRingPolicy(tc.linearRingPolicy)
+
+			geomWKB, err := geobabel.WKBFromGeomT(tc.geomT, option)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, hex.EncodeToString(geomWKB))
+			assert.Equal(t, geomWKB, geobabel.WKBFromGEOSGeom(tc.geosGeom, option))
+			assert.Equal(t, geomWKB, geobabel.WKBFromOrbGeometry(tc.orbGeometry, option))
+
+			geomT, err := geobabel.NewGeomTFromWKB(geomWKB, option)
+			require.NoError(t, err)
+			assert.Equal(t, tc.geomT, geomT)
+
+			geosGeom, err := geobabel.NewGEOSGeomFromWKB(geosContext, geomWKB, option)
+			require.NoError(t, err)
+			assert.True(t, tc.geosGeom.EqualsExact(geosGeom, 0))
+
+			orbGeometry, err := geobabel.NewOrbGeometryFromWKB(geomWKB, option)
+			require.NoError(t, err)
+			assertOrbGeometryEqual(t, tc.orbGeometry, orbGeometry)
+
+			ewkb, err := geobabel.EWKBFromGeomT(geom.Must(geom.SetSRID(tc.geomT, 4326)), option)
+			require.NoError(t, err)
+			geomT, err = geobabel.NewGeomTFromEWKB(ewkb, option)
+			require.NoError(t, err)
+			assert.Equal(t, geom.Must(geom.SetSRID(tc.geomT, 4326)), geomT)
+		})
+	}
+
+	t.Run("LegacyDefaults", func(t *testing.T) {
+		lineStringWKB, err := geobabel.WKBFromGeomT(geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 0}, {1, 1}, {0, 0}}))
+		require.NoError(t, err)
+		assert.Equal(t, lineStringWKB, geobabel.WKBFromGEOSGeom(geosContext.NewLinearRing([][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}})))
+		assert.Equal(t, hex.EncodeToString(lineStringWKB), strings.ToLower(geobabel.HexWKBFromGEOSGeom(geosContext.NewLinearRing([][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}))))
+
+		polygonWKB, err := geobabel.WKBFromGeomT(geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}))
+		require.NoError(t, err)
+		assert.Equal(t, polygonWKB, geobabel.WKBFromOrbGeometry(orb.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}}))
+		assert.Equal(t, polygonWKB, geobabel.WKBFromOrbGeometry(orb.Collection{orb.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}}})[9:])
+		assert.Equal(t, hex.EncodeToString(polygonWKB), strings.ToLower(geobabel.HexWKBFromOrbGeometry(orb.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}})))
+
+		assert.Equal(t, lineStringWKB, geobabel.WKBFromOrbGeometry(orb.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}}, geobabel.WithLinearRingPolicy(geobabel.LinearRingPolicyLineString)))
+	})
+
+	t.Run("NotLinearRings", func(t *testing.T) {
+		for _, tc := range []struct {
+			name             string
+			geomT            geom.T
+			linearRingPolicy geobabel.LinearRingPolicy
+		}{
+			{
+				name:             "UnclosedLineString",
+				geomT:            geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 0}, {1, 1}}),
+				linearRingPolicy: geobabel.LinearRingPolicyLineString,
+			},
+			{
+				name:             "PolygonWithHole",
+				geomT:            geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{{{0, 0}, {3, 0}, {3, 3}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}}),
+				linearRingPolicy: geobabel.LinearRingPolicyPolygon,
+			},
+			{
+				name:             "ClosedLineStringWithPolygonPolicy",
+				geomT:            geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 0}, {1, 1}, {0, 0}}),
+				linearRingPolicy: geobabel.LinearRingPolicyPolygon,
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				option := geobabel.WithLinearRingPolicy(tc.linearRingPolicy)
+				wkb, err := geobabel.WKBFromGeomT(tc.geomT, option)
+				require.NoError(t, err)
+
+				geomT, err := geobabel.NewGeomTFromWKB(wkb, option)
+				require.NoError(t, err)
+				assert.Equal(t, tc.geomT, geomT)
+
+				geosGeom, err := geobabel.NewGEOSGeomFromWKB(geosContext, wkb, option)
+				require.NoError(t, err)
+				assert.True(t, geobabel.NewGEOSGeomFromGeomT(geosContext, tc.geomT).EqualsExact(geosGeom, 0))
+
+				orbGeometry, err := geobabel.NewOrbGeometryFromWKB(wkb, option)
+				require.NoError(t, err)
+				assertOrbGeometryEqual(t, geobabel.NewOrbGeometryFromGeomT(tc.geomT), orbGeometry)
+			})
+		}
+	})
+}
+
+func TestEWKB(t *testing.T) {
+	geosContext := geos.NewContext()
+	for _, tc := range []struct {
+		name        string
+		geomT       geom.T
+		ewkbHex     string
+		orbGeometry orb.Geometry
+	}{
+		{
+			name:        "PointZ",
+			geomT:       geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{1, 2, 3}).SetSRID(4326),
+			ewkbHex:     "01010000a0e6100000000000000000f03f00000000000000400000000000000840",
+			orbGeometry: orb.Point{1, 2},
+		},
+		{
+			name: "GeometryCollection",
+			geomT: geom.NewGeometryCollection().MustPush(
+				geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+				geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{3, 4}, {5, 6}}),
+			).SetSRID(3857),
+			ewkbHex: "0107000020110f00000200000001010000000000000000" +
+				"00f03f000000000000004001020000000200000000000000000008400000000000001040" +
+				"00000000000014400000000000001840",
+			orbGeometry: orb.Collection{
+				orb.Point{1, 2},
+				orb.LineString{{3, 4}, {5, 6}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ewkb, err := geobabel.EWKBFromGeomT(tc.geomT)
+			require.NoError(t, err)
+			assert.Equal(t, tc.ewkbHex, hex.EncodeToString(ewkb))
+
+			geomT, err := geobabel.NewGeomTFromEWKB(ewkb)
+			require.NoError(t, err)
+			assert.Equal(t, tc.geomT, geomT)
+
+			geosGeom, err := geobabel.NewGEOSGeomFromEWKB(geosContext, ewkb)
+			require.NoError(t, err)
+			assert.Equal(t, tc.geomT.SRID(), geosGeom.SRID())
+			assert.Equal(t, tc.geomT, geobabel.NewGeomTFromGEOSGeom(geosGeom))
+			geosEWKB, err := geobabel.EWKBFromGEOSGeom(geosGeom)
+			require.NoError(t, err)
+			assert.Equal(t, ewkb, geosEWKB)
+
+			orbGeometry, srid, err := geobabel.NewOrbGeometryFromEWKB(ewkb)
+			require.NoError(t, err)
+			assert.Equal(t, tc.orbGeometry, orbGeometry)
+			assert.Equal(t, tc.geomT.SRID(), srid)
+
+			orbEWKB := geobabel.EWKBFromOrbGeometry(tc.orbGeometry, srid)
+			geomT, err = geobabel.NewGeomTFromEWKB(orbEWKB)
+			require.NoError(t, err)
+			assert.Equal(t, tc.geomT.SRID(), geomT.SRID())
+		})
+	}
+}
+
+func TestHexWKB(t *testing.T) {
+	geosContext := geos.NewContext()
+	const (
+		hexWKB  = "0101000000000000000000F03F0000000000000040"
+		hexEWKB = "0101000020E6100000000000000000F03F0000000000000040"
+	)
+	geomPoint := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2})
+	geomPointSRID := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}).SetSRID(4326)
+
+	for _, tc := range []struct {
+		name     string
+		hexWKB   string
+		expected geom.T
+	}{
+		{
+			name:     "wkb",
+			hexWKB:   hexWKB,
+			expected: geomPoint,
+		},
+		{
+			name:     "ewkb",
+			hexWKB:   hexEWKB,
+			expected: geomPointSRID,
+		},
+		{
+			name:     "lower_case",
+			hexWKB:   "0101000020e6100000000000000000f03f0000000000000040",
+			expected: geomPointSRID,
+		},
+		{
+			name:     "bytea",
+			hexWKB:   `\x0101000020e6100000000000000000f03f0000000000000040`,
+			expected: geomPointSRID,
+		},
+		{
+			name:     "iso_z",
+			hexWKB:   "01E9030000000000000000F03F00000000000000400000000000000840",
+			expected: geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{1, 2, 3}),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			geomT, err := geobabel.NewGeomTFromHexWKB(tc.hexWKB)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, geomT)
+
+			geosGeom, err := geobabel.NewGEOSGeomFromHexWKB(geosContext, tc.hexWKB)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, geobabel.NewGeomTFromGEOSGeom(geosGeom))
+		})
+	}
+
+	orbGeometry, srid, err := geobabel.NewOrbGeometryFromHexWKB(`\x` + hexEWKB)
+	require.NoError(t, err)
+	assert.Equal(t, orb.Point{1, 2}, orbGeometry)
+	assert.Equal(t, 4326, srid)
+
+	_, err = geobabel.NewGeomTFromHexWKB("01010000000G")
+	assert.Error(t, err)
+
+	actualHexWKB, err := geobabel.HexWKBFromGeomT(geomPoint)
+	require.NoError(t, err)
+	assert.Equal(t, hexWKB, actualHexWKB)
+	assert.Equal(t, hexWKB, geobabel.HexWKBFromOrbGeometry(orb.Point{1, 2}))
+	assert.Equal(t, hexWKB, geobabel.HexWKBFromGEOSGeom(geosContext.NewPoint([]float64{1, 2})))
+
+	actualHexEWKB, err := geobabel.HexEWKBFromGeomT(geomPointSRID)
+	require.NoError(t, err)
+	assert.Equal(t, hexEWKB, actualHexEWKB)
+	assert.Equal(t, hexEWKB, geobabel.HexEWKBFromOrbGeometry(orb.Point{1, 2}, 4326))
+	actualHexEWKB, err = geobabel.HexEWKBFromOrbGeometryE(orb.Point{1, 2}, 4326)
+	require.NoError(t, err)
+	assert.Equal(t, hexEWKB, actualHexEWKB)
+	_, err = geobabel.HexEWKBFromOrbGeometryE(orb.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}}, 4326)
+	var convertError *geobabel.ConvertError
+	assert.True(t, errors.As(err, &convertError))
+	actualHexEWKB, err = geobabel.HexEWKBFromGEOSGeom(geosContext.NewPoint([]float64{1, 2}).SetSRID(4326))
+	require.NoError(t, err)
+	assert.Equal(t, hexEWKB, actualHexEWKB)
+}
+
+func TestTWKB(t *testing.T) {
+	geosContext := geos.NewContext()
+
+	// Fixtures from PostGIS's ST_AsTWKB.
+	for _, tc := range []struct {
+		name     string
+		twkb     string
+		options  []geobabel.Option
+		expected geom.T
+	}{
+		{
+			name:     "point",
+			twkb:     "01000204",
+			expected: geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+		},
+		{
+			name:     "line_string",
+			twkb:     "02000202020808",
+			expected: geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 1}, {5, 5}}),
+		},
+		{
+			name:     "multi_point_ids",
+			twkb:     "040402020400000202",
+			options:  []geobabel.Option{geobabel.WithTWKBIDs([]int64{1, 2})},
+			expected: geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 1}}),
+		},
+		{
+			name:     "line_string_bbox_size",
+			twkb:     "020309020802080202020808",
+			options:  []geobabel.Option{geobabel.WithTWKBBBox(true), geobabel.WithTWKBSize(true)},
+			expected: geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 1}, {5, 5}}),
+		},
+		{
+			name:     "precision",
+			twkb:     "2100f601b002",
+			options:  []geobabel.Option{geobabel.WithTWKBPrecision(1, 0, 0)},
+			expected: geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{12.3, 15.2}),
+		},
+		{
+			name:     "xyz",
+			twkb:     "21080502040f",
+			options:  []geobabel.Option{geobabel.WithTWKBPrecision(1, 1, 0)},
+			expected: geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{0.1, 0.2, -0.8}),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			twkb, err := hex.DecodeString(tc.twkb)
+			require.NoError(t, err)
+
+			geomT, err := geobabel.NewGeomTFromTWKB(twkb)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, geomT)
+
+			actualTWKB, err := geobabel.TWKBFromGeomT(tc.expected, tc.options...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.twkb, hex.EncodeToString(actualTWKB))
+		})
+	}
+
+	for _, tc := range []struct {
+		name        string
+		geomT       geom.T
+		geosGeom    *geos.Geom
+		orbGeometry orb.Geometry
+	}{
+		{
+			name:        "Point",
+			geomT:       geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+			geosGeom:    geosContext.NewPoint([]float64{1, 2}),
+			orbGeometry: orb.Point{1, 2},
+		},
+		{
+			name:        "EmptyPoint",
+			geomT:       geom.NewPointEmpty(geom.XY),
+			geosGeom:    geosContext.NewEmptyPoint(),
+			orbGeometry: orb.Point{math.NaN(), math.NaN()},
+		},
+		{
+			name:        "LineString",
+			geomT:       geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{-1.5, 2.25}, {3.125, -4}}),
+			geosGeom:    geosContext.NewLineString([][]float64{{-1.5, 2.25}, {3.125, -4}}),
+			orbGeometry: orb.LineString{{-1.5, 2.25}, {3.125, -4}},
+		},
+		{
+			name:        "Polygon",
+			geomT:       geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{{{0, 0}, {3, 0}, {3, 3}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}}),
+			geosGeom:    geosContext.NewPolygon([][][]float64{{{0, 0}, {3, 0}, {3, 3}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}}),
+			orbGeometry: orb.Polygon{{{0, 0}, {3, 0}, {3, 3}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}},
+		},
+		{
+			name:        "EmptyPolygon",
+			geomT:       geom.NewPolygon(geom.XY),
+			geosGeom:    geosContext.NewEmptyPolygon(),
+			orbGeometry: orb.Polygon{},
+		},
+		{
+			name:        "MultiPoint",
+			geomT:       geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+			geosGeom:    geosContext.NewCollection(geos.TypeIDMultiPoint, []*geos.Geom{geosContext.NewPoint([]float64{1, 2}), geosContext.NewPoint([]float64{3, 4})}),
+			orbGeometry: orb.MultiPoint{{1, 2}, {3, 4}},
+		},
+		{
+			name:        "MultiLineString",
+			geomT:       geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}}}),
+			geosGeom:    geosContext.NewCollection(geos.TypeIDMultiLineString, []*geos.Geom{geosContext.NewLineString([][]float64{{1, 2}, {3, 4}}), geosContext.NewLineString([][]float64{{5, 6}, {7, 8}})}),
+			orbGeometry: orb.MultiLineString{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}}},
+		},
+		{
+			name: "MultiPolygon",
+			geomT: geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+				{{{2, 1}, {3, 1}, {3, 2}, {2, 1}}},
+			}),
+			geosGeom: geosContext.NewCollection(geos.TypeIDMultiPolygon, []*geos.Geom{
+				geosContext.NewPolygon([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}),
+				geosContext.NewPolygon([][][]float64{{{2, 1}, {3, 1}, {3, 2}, {2, 1}}}),
+			}),
+			orbGeometry: orb.MultiPolygon{
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+				{{{2, 1}, {3, 1}, {3, 2}, {2, 1}}},
+			},
+		},
+		{
+			name: "GeometryCollection",
+			geomT: geom.NewGeometryCollection().MustPush(
+				geom.NewPointEmpty(geom.XY),
+				geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-5, 6}),
+				geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+			),
+			geosGeom: geosContext.NewCollection(geos.TypeIDGeometryCollection, []*geos.Geom{
+				geosContext.NewEmptyPoint(),
+				geosContext.NewPoint([]float64{-5, 6}),
+				geosContext.NewLineString([][]float64{{1, 2}, {3, 4}}),
+			}),
+			orbGeometry: orb.Collection{
+				orb.Point{math.NaN(), math.NaN()},
+				orb.Point{-5, 6},
+				orb.LineString{{1, 2}, {3, 4}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, options := range [][]geobabel.Option{
+				{geobabel.WithTWKBPrecision(3, 0, 0)},
+				{geobabel.WithTWKBPrecision(3, 0, 0), geobabel.WithTWKBBBox(true)},
+				{geobabel.WithTWKBPrecision(3, 0, 0), geobabel.WithTWKBSize(true)},
+				{geobabel.WithTWKBPrecision(3, 0, 0), geobabel.WithTWKBBBox(true), geobabel.WithTWKBSize(true)},
+			} {
+				geomTWKB, err := geobabel.TWKBFromGeomT(tc.geomT, options...)
+				require.NoError(t, err)
+				geosTWKB, err := geobabel.TWKBFromGEOSGeom(tc.geosGeom, options...)
+				require.NoError(t, err)
+				assert.Equal(t, geomTWKB, geosTWKB)
+				orbTWKB, err := geobabel.TWKBFromOrbGeometry(tc.orbGeometry, options...)
+				require.NoError(t, err)
+				assert.Equal(t, geomTWKB, orbTWKB)
+
+				expectedWKB, err := geobabel.WKBFromGeomT(tc.geomT)
+				require.NoError(t, err)
+
+				geomT, err := geobabel.NewGeomTFromTWKB(geomTWKB)
+				require.NoError(t, err)
+				geomWKB, err := geobabel.WKBFromGeomT(geomT)
+				require.NoError(t, err)
+				assert.Equal(t, expectedWKB, geomWKB)
+
+				geosGeom, err := geobabel.NewGEOSGeomFromTWKB(geosContext, geomTWKB)
+				require.NoError(t, err)
+				assert.Equal(t, expectedWKB, geobabel.WKBFromGEOSGeom(geosGeom))
+
+				orbGeometry, err := geobabel.NewOrbGeometryFromTWKB(geomTWKB)
+				require.NoError(t, err)
+				assert.Equal(t, expectedWKB, geobabel.WKBFromOrbGeometry(orbGeometry))
+			}
+		})
+	}
+
+	t.Run("xyzm", func(t *testing.T) {
+		geomT := geom.NewLineString(geom.XYZM).MustSetCoords([]geom.Coord{{1.25, 2.5, 3.125, 4}, {5, 6, 7, 8.5}})
+		twkb, err := geobabel.TWKBFromGeomT(geomT, geobabel.WithTWKBPrecision(2, 3, 1), geobabel.WithTWKBBBox(true))
+		require.NoError(t, err)
+		actualGeomT, err := geobabel.NewGeomTFromTWKB(twkb)
+		require.NoError(t, err)
+		assert.Equal(t, geomT, actualGeomT)
+	})
+
+	t.Run("repeated_points", func(t *testing.T) {
+		geomT := geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1.1, 0}, {0.9, 0}, {2, 0}})
+		twkb, err := geobabel.TWKBFromGeomT(geomT)
+		require.NoError(t, err)
+		actualGeomT, err := geobabel.NewGeomTFromTWKB(twkb)
+		require.NoError(t, err)
+		assert.Equal(t, geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 0}, {2, 0}}), actualGeomT)
+	})
+
+	t.Run("ids", func(t *testing.T) {
+		geomT := geom.NewGeometryCollection().MustPush(
+			geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+			geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{3, 4}),
+		)
+		twkb, err := geobabel.TWKBFromGeomT(geomT, geobabel.WithTWKBIDs([]int64{-1, 1 << 40}), geobabel.WithTWKBSize(true))
+		require.NoError(t, err)
+		ids, err := geobabel.TWKBIDs(twkb)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{-1, 1 << 40}, ids)
+		actualGeomT, err := geobabel.NewGeomTFromTWKB(twkb)
+		require.NoError(t, err)
+		assert.Equal(t, geomT, actualGeomT)
+
+		_, err = geobabel.TWKBFromGeomT(geomT, geobabel.WithTWKBIDs([]int64{1}))
+		assert.EqualError(t, err, "*geom.GeometryCollection: 1 TWKB IDs for 2 geometries")
+		_, err = geobabel.TWKBFromGeomT(geom.NewPointEmpty(geom.XY), geobabel.WithTWKBIDs([]int64{}))
+		assert.Error(t, err)
+	})
+
+	t.Run("linear_ring", func(t *testing.T) {
+		geomLinearRing := geom.NewLinearRing(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 0}, {1, 1}, {0, 0}})
+		_, err := geobabel.TWKBFromGeomT(geomLinearRing)
+		assert.ErrorIs(t, err, geobabel.ErrUnsupportedType)
+		_, err = geobabel.TWKBFromOrbGeometry(orb.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}})
+		assert.ErrorIs(t, err, geobabel.ErrUnsupportedType)
+		for _, linearRingPolicy := range []geobabel.LinearRingPolicy{
+			geobabel.LinearRingPolicyLineString,
+			geobabel.LinearRingPolicyPolygon,
+		} {
+			option := geobabel.WithLinearRingPolicy(linearRingPolicy)
+			twkb, err := geobabel.TWKBFromGeomT(geomLinearRing, option)
+			require.NoError(t, err)
+			geomT, err := geobabel.NewGeomTFromTWKB(twkb, option)
+			require.NoError(t, err)
+			assert.Equal(t, geomLinearRing, geomT)
+			orbGeometry, err := geobabel.NewOrbGeometryFromTWKB(twkb, option)
+			require.NoError(t, err)
+			assert.Equal(t, orb.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}}, orbGeometry)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		geomPoint := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2})
+		_, err := geobabel.TWKBFromGeomT(geomPoint, geobabel.WithTWKBPrecision(8, 0, 0))
+		assert.Error(t, err)
+		_, err = geobabel.TWKBFromOrbGeometry(orb.MultiPoint{{math.NaN(), math.NaN()}})
+		assert.Error(t, err)
+		for _, twkb := range []string{
+			"",
+			"01",
+			"0100",
+			"010002",
+			"08000204",
+			"0200ff",
+			"0200020202",
+			"0100020400",
+		} {
+			data, err := hex.DecodeString(twkb)
+			require.NoError(t, err)
+			_, err = geobabel.NewGeomTFromTWKB(data)
+			assert.Error(t, err, twkb)
+		}
+	})
+}
+
+func TestSRID(t *testing.T) {
+	geosContext := geos.NewContext()
+
+	geomPoint := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}).SetSRID(4326)
+	geosPoint := geobabel.NewGEOSGeomFromGeomT(geosContext, geomPoint)
+	assert.Equal(t, 4326, geosPoint.SRID())
+	assert.Equal(t, geomPoint, geobabel.NewGeomTFromGEOSGeom(geosPoint))
+	assert.Equal(t, geomPoint, geobabel.NewGeomPointFromGEOSGeom(geosPoint))
+
+	_, _, err := geobabel.NewOrbGeometryFromEWKB(
+		geobabel.EWKBFromOrbGeometry(orb.Point{1, 2}, 4326)[:10],
+	)
+	assert.Error(t, err)
+}
+
+func TestWKT(t *testing.T) {
+	geosContext := geos.NewContext()
+	for _, tc := range []struct {
+		name     string
+		geomT    geom.T
+		options  []geobabel.Option
+		wkt      string
+		ewkt     string
+		skipOrb  bool
+		skipGEOS bool
+	}{
+		{
+			name:  "Point",
+			geomT: geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2.5}),
+			wkt:   "POINT (1 2.5)",
+			ewkt:  "POINT (1 2.5)",
+		},
+		{
+			name:  "PointSRID",
+			geomT: geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}).SetSRID(4326),
+			wkt:   "POINT (1 2)",
+			ewkt:  "SRID=4326;POINT (1 2)",
+		},
+		{
+			name:    "PointMaxDecimalDigits",
+			geomT:   geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1.0 / 3, 2.5}),
+			options: []geobabel.Option{geobabel.WithMaxDecimalDigits(3)},
+			wkt:     "POINT (0.333 2.5)",
+			ewkt:    "POINT (0.333 2.5)",
+		},
+		{
+			name:    "LineStringZ",
+			geomT:   geom.NewLineString(geom.XYZ).MustSetCoords([]geom.Coord{{1, 2, 3}, {4, 5, 6}}).SetSRID(3857),
+			wkt:     "LINESTRING Z (1 2 3, 4 5 6)",
+			ewkt:    "SRID=3857;LINESTRING Z (1 2 3, 4 5 6)",
+			skipOrb: true,
+		},
+		{
+			name: "Polygon",
+			geomT: geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+				{{0, 0}, {4, 0}, {4, 4}, {0, 0}},
+				{{2, 1}, {3, 1}, {3, 2}, {2, 1}},
+			}),
+			wkt:  "POLYGON ((0 0, 4 0, 4 4, 0 0), (2 1, 3 1, 3 2, 2 1))",
+			ewkt: "POLYGON ((0 0, 4 0, 4 4, 0 0), (2 1, 3 1, 3 2, 2 1))",
+		},
+		{
+			name:  "EmptyPoint",
+			geomT: geom.NewPointEmpty(geom.XY),
+			wkt:   "POINT EMPTY",
+			ewkt:  "POINT EMPTY",
+		},
+		{
+			name: "GeometryCollection",
+			geomT: geom.NewGeometryCollection().MustPush(
+				geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+				geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{nil, {3, 4}}),
+			).SetSRID(4326),
+			wkt:  "GEOMETRYCOLLECTION (POINT (1 2), MULTIPOINT (EMPTY, 3 4))",
+			ewkt: "SRID=4326;GEOMETRYCOLLECTION (POINT (1 2), MULTIPOINT (EMPTY, 3 4))",
+		},
+		{
+			name:  "EmptyGeometryCollection",
+			geomT: geom.NewGeometryCollection().MustSetLayout(geom.XY),
+			wkt:   "GEOMETRYCOLLECTION EMPTY",
+			ewkt:  "GEOMETRYCOLLECTION EMPTY",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			wkt, err := geobabel.WKTFromGeomT(tc.geomT, tc.options...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wkt, wkt)
+			ewkt, err := geobabel.EWKTFromGeomT(tc.geomT, tc.options...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.ewkt, ewkt)
+
+			geomT, err := geobabel.NewGeomTFromEWKT(tc.ewkt)
+			require.NoError(t, err)
+			assert.Equal(t, tc.ewkt, mustEWKTFromGeomT(t, geomT, tc.options...))
+
+			geosGeom := geobabel.NewGEOSGeomFromGeomT(geosContext, tc.geomT)
+			wkt, err = geobabel.WKTFromGEOSGeom(geosGeom, tc.options...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wkt, wkt)
+			ewkt, err = geobabel.EWKTFromGEOSGeom(geosGeom, tc.options...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.ewkt, ewkt)
+
+			geosGeom, err = geobabel.NewGEOSGeomFromEWKT(geosContext, tc.ewkt)
+			require.NoError(t, err)
+			assert.Equal(t, tc.geomT.SRID(), geosGeom.SRID())
+
+			if tc.skipOrb {
+				return
+			}
+
+			orbGeometry := geobabel.NewOrbGeometryFromGeomT(tc.geomT)
+			wkt, err = geobabel.WKTFromOrbGeometry(orbGeometry, tc.options...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wkt, wkt)
+			ewkt, err = geobabel.EWKTFromOrbGeometry(orbGeometry, tc.geomT.SRID(), tc.options...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.ewkt, ewkt)
+
+			orbGeometry, srid, err := geobabel.NewOrbGeometryFromEWKT(tc.ewkt)
+			require.NoError(t, err)
+			assert.Equal(t, tc.geomT.SRID(), srid)
+			wkt, err = geobabel.WKTFromOrbGeometry(orbGeometry, tc.options...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wkt, wkt)
+		})
+	}
+}
+
+func TestEWKTErrors(t *testing.T) {
+	for _, ewkt := range []string{
+		"SRID=4326 POINT (1 2)",
+		"SRID=x;POINT (1 2)",
+		"SRID=4326;POINT (1)",
+	} {
+		_, err := geobabel.NewGeomTFromEWKT(ewkt)
+		assert.Error(t, err, ewkt)
+	}
+}
+
+func TestGeoJSON(t *testing.T) {
+	geosContext := geos.NewContext()
+	for _, tc := range []struct {
+		name     string
+		geomT    geom.T
+		options  []geobabel.Option
+		geoJSON  string
+		expected geom.T
+		skipOrb  bool
+	}{
+		{
+			name:    "Point",
+			geomT:   geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2.5}),
+			geoJSON: `{"type":"Point","coordinates":[1,2.5]}`,
+		},
+		{
+			name:     "PointMaxDecimalDigits",
+			geomT:    geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1.0 / 3, 0.0000001}),
+			options:  []geobabel.Option{geobabel.WithMaxDecimalDigits(3)},
+			geoJSON:  `{"type":"Point","coordinates":[0.333,0]}`,
+			expected: geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{0.333, 0}),
+		},
+		{
+			name:    "LineStringZ",
+			geomT:   geom.NewLineString(geom.XYZ).MustSetCoords([]geom.Coord{{1, 2, 3}, {4, 5, 6}}),
+			geoJSON: `{"type":"LineString","coordinates":[[1,2,3],[4,5,6]]}`,
+			skipOrb: true,
+		},
+		{
+			name:     "LineStringM",
+			geomT:    geom.NewLineString(geom.XYM).MustSetCoords([]geom.Coord{{1, 2, 3}, {4, 5, 6}}),
+			geoJSON:  `{"type":"LineString","coordinates":[[1,2],[4,5]]}`,
+			expected: geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {4, 5}}),
+			skipOrb:  true,
+		},
+		{
+			name:     "LineStringZM",
+			geomT:    geom.NewLineString(geom.XYZM).MustSetCoords([]geom.Coord{{1, 2, 3, 4}, {5, 6, 7, 8}}),
+			geoJSON:  `{"type":"LineString","coordinates":[[1,2,3],[5,6,7]]}`,
+			expected: geom.NewLineString(geom.XYZ).MustSetCoords([]geom.Coord{{1, 2, 3}, {5, 6, 7}}),
+			skipOrb:  true,
+		},
+		{
+			name:    "LineStringBBox",
+			geomT:   geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 0}}),
+			options: []geobabel.Option{geobabel.WithGeoJSONBBox(true)},
+			geoJSON: `{"type":"LineString","bbox":[1,0,3,2],"coordinates":[[1,2],[3,0]]}`,
+		},
+		{
+			name: "PolygonRightHandRule",
+			geomT: geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+				{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {0, 0}},
+				{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+			}),
+			options: []geobabel.Option{geobabel.WithRightHandRule(true)},
+			geoJSON: `{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[2,2],[2,1],[1,1]]]}`,
+			expected: geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+				{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+				{{1, 1}, {2, 2}, {2, 1}, {1, 1}},
+			}),
+		},
+		{
+			name: "PolygonWithoutRightHandRule",
+			geomT: geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+				{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {0, 0}},
+			}),
+			geoJSON: `{"type":"Polygon","coordinates":[[[0,0],[0,4],[4,4],[4,0],[0,0]]]}`,
+		},
+		{
+			name:    "EmptyPointBBox",
+			geomT:   geom.NewPointEmpty(geom.XY),
+			options: []geobabel.Option{geobabel.WithGeoJSONBBox(true)},
+			geoJSON: `{"type":"Point","coordinates":[]}`,
+		},
+		{
+			name: "GeometryCollection",
+			geomT: geom.NewGeometryCollection().MustPush(
+				geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+				geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{
+					{{{0, 0}, {0, 1}, {1, 1}, {0, 0}}},
+				}),
+			),
+			options: []geobabel.Option{geobabel.WithRightHandRule(true)},
+			geoJSON: `{"type":"GeometryCollection","geometries":[` +
+				`{"type":"Point","coordinates":[1,2]},` +
+				`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,1],[0,1],[0,0]]]]}` +
+				`]}`,
+			expected: geom.NewGeometryCollection().MustPush(
+				geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+				geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{
+					{{{0, 0}, {1, 1}, {0, 1}, {0, 0}}},
+				}),
+			),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			expected := tc.expected
+			if expected == nil {
+				expected = tc.geomT
+			}
+
+			geoJSON, err := geobabel.GeoJSONFromGeomT(tc.geomT, tc.options...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.geoJSON, string(geoJSON))
+
+			geomT, err := geobabel.NewGeomTFromGeoJSON(geoJSON)
+			require.NoError(t, err)
+			assert.Equal(t, mustEWKTFromGeomT(t, expected), mustEWKTFromGeomT(t, geomT))
+
+			geosGeom := geobabel.NewGEOSGeomFromGeomT(geosContext, tc.geomT, geobabel.WithZMPolicy(geobabel.ZMPolicyKeep))
+			geoJSON, err = geobabel.GeoJSONFromGEOSGeom(geosGeom, tc.options...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.geoJSON, string(geoJSON))
+
+			geosGeom, err = geobabel.NewGEOSGeomFromGeoJSON(geosContext, geoJSON)
+			require.NoError(t, err)
+			assert.True(t, geobabel.NewGEOSGeomFromGeomT(geosContext, expected).EqualsExact(geosGeom, 0))
+
+			if tc.skipOrb {
+				return
+			}
+
+			orbGeometry := geobabel.NewOrbGeometryFromGeomT(tc.geomT)
+			geoJSON, err = geobabel.GeoJSONFromOrbGeometry(orbGeometry, tc.options...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.geoJSON, string(geoJSON))
+
+			orbGeometry, err = geobabel.NewOrbGeometryFromGeoJSON(geoJSON)
+			require.NoError(t, err)
+			assertOrbGeometryEqual(t, geobabel.NewOrbGeometryFromGeomT(expected), orbGeometry)
+		})
+	}
+}
+
+func TestGeoJSONZMPolicyError(t *testing.T) {
+	geomT := geom.NewGeometryCollection().MustPush(
+		geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+		geom.NewPoint(geom.XYM).MustSetCoords(geom.Coord{1, 2, 3}),
+	)
+	_, err := geobabel.GeoJSONFromGeomT(geomT, geobabel.WithZMPolicy(geobabel.ZMPolicyError))
+	assert.ErrorIs(t, err, geobabel.ErrUnsupportedLayout)
+	var convertError *geobabel.ConvertError
+	require.True(t, errors.As(err, &convertError))
+	assert.Equal(t, []int{1}, convertError.Path)
+}
+
+func TestFeatureCollection(t *testing.T) {
+	orbFeatureCollection := orbgeojson.NewFeatureCollection()
+	orbFeatureCollection.BBox = orbgeojson.BBox{0, 0, 3, 4}
+	orbFeatureCollection.Append(&orbgeojson.Feature{
+		ID:       "a",
+		Type:     "Feature",
+		BBox:     orbgeojson.BBox{1, 2, 1, 2},
+		Geometry: orb.Point{1, 2},
+		Properties: orbgeojson.Properties{
+			"name": "point",
+		},
+	})
+	orbFeatureCollection.Append(&orbgeojson.Feature{
+		ID:       float64(2),
+		Type:     "Feature",
+		Geometry: orb.LineString{{0, 0}, {3, 4}},
+	})
+	orbFeatureCollection.Append(&orbgeojson.Feature{
+		Type: "Feature",
+	})
+
+	geomFeatureCollection, err := geobabel.NewGeomFeatureCollectionFromOrbFeatureCollection(orbFeatureCollection)
+	require.NoError(t, err)
+	assert.Equal(t, geom.NewBounds(geom.XY).Set(0, 0, 3, 4), geomFeatureCollection.BBox)
+	require.Len(t, geomFeatureCollection.Features, 3)
+	assert.Equal(t, "a", geomFeatureCollection.Features[0].ID)
+	assert.Equal(t, geom.NewBounds(geom.XY).Set(1, 2, 1, 2), geomFeatureCollection.Features[0].BBox)
+	assert.Equal(t, geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}), geomFeatureCollection.Features[0].Geometry)
+	assert.Equal(t, map[string]interface{}{"name": "point"}, geomFeatureCollection.Features[0].Properties)
+	assert.Equal(t, "2", geomFeatureCollection.Features[1].ID)
+	assert.Nil(t, geomFeatureCollection.Features[1].BBox)
+	assert.Equal(t, "", geomFeatureCollection.Features[2].ID)
+	assert.Nil(t, geomFeatureCollection.Features[2].Geometry)
+
+	actualOrbFeatureCollection, err := geobabel.NewOrbFeatureCollectionFromGeomFeatureCollection(geomFeatureCollection)
+	require.NoError(t, err)
+	assert.Equal(t, orbFeatureCollection.BBox, actualOrbFeatureCollection.BBox)
+	require.Len(t, actualOrbFeatureCollection.Features, 3)
+	assert.Equal(t, orbFeatureCollection.Features[0], actualOrbFeatureCollection.Features[0])
+	assert.Equal(t, "2", actualOrbFeatureCollection.Features[1].ID)
+	assert.Equal(t, orbFeatureCollection.Features[2], actualOrbFeatureCollection.Features[2])
+
+	geoJSON, err := json.Marshal(geomFeatureCollection)
+	require.NoError(t, err)
+	actualGeoJSON, err := json.Marshal(actualOrbFeatureCollection)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(geoJSON), string(actualGeoJSON))
+}
+
+func TestFeatureCollectionErrors(t *testing.T) {
+	orbFeatureCollection := orbgeojson.NewFeatureCollection()
+	orbFeatureCollection.Append(orbgeojson.NewFeature(orb.Point{1, 2}))
+	orbFeatureCollection.Append(orbgeojson.NewFeature(customOrbGeometry{}))
+	_, err := geobabel.NewGeomFeatureCollectionFromOrbFeatureCollection(orbFeatureCollection)
+	assert.ErrorIs(t, err, geobabel.ErrUnsupportedType)
+	var convertError *geobabel.ConvertError
+	require.True(t, errors.As(err, &convertError))
+	assert.Equal(t, []int{1}, convertError.Path)
+
+	_, err = geobabel.NewGeomFeatureFromOrbFeature(&orbgeojson.Feature{
+		BBox: orbgeojson.BBox{1, 2, 3},
+	})
+	assert.Error(t, err)
+
+	_, err = geobabel.NewGeomFeatureFromOrbFeature(&orbgeojson.Feature{
+		ID: true,
+	})
+	assert.Error(t, err)
+}
+
+func TestTypedOrbFromGEOSGeom(t *testing.T) {
+	geosContext := geos.NewContext()
+	geosPolygon := geosContext.NewPolygon([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}})
+	orbPolygon := orb.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}
+
+	_, err := geobabel.NewOrbMultiPolygonFromGEOSGeomE(geosPolygon)
+	assert.ErrorIs(t, err, geobabel.ErrUnexpectedType)
+	assert.EqualError(t, err, "Polygon: unexpected type: expected MultiPolygon")
+	assert.Panics(t, func() {
+		geobabel.NewOrbMultiPolygonFromGEOSGeom(geosPolygon)
+	})
+
+	orbMultiPolygon, err := geobabel.NewOrbMultiPolygonFromGEOSGeomE(geosPolygon, geobabel.WithPromoteToMulti(true))
+	require.NoError(t, err)
+	assert.Equal(t, orb.MultiPolygon{orbPolygon}, orbMultiPolygon)
+
+	orbMultiPolygon, err = geobabel.NewOrbMultiPolygonFromGEOSGeomE(geosContext.NewEmptyPolygon(), geobabel.WithPromoteToMulti(true))
+	require.NoError(t, err)
+	assert.Equal(t, orb.MultiPolygon{}, orbMultiPolygon)
+
+	orbCollection, err := geobabel.NewOrbCollectionFromGEOSGeomE(geosPolygon, geobabel.WithPromoteToMulti(true))
+	require.NoError(t, err)
+	assert.Equal(t, orb.Collection{orbPolygon}, orbCollection)
+
+	geosGeometryCollection := geosContext.NewCollection(geos.TypeIDGeometryCollection, []*geos.Geom{
+		geosContext.NewCollection(geos.TypeIDMultiPolygon, []*geos.Geom{geosPolygon.Clone()}),
+	})
+	_, err = geobabel.NewOrbPolygonFromGEOSGeomE(geosGeometryCollection)
+	assert.ErrorIs(t, err, geobabel.ErrUnexpectedType)
+	actualOrbPolygon, err := geobabel.NewOrbPolygonFromGEOSGeomE(geosGeometryCollection, geobabel.WithUnwrapSingletons(true))
+	require.NoError(t, err)
+	assert.Equal(t, orbPolygon, actualOrbPolygon)
+	orbMultiPolygon, err = geobabel.NewOrbMultiPolygonFromGEOSGeomE(geosGeometryCollection, geobabel.WithUnwrapSingletons(true))
+	require.NoError(t, err)
+	assert.Equal(t, orb.MultiPolygon{orbPolygon}, orbMultiPolygon)
+
+	_, err = geobabel.NewOrbRingFromGEOSGeomE(geosPolygon.ExteriorRing())
+	require.NoError(t, err)
+	_, err = geobabel.NewOrbRingFromGEOSGeomE(geosContext.NewLineString([][]float64{{0, 0}, {1, 1}}))
+	assert.ErrorIs(t, err, geobabel.ErrUnexpectedType)
+
+	_, err = geobabel.NewOrbPointFromGEOSGeomE(geosContext.NewPoint([]float64{1, 2, 3}), geobabel.WithZMPolicy(geobabel.ZMPolicyError))
+	assert.ErrorIs(t, err, geobabel.ErrUnsupportedLayout)
+}
+
+func TestTyped(t *testing.T) {
+	geosContext := geos.NewContext()
+	tests := []struct {
+		name            string
+		geomT           geom.T
+		geosGeom        *geos.Geom
+		orbGeometry     orb.Geometry
+		newGeomFromOrb  func(orb.Geometry) geom.T
+		newGeomFromGEOS func(*geos.Geom) (geom.T, error)
+		newOrbFromGeom  func(geom.T) (orb.Geometry, error)
+		newOrbFromFlat  func(geom.T) orb.Geometry
+		newOrbFromGEOS  func(*geos.Geom) (orb.Geometry, error)
+		newGEOSFromGeom func(*geos.Context, geom.T) (*geos.Geom, error)
+		newGEOSFromOrb  func(*geos.Context, orb.Geometry) (*geos.Geom, error)
+	}{
+		{
+			name:        "Point",
+			geomT:       geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+			geosGeom:    geosContext.NewPoint([]float64{1, 2}),
+			orbGeometry: orb.Point{1, 2},
+			newGeomFromOrb: func(orbGeometry orb.Geometry) geom.T {
+				return geobabel.NewGeomPointFromOrbPoint(orbGeometry.(orb.Point))
+			},
+			newGeomFromGEOS: func(geosGeom *geos.Geom) (geom.T, error) {
+				return geobabel.NewGeomPointFromGEOSGeomE(geosGeom)
+			},
+			newOrbFromGeom: func(geomT geom.T) (orb.Geometry, error) {
+				return geobabel.NewOrbPointFromGeomPointE(geomT.(*geom.Point))
+			},
+			newOrbFromFlat: func(geomT geom.T) orb.Geometry {
+				return geobabel.NewOrbPointFromGeomFlatCoords(geomT.FlatCoords())
+			},
+			newOrbFromGEOS: func(geosGeom *geos.Geom) (orb.Geometry, error) {
+				return geobabel.NewOrbPointFromGEOSGeomE(geosGeom)
+			},
+			newGEOSFromGeom: func(geosContext *geos.Context, geomT geom.T) (*geos.Geom, error) {
+				return geobabel.NewGEOSGeomFromGeomPointE(geosContext, geomT.(*geom.Point))
+			},
+			newGEOSFromOrb: func(geosContext *geos.Context, orbGeometry orb.Geometry) (*geos.Geom, error) {
+				return geobabel.NewGEOSGeomFromOrbPointE(geosContext, orbGeometry.(orb.Point))
+			},
+		},
+		{
+			name:        "LineString",
+			geomT:       geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+			geosGeom:    geosContext.NewLineString([][]float64{{1, 2}, {3, 4}}),
+			orbGeometry: orb.LineString{{1, 2}, {3, 4}},
+			newGeomFromOrb: func(orbGeometry orb.Geometry) geom.T {
+				return geobabel.NewGeomLineStringFromOrbLineString(orbGeometry.(orb.LineString))
+			},
+			newGeomFromGEOS: func(geosGeom *geos.Geom) (geom.T, error) {
+				return geobabel.NewGeomLineStringFromGEOSGeomE(geosGeom)
+			},
+			newOrbFromGeom: func(geomT geom.T) (orb.Geometry, error) {
+				return geobabel.NewOrbLineStringFromGeomLineStringE(geomT.(*geom.LineString))
+			},
+			newOrbFromFlat: func(geomT geom.T) orb.Geometry {
+				return geobabel.NewOrbLineStringFromGeomFlatCoords(geomT.FlatCoords(), geomT.Stride())
+			},
+			newOrbFromGEOS: func(geosGeom *geos.Geom) (orb.Geometry, error) {
+				return geobabel.NewOrbLineStringFromGEOSGeomE(geosGeom)
+			},
+			newGEOSFromGeom: func(geosContext *geos.Context, geomT geom.T) (*geos.Geom, error) {
+				return geobabel.NewGEOSGeomFromGeomLineStringE(geosContext, geomT.(*geom.LineString))
+			},
+			newGEOSFromOrb: func(geosContext *geos.Context, orbGeometry orb.Geometry) (*geos.Geom, error) {
+				return geobabel.NewGEOSGeomFromOrbLineStringE(geosContext, orbGeometry.(orb.LineString))
+			},
+		},
+		{
+			name:        "LinearRing",
+			geomT:       geom.NewLinearRing(geom.XY).MustSetCoords([]geom.Coord{{1, 4}, {5, 2}, {3, 6}, {1, 4}}),
+			geosGeom:    geosContext.NewLinearRing([][]float64{{1, 4}, {5, 2}, {3, 6}, {1, 4}}),
+			orbGeometry: orb.Ring{{1, 4}, {5, 2}, {3, 6}, {1, 4}},
+			newGeomFromOrb: func(orbGeometry orb.Geometry) geom.T {
+				return geobabel.NewGeomLinearRingFromOrbRing(orbGeometry.(orb.Ring))
+			},
+			newGeomFromGEOS: func(geosGeom *geos.Geom) (geom.T, error) {
+				return geobabel.NewGeomLinearRingFromGEOSGeomE(geosGeom)
+			},
+			newOrbFromGeom: func(geomT geom.T) (orb.Geometry, error) {
+				return geobabel.NewOrbRingFromGeomLinearRingE(geomT.(*geom.LinearRing))
+			},
+			newOrbFromFlat: func(geomT geom.T) orb.Geometry {
+				return geobabel.NewOrbRingFromGeomFlatCoords(geomT.FlatCoords(), geomT.Stride())
+			},
+			newOrbFromGEOS: func(geosGeom *geos.Geom) (orb.Geometry, error) {
+				return geobabel.NewOrbRingFromGEOSGeomE(geosGeom)
+			},
+			newGEOSFromGeom: func(geosContext *geos.Context, geomT geom.T) (*geos.Geom, error) {
+				return geobabel.NewGEOSGeomFromGeomLinearRingE(geosContext, geomT.(*geom.LinearRing))
+			},
+			newGEOSFromOrb: func(geosContext *geos.Context, orbGeometry orb.Geometry) (*geos.Geom, error) {
+				return geobabel.NewGEOSGeomFromOrbRingE(geosContext, orbGeometry.(orb.Ring))
+			},
+		},
+		{
+			name: "Polygon",
+			geomT: geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+				{{0, 0}, {4, 0}, {4, 4}, {0, 0}},
+				{{2, 1}, {3, 1}, {3, 2}, {2, 1}},
+			}),
+			geosGeom: geosContext.NewPolygon([][][]float64{
+				{{0, 0}, {4, 0}, {4, 4}, {0, 0}},
+				{{2, 1}, {3, 1}, {3, 2}, {2, 1}},
+			}),
+			orbGeometry: orb.Polygon{
+				{{0, 0}, {4, 0}, {4, 4}, {0, 0}},
+				{{2, 1}, {3, 1}, {3, 2}, {2, 1}},
+			},
+			newGeomFromOrb: func(orbGeometry orb.Geometry) geom.T {
+				return geobabel.NewGeomPolygonFromOrbPolygon(orbGeometry.(orb.Polygon))
+			},
+			newGeomFromGEOS: func(geosGeom *geos.Geom) (geom.T, error) {
+				return geobabel.NewGeomPolygonFromGEOSGeomE(geosGeom)
+			},
+			newOrbFromGeom: func(geomT geom.T) (orb.Geometry, error) {
+				return geobabel.NewOrbPolygonFromGeomPolygonE(geomT.(*geom.Polygon))
+			},
+			newOrbFromFlat: func(geomT geom.T) orb.Geometry {
+				return geobabel.NewOrbPolygonFromGeomFlatCoords(geomT.FlatCoords(), geomT.Ends(), geomT.Stride())
+			},
+			newOrbFromGEOS: func(geosGeom *geos.Geom) (orb.Geometry, error) {
+				return geobabel.NewOrbPolygonFromGEOSGeomE(geosGeom)
+			},
+			newGEOSFromGeom: func(geosContext *geos.Context, geomT geom.T) (*geos.Geom, error) {
+				return geobabel.NewGEOSGeomFromGeomPolygonE(geosContext, geomT.(*geom.Polygon))
+			},
+			newGEOSFromOrb: func(geosContext *geos.Context, orbGeometry orb.Geometry) (*geos.Geom, error) {
+				return geobabel.NewGEOSGeomFromOrbPolygonE(geosContext, orbGeometry.(orb.Polygon))
+			},
+		},
+		{
+			name:  "MultiPoint",
+			geomT: geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+			geosGeom: geosContext.NewCollection(geos.TypeIDMultiPoint, []*geos.Geom{
+				geosContext.NewPoint([]float64{1, 2}),
+				geosContext.NewPoint([]float64{3, 4}),
+			}),
+			orbGeometry: orb.MultiPoint{{1, 2}, {3, 4}},
+			newGeomFromOrb: func(orbGeometry orb.Geometry) geom.T {
+				return geobabel.NewGeomMultiPointFromOrbMultiPoint(orbGeometry.(orb.MultiPoint))
+			},
+			newGeomFromGEOS: func(geosGeom *geos.Geom) (geom.T, error) {
+				return geobabel.NewGeomMultiPointFromGEOSGeomE(geosGeom)
+			},
+			newOrbFromGeom: func(geomT geom.T) (orb.Geometry, error) {
+				return geobabel.NewOrbMultiPointFromGeomMultiPointE(geomT.(*geom.MultiPoint))
+			},
+			newOrbFromFlat: func(geomT geom.T) orb.Geometry {
+				return geobabel.NewOrbMultiPointFromGeomFlatCoords(geomT.FlatCoords(), geomT.Stride())
+			},
+			newOrbFromGEOS: func(geosGeom *geos.Geom) (orb.Geometry, error) {
+				return geobabel.NewOrbMultiPointFromGEOSGeomE(geosGeom)
+			},
+			newGEOSFromGeom: func(geosContext *geos.Context, geomT geom.T) (*geos.Geom, error) {
+				return geobabel.NewGEOSGeomFromGeomMultiPointE(geosContext, geomT.(*geom.MultiPoint))
+			},
+			newGEOSFromOrb: func(geosContext *geos.Context, orbGeometry orb.Geometry) (*geos.Geom, error) {
+				return geobabel.NewGEOSGeomFromOrbMultiPointE(geosContext, orbGeometry.(orb.MultiPoint))
+			},
+		},
+		{
+			name: "MultiLineString",
+			geomT: geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{
+				{{1, 2}, {3, 4}},
+				{{5, 6}, {7, 8}},
+			}),
+			geosGeom: geosContext.NewCollection(geos.TypeIDMultiLineString, []*geos.Geom{
+				geosContext.NewLineString([][]float64{{1, 2}, {3, 4}}),
+				geosContext.NewLineString([][]float64{{5, 6}, {7, 8}}),
+			}),
+			orbGeometry: orb.MultiLineString{
+				{{1, 2}, {3, 4}},
+				{{5, 6}, {7, 8}},
+			},
+			newGeomFromOrb: func(orbGeometry orb.Geometry) geom.T {
+				return geobabel.NewGeomMultiLineStringFromOrbMultiLineString(orbGeometry.(orb.MultiLineString))
+			},
+			newGeomFromGEOS: func(geosGeom *geos.Geom) (geom.T, error) {
+				return geobabel.NewGeomMultiLineStringFromGEOSGeomE(geosGeom)
+			},
+			newOrbFromGeom: func(geomT geom.T) (orb.Geometry, error) {
+				return geobabel.NewOrbMultiLineStringFromGeomMultiLineStringE(geomT.(*geom.MultiLineString))
+			},
+			newOrbFromFlat: func(geomT geom.T) orb.Geometry {
+				return geobabel.NewOrbMultiLineStringFromGeomFlatCoords(geomT.FlatCoords(), geomT.Ends(), geomT.Stride())
+			},
+			newOrbFromGEOS: func(geosGeom *geos.Geom) (orb.Geometry, error) {
+				return geobabel.NewOrbMultiLineStringFromGEOSGeomE(geosGeom)
+			},
+			newGEOSFromGeom: func(geosContext *geos.Context, geomT geom.T) (*geos.Geom, error) {
+				return geobabel.NewGEOSGeomFromGeomMultiLineStringE(geosContext, geomT.(*geom.MultiLineString))
+			},
+			newGEOSFromOrb: func(geosContext *geos.Context, orbGeometry orb.Geometry) (*geos.Geom, error) {
+				return geobabel.NewGEOSGeomFromOrbMultiLineStringE(geosContext, orbGeometry.(orb.MultiLineString))
+			},
+		},
+		{
+			name: "MultiPolygon",
+			geomT: geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+				{{{2, 1}, {3, 1}, {3, 2}, {2, 1}}},
+			}),
+			geosGeom: geosContext.NewCollection(geos.TypeIDMultiPolygon, []*geos.Geom{
+				geosContext.NewPolygon([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}),
+				geosContext.NewPolygon([][][]float64{{{2, 1}, {3, 1}, {3, 2}, {2, 1}}}),
+			}),
+			orbGeometry: orb.MultiPolygon{
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+				{{{2, 1}, {3, 1}, {3, 2}, {2, 1}}},
+			},
+			newGeomFromOrb: func(orbGeometry orb.Geometry) geom.T {
+				return geobabel.NewGeomMultiPolygonFromOrbMultiPolygon(orbGeometry.(orb.MultiPolygon))
+			},
+			newGeomFromGEOS: func(geosGeom *geos.Geom) (geom.T, error) {
+				return geobabel.NewGeomMultiPolygonFromGEOSGeomE(geosGeom)
+			},
+			newOrbFromGeom: func(geomT geom.T) (orb.Geometry, error) {
+				return geobabel.NewOrbMultiPolygonFromGeomMultiPolygonE(geomT.(*geom.MultiPolygon))
+			},
+			newOrbFromFlat: func(geomT geom.T) orb.Geometry {
+				return geobabel.NewOrbMultiPolygonFromGeomFlatCoords(geomT.FlatCoords(), geomT.Endss(), geomT.Stride())
+			},
+			newOrbFromGEOS: func(geosGeom *geos.Geom) (orb.Geometry, error) {
+				return geobabel.NewOrbMultiPolygonFromGEOSGeomE(geosGeom)
+			},
+			newGEOSFromGeom: func(geosContext *geos.Context, geomT geom.T) (*geos.Geom, error) {
+				return geobabel.NewGEOSGeomFromGeomMultiPolygonE(geosContext, geomT.(*geom.MultiPolygon))
+			},
+			newGEOSFromOrb: func(geosContext *geos.Context, orbGeometry orb.Geometry) (*geos.Geom, error) {
+				return geobabel.NewGEOSGeomFromOrbMultiPolygonE(geosContext, orbGeometry.(orb.MultiPolygon))
+			},
+		},
+		{
+			name: "GeometryCollection",
+			geomT: geom.NewGeometryCollection().MustPush(
+				geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+				geom.NewLinearRing(geom.XY).MustSetCoords([]geom.Coord{{1, 4}, {5, 2}, {3, 6}, {1, 4}}),
+			),
+			geosGeom: geosContext.NewCollection(geos.TypeIDGeometryCollection, []*geos.Geom{
+				geosContext.NewPoint([]float64{1, 2}),
+				geosContext.NewLinearRing([][]float64{{1, 4}, {5, 2}, {3, 6}, {1, 4}}),
+			}),
+			orbGeometry: orb.Collection{
+				orb.Point{1, 2},
+				orb.Ring{{1, 4}, {5, 2}, {3, 6}, {1, 4}},
+			},
+			newGeomFromOrb: func(orbGeometry orb.Geometry) geom.T {
+				return geobabel.NewGeomGeometryCollectionFromOrbCollection(orbGeometry.(orb.Collection))
+			},
+			newGeomFromGEOS: func(geosGeom *geos.Geom) (geom.T, error) {
+				return geobabel.NewGeomGeometryCollectionFromGEOSGeomE(geosGeom)
+			},
+			newOrbFromGeom: func(geomT geom.T) (orb.Geometry, error) {
+				return geobabel.NewOrbCollectionFromGeomGeometryCollectionE(geomT.(*geom.GeometryCollection))
+			},
+			newOrbFromGEOS: func(geosGeom *geos.Geom) (orb.Geometry, error) {
+				return geobabel.NewOrbCollectionFromGEOSGeomE(geosGeom)
+			},
+			newGEOSFromGeom: func(geosContext *geos.Context, geomT geom.T) (*geos.Geom, error) {
+				return geobabel.NewGEOSGeomFromGeomGeometryCollectionE(geosContext, geomT.(*geom.GeometryCollection))
+			},
+			newGEOSFromOrb: func(geosContext *geos.Context, orbGeometry orb.Geometry) (*geos.Geom, error) {
+				return geobabel.NewGEOSGeomFromOrbCollectionE(geosContext, orbGeometry.(orb.Collection))
+			},
+		},
+	}
+	for i, tc := range tests {
+		otherGEOSGeom := tests[(i+1)%len(tests)].geosGeom
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.geomT, tc.newGeomFromOrb(tc.orbGeometry))
+
+			geomT, err := tc.newGeomFromGEOS(tc.geosGeom)
+			require.NoError(t, err)
+			assert.Equal(t, tc.geomT, geomT)
+			_, err = tc.newGeomFromGEOS(otherGEOSGeom)
+			assert.ErrorIs(t, err, geobabel.ErrUnexpectedType)
+
+			orbGeometry, err := tc.newOrbFromGeom(tc.geomT)
+			require.NoError(t, err)
+			assert.Equal(t, tc.orbGeometry, orbGeometry)
+
+			if tc.newOrbFromFlat != nil {
+				assert.Equal(t, tc.orbGeometry, tc.newOrbFromFlat(tc.geomT))
+			}
+
+			orbGeometry, err = tc.newOrbFromGEOS(tc.geosGeom)
+			require.NoError(t, err)
+			assert.Equal(t, tc.orbGeometry, orbGeometry)
+			_, err = tc.newOrbFromGEOS(otherGEOSGeom)
+			assert.ErrorIs(t, err, geobabel.ErrUnexpectedType)
+
+			geosGeom, err := tc.newGEOSFromGeom(geosContext, tc.geomT)
+			require.NoError(t, err)
+			assert.True(t, tc.geosGeom.EqualsExact(geosGeom, 0))
+
+			geosGeom, err = tc.newGEOSFromOrb(geosContext, tc.orbGeometry)
+			require.NoError(t, err)
+			assert.True(t, tc.geosGeom.EqualsExact(geosGeom, 0))
+		})
+	}
+
+	geosPolygon := geosContext.NewPolygon([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}})
+	geomPolygon := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}})
+	assert.Panics(t, func() {
+		geobabel.NewGeomMultiPolygonFromGEOSGeom(geosPolygon)
+	})
+	geomMultiPolygon, err := geobabel.NewGeomMultiPolygonFromGEOSGeomE(geosPolygon, geobabel.WithPromoteToMulti(true))
+	require.NoError(t, err)
+	assert.Equal(t, geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{geomPolygon.Coords()}), geomMultiPolygon)
+	geomGeometryCollection, err := geobabel.NewGeomGeometryCollectionFromGEOSGeomE(geosPolygon, geobabel.WithPromoteToMulti(true))
+	require.NoError(t, err)
+	assert.Equal(t, geom.NewGeometryCollection().MustPush(geomPolygon), geomGeometryCollection)
+
+	_, err = geobabel.NewOrbPolygonFromGeomPolygonE(geom.NewPolygon(geom.XYZ), geobabel.WithZMPolicy(geobabel.ZMPolicyError))
+	assert.ErrorIs(t, err, geobabel.ErrUnsupportedLayout)
+	orbPolygon, err := geobabel.NewOrbPolygonFromGeomPolygonE(geomPolygon, geobabel.WithOrientation(geobabel.OrientationClockwise))
+	require.NoError(t, err)
+	assert.Equal(t, orb.Polygon{{{0, 0}, {1, 1}, {1, 0}, {0, 0}}}, orbPolygon)
+
+	geosGeom, err := geobabel.NewGEOSGeomFromGeomPointE(geosContext, geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}).SetSRID(4326))
+	require.NoError(t, err)
+	assert.Equal(t, 4326, geosGeom.SRID())
+	geosGeom, err = geobabel.NewGEOSGeomFromOrbBoundE(geosContext, orb.Bound{Min: orb.Point{0, 0}, Max: orb.Point{1, 1}})
+	require.NoError(t, err)
+	assert.Equal(t, geos.TypeIDPolygon, geosGeom.TypeID())
+}
+
+func TestAppend(t *testing.T) {
+	orbPolygon := orb.Polygon{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 0}},
+		{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+	}
+	geomPolygon := geobabel.NewGeomPolygonFromOrbPolygon(orbPolygon)
+	orbMultiPolygon := orb.MultiPolygon{orbPolygon, orbPolygon}
+	geomMultiPolygon := geobabel.NewGeomMultiPolygonFromOrbMultiPolygon(orbMultiPolygon)
+
+	geomFlatCoords, geomEnds := geobabel.AppendGeomFlatCoordsFromOrbPolygon([]float64{-1, -1}, []int{2}, orbPolygon)
+	assert.Equal(t, append([]float64{-1, -1}, geomPolygon.FlatCoords()...), geomFlatCoords)
+	assert.Equal(t, []int{2, 10, 18}, geomEnds)
+
+	geomFlatCoords, geomEndss := geobabel.AppendGeomFlatCoordsFromOrbMultiPolygon(nil, nil, orbMultiPolygon)
+	assert.Equal(t, geomMultiPolygon.FlatCoords(), geomFlatCoords)
+	assert.Equal(t, geomMultiPolygon.Endss(), geomEndss)
+
+	actualOrbPolygon := geobabel.AppendOrbPolygonFromGeomFlatCoords(nil, geomPolygon.FlatCoords(), geomPolygon.Ends(), geomPolygon.Stride())
+	assert.Equal(t, orbPolygon, actualOrbPolygon)
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		actualOrbPolygon = geobabel.AppendOrbPolygonFromGeomFlatCoords(actualOrbPolygon[:0], geomPolygon.FlatCoords(), geomPolygon.Ends(), geomPolygon.Stride())
+	}))
+	assert.Equal(t, orbPolygon, actualOrbPolygon)
+
+	actualOrbMultiPolygon := geobabel.AppendOrbMultiPolygonFromGeomFlatCoords(nil, geomMultiPolygon.FlatCoords(), geomMultiPolygon.Endss(), geomMultiPolygon.Stride())
+	assert.Equal(t, orbMultiPolygon, actualOrbMultiPolygon)
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		actualOrbMultiPolygon = geobabel.AppendOrbMultiPolygonFromGeomFlatCoords(actualOrbMultiPolygon[:0], geomMultiPolygon.FlatCoords(), geomMultiPolygon.Endss(), geomMultiPolygon.Stride())
+	}))
+	assert.Equal(t, orbMultiPolygon, actualOrbMultiPolygon)
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		geomFlatCoords, geomEndss = geobabel.AppendGeomFlatCoordsFromOrbMultiPolygon(geomFlatCoords[:0], geomEndss[:0], orbMultiPolygon)
+	}))
+	assert.Equal(t, geomMultiPolygon.Endss(), geomEndss)
+}
+
+func TestNewGEOSGeomsFromOrbGeometries(t *testing.T) {
+	geosContext := geos.NewContext()
+	orbGeometries := []orb.Geometry{
+		orb.Point{1, 2},
+		orb.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}},
+		orb.Polygon{
+			{{0, 0}, {4, 0}, {4, 4}, {0, 0}},
+			{},
+			{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+		},
+		orb.MultiPoint{{1, 2}, {math.NaN(), math.NaN()}},
+		orb.Collection{
+			orb.LineString{{0, 0}, {1, 1}},
+			orb.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}},
+		},
+		orb.MultiPolygon{{}},
+	}
+	for _, newGEOSGeomsFromOrbGeometries := range []func(*geos.Context, []orb.Geometry) ([]*geos.Geom, error){
+		func(geosContext *geos.Context, orbGeometries []orb.Geometry) ([]*geos.Geom, error) {
+			return geobabel.NewGEOSGeomsFromOrbGeometriesE(geosContext, orbGeometries)
+		},
+		geobabel.NewConverter().NewGEOSGeomsFromOrbGeometriesE,
+	} {
+		geosGeoms, err := newGEOSGeomsFromOrbGeometries(geosContext, orbGeometries)
+		require.NoError(t, err)
+		require.Len(t, geosGeoms, len(orbGeometries))
+		for i, orbGeometry := range orbGeometries {
+			assert.Equal(t, geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbGeometry).TypeID(), geosGeoms[i].TypeID())
+			assertOrbGeometryEqual(t, geobabel.NewOrbGeometryFromGEOSGeom(geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbGeometry)), geobabel.NewOrbGeometryFromGEOSGeom(geosGeoms[i]))
+		}
+	}
+
+	geosGeoms := geobabel.NewGEOSGeomsFromOrbGeometries(geosContext, []orb.Geometry{
+		orb.Collection{
+			orb.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}},
+			orb.Point{5, 5},
+		},
+		orb.Polygon{{{0, 0}, {4, 0}, {4, 4}, {0, 0}}},
+		orb.Point{1, 2},
+	})
+	require.Len(t, geosGeoms, 3)
+	assert.Equal(t, geos.TypeIDGeometryCollection, geosGeoms[0].TypeID())
+	assertOrbGeometryEqual(t, orb.Polygon{{{0, 0}, {4, 0}, {4, 4}, {0, 0}}}, geobabel.NewOrbGeometryFromGEOSGeom(geosGeoms[1]))
+	assertOrbGeometryEqual(t, orb.Point{1, 2}, geobabel.NewOrbGeometryFromGEOSGeom(geosGeoms[2]))
+
+	invalidOrbGeometries := []orb.Geometry{
+		orb.Point{1, 2},
+		orb.Collection{orb.Point{1, 2}, customOrbGeometry{}},
+	}
+	_, err := geobabel.NewGEOSGeomsFromOrbGeometriesE(geosContext, invalidOrbGeometries)
+	assert.EqualError(t, err, "[1][1]: geobabel_test.customOrbGeometry: unsupported type")
+	_, err = geobabel.NewConverter().NewGEOSGeomsFromOrbGeometriesE(geosContext, invalidOrbGeometries)
+	assert.EqualError(t, err, "[1][1]: geobabel_test.customOrbGeometry: unsupported type")
+	assert.Panics(t, func() {
+		geobabel.NewGEOSGeomsFromOrbGeometries(geosContext, invalidOrbGeometries)
+	})
+	assert.Panics(t, func() {
+		geobabel.NewConverter().NewGEOSGeomsFromOrbGeometries(geosContext, invalidOrbGeometries)
+	})
+}
+
+func TestConverter(t *testing.T) {
+	geosContext := geos.NewContext()
+	converter := geobabel.NewConverter(geobabel.WithZMPolicy(geobabel.ZMPolicyKeep))
+	for _, geomT := range []geom.T{
+		geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+			{{0, 0}, {4, 0}, {4, 4}, {0, 0}},
+			{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+		}),
+		geom.NewLineString(geom.XYM).MustSetCoords([]geom.Coord{{1, 2, 3}, {4, 5, 6}}),
+		geom.NewGeometryCollection().MustPush(
+			geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}),
+			geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, nil}),
+		),
+	} {
+		expected := geobabel.NewGEOSGeomFromGeomT(geosContext, geomT, geobabel.WithZMPolicy(geobabel.ZMPolicyKeep))
+		geosGeom, err := converter.NewGEOSGeomFromGeomTE(geosContext, geomT)
+		require.NoError(t, err)
+		assert.True(t, expected.EqualsExact(geosGeom, 0))
+
+		orbGeometry := geobabel.NewOrbGeometryFromGeomT(geomT)
+		expected = geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbGeometry)
+		geosGeom, err = converter.NewGEOSGeomFromOrbGeometryE(geosContext, orbGeometry)
+		require.NoError(t, err)
+		assert.True(t, expected.EqualsExact(geosGeom, 0))
+	}
+}
+
+func TestTransform(t *testing.T) {
+	geosContext := geos.NewContext()
+	transform := geobabel.WithTransform(func(x, y float64) (float64, float64) {
+		return x + 10, 2 * y
+	})
+	newGeomT := func(transform func(geom.Coord) geom.Coord) geom.T {
+		return geom.NewGeometryCollection().MustPush(
+			geom.NewPoint(geom.XY).MustSetCoords(transform(geom.Coord{1, 2})),
+			geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{transform(geom.Coord{1, 2}), transform(geom.Coord{3, 4})}),
+			geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+				{transform(geom.Coord{0, 0}), transform(geom.Coord{4, 0}), transform(geom.Coord{4, 4}), transform(geom.Coord{0, 0})},
+				{transform(geom.Coord{1, 1}), transform(geom.Coord{2, 1}), transform(geom.Coord{2, 2}), transform(geom.Coord{1, 1})},
+			}),
+			geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{transform(geom.Coord{1, 2}), nil}),
+			geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{{transform(geom.Coord{1, 2}), transform(geom.Coord{3, 4})}}),
+			geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{
+				{{transform(geom.Coord{0, 0}), transform(geom.Coord{1, 0}), transform(geom.Coord{1, 1}), transform(geom.Coord{0, 0})}},
+			}),
+		)
+	}
+	geomT := newGeomT(func(coord geom.Coord) geom.Coord { return coord })
+	expectedGeomT := newGeomT(func(coord geom.Coord) geom.Coord { return geom.Coord{coord[0] + 10, 2 * coord[1]} })
+	orbGeometry := geobabel.NewOrbGeometryFromGeomT(geomT)
+	expectedOrbGeometry := geobabel.NewOrbGeometryFromGeomT(expectedGeomT)
+	geosGeom := geobabel.NewGEOSGeomFromGeomT(geosContext, geomT)
+	expectedGEOSGeom := geobabel.NewGEOSGeomFromGeomT(geosContext, expectedGeomT)
+
+	assertOrbGeometryEqual(t, expectedOrbGeometry, geobabel.NewOrbGeometryFromGeomT(geomT, transform))
+	assertOrbGeometryEqual(t, expectedOrbGeometry, geobabel.NewOrbGeometryFromGEOSGeom(geosGeom, transform))
+	assert.Equal(t, expectedGeomT, geobabel.NewGeomTFromOrbGeometry(orbGeometry, transform))
+	assert.Equal(t, expectedGeomT, geobabel.NewGeomTFromGEOSGeom(geosGeom, transform))
+	assert.True(t, expectedGEOSGeom.EqualsExact(geobabel.NewGEOSGeomFromGeomT(geosContext, geomT, transform), 0))
+	assert.True(t, expectedGEOSGeom.EqualsExact(geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbGeometry, transform), 0))
+
+	orbRing := orb.Ring{{0, 0}, {1, 0}, {1, 1}, {0, 0}}
+	expectedOrbRing := orb.Ring{{10, 0}, {11, 0}, {11, 2}, {10, 0}}
+	assert.Equal(t, expectedOrbRing, geobabel.NewOrbRingFromGEOSGeom(geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbRing, transform)))
+	assert.Equal(t, expectedOrbRing, geobabel.NewOrbRingFromGEOSGeom(geosContext.NewLinearRing([][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}), transform))
+
+	// Z ordinates are preserved and the source geometry is not modified.
+	geomLineString := geom.NewLineString(geom.XYZ).MustSetCoords([]geom.Coord{{1, 2, 3}, {4, 5, 6}})
+	expectedGeomLineString := geom.NewLineString(geom.XYZ).MustSetCoords([]geom.Coord{{11, 4, 3}, {14, 10, 6}})
+	assert.Equal(t, expectedGeomLineString, geobabel.NewGeomTFromGEOSGeom(geobabel.NewGEOSGeomFromGeomT(geosContext, geomLineString, transform)))
+	assert.Equal(t, []float64{1, 2, 3, 4, 5, 6}, geomLineString.FlatCoords())
+
+	wkt, err := geobabel.WKTFromGeomT(geomLineString, transform)
+	require.NoError(t, err)
+	assert.Equal(t, "LINESTRING Z (11 4 3, 14 10 6)", wkt)
+	assert.Equal(t, []float64{1, 2, 3, 4, 5, 6}, geomLineString.FlatCoords())
+}
+
+func TestProjection(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		fromSRID, toSRID int
+		x, y             float64
+		expectedX        float64
+		expectedY        float64
+		delta            float64
+	}{
+		{
+			name:      "web_mercator_origin",
+			fromSRID:  geobabel.SRIDWGS84,
+			toSRID:    geobabel.SRIDWebMercator,
+			expectedX: 0,
+			expectedY: 0,
+		},
+		{
+			name:      "web_mercator_antimeridian",
+			fromSRID:  geobabel.SRIDWGS84,
+			toSRID:    geobabel.SRIDWebMercator,
+			x:         180,
+			expectedX: 20037508.342789244,
+			expectedY: 0,
+			delta:     1e-6,
+		},
+		{
+			name:      "utm_central_meridian",
+			fromSRID:  geobabel.SRIDWGS84,
+			toSRID:    32631,
+			x:         3,
+			expectedX: 500000,
+			expectedY: 0,
+			delta:     1e-6,
+		},
+		{
+			name:      "utm_zone_edge",
+			fromSRID:  geobabel.SRIDWGS84,
+			toSRID:    32631,
+			expectedX: 166021.443,
+			expectedY: 0,
+			delta:     1e-3,
+		},
+		{
+			name:      "utm_meridian_arc",
+			fromSRID:  geobabel.SRIDWGS84,
+			toSRID:    32631,
+			x:         3,
+			y:         45,
+			expectedX: 500000,
+			expectedY: 0.9996 * 4984944.378,
+			delta:     1e-3,
+		},
+		{
+			name:      "utm_south",
+			fromSRID:  geobabel.SRIDWGS84,
+			toSRID:    32731,
+			x:         3,
+			expectedX: 500000,
+			expectedY: 10000000,
+			delta:     1e-6,
+		},
+		{
+			name:      "identity",
+			fromSRID:  32633,
+			toSRID:    32633,
+			x:         123456.789,
+			y:         987654.321,
+			expectedX: 123456.789,
+			expectedY: 987654.321,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			projection, err := geobabel.NewProjection(tc.fromSRID, tc.toSRID)
+			require.NoError(t, err)
+			assert.Equal(t, tc.fromSRID, projection.FromSRID())
+			assert.Equal(t, tc.toSRID, projection.ToSRID())
+			x, y := projection.Transform(tc.x, tc.y)
+			assert.InDelta(t, tc.expectedX, x, tc.delta)
+			assert.InDelta(t, tc.expectedY, y, tc.delta)
+		})
+	}
+
+	t.Run("round_trip", func(t *testing.T) {
+		for _, srid := range []int{geobabel.SRIDWebMercator, 32633, 32733} {
+			forward, err := geobabel.NewProjection(geobabel.SRIDWGS84, srid)
+			require.NoError(t, err)
+			inverse, err := geobabel.NewProjection(srid, geobabel.SRIDWGS84)
+			require.NoError(t, err)
+			for _, lonLat := range [][2]float64{{15, 0}, {12, 45}, {17.5, -33}, {9.5, 80}} {
+				x, y := forward.Transform(lonLat[0], lonLat[1])
+				lon, lat := inverse.Transform(x, y)
+				assert.InDelta(t, lonLat[0], lon, 1e-9)
+				assert.InDelta(t, lonLat[1], lat, 1e-9)
+			}
+		}
+	})
+
+	t.Run("orb", func(t *testing.T) {
+		projection, err := geobabel.NewProjection(geobabel.SRIDWGS84, geobabel.SRIDWebMercator)
+		require.NoError(t, err)
+		for _, orbPoint := range []orb.Point{{-122.4, 37.8}, {151.2, -33.9}, {0, 89.9}} {
+			x, y := projection.Transform(orbPoint[0], orbPoint[1])
+			expected := project.WGS84.ToMercator(orbPoint)
+			assert.InDelta(t, expected[0], x, 1e-6)
+			assert.InDelta(t, expected[1], y, 1e-6)
+		}
+	})
+
+	t.Run("utm_srid", func(t *testing.T) {
+		srid, err := geobabel.UTMSRID(31, true)
+		require.NoError(t, err)
+		assert.Equal(t, 32631, srid)
+		srid, err = geobabel.UTMSRID(60, false)
+		require.NoError(t, err)
+		assert.Equal(t, 32760, srid)
+		_, err = geobabel.UTMSRID(61, true)
+		assert.Error(t, err)
+		assert.Equal(t, 32617, geobabel.UTMSRIDFromLonLat(-79.4, 43.6))
+		assert.Equal(t, 32731, geobabel.UTMSRIDFromLonLat(3, -10))
+		assert.Equal(t, 32601, geobabel.UTMSRIDFromLonLat(-180, 0))
+	})
+
+	t.Run("unsupported_srid", func(t *testing.T) {
+		_, err := geobabel.NewProjection(geobabel.SRIDWGS84, 27700)
+		assert.ErrorIs(t, err, geobabel.ErrUnsupportedSRID)
+		_, err = geobabel.NewProjection(32600, geobabel.SRIDWGS84)
+		assert.ErrorIs(t, err, geobabel.ErrUnsupportedSRID)
+	})
+
+	t.Run("conversions", func(t *testing.T) {
+		geosContext := geos.NewContext()
+		projection, err := geobabel.NewProjection(geobabel.SRIDWGS84, geobabel.SRIDWebMercator)
+		require.NoError(t, err)
+		options := []geobabel.Option{geobabel.WithProjection(projection)}
+		orbPoint := orb.Point{-122.4, 37.8}
+		expectedX, expectedY := projection.Transform(orbPoint[0], orbPoint[1])
+		expectedGeomT := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{expectedX, expectedY}).SetSRID(geobabel.SRIDWebMercator)
+
+		assert.Equal(t, expectedGeomT, geobabel.NewGeomTFromOrbGeometry(orbPoint, options...))
+		assert.Equal(t, expectedGeomT, geobabel.NewGeomPointFromOrbPoint(orbPoint, options...))
+
+		geosGeom := geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbPoint, options...)
+		assert.Equal(t, geobabel.SRIDWebMercator, geosGeom.SRID())
+		assert.Equal(t, expectedGeomT, geobabel.NewGeomTFromGEOSGeom(geosGeom))
+
+		geomT := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{orbPoint[0], orbPoint[1]}).SetSRID(geobabel.SRIDWGS84)
+		geosGeom = geobabel.NewGEOSGeomFromGeomT(geosContext, geomT, options...)
+		assert.Equal(t, geobabel.SRIDWebMercator, geosGeom.SRID())
+		assert.Equal(t, expectedGeomT, geobabel.NewGeomTFromGEOSGeom(geosGeom))
+
+		geosGeom = geosContext.NewPoint([]float64{orbPoint[0], orbPoint[1]})
+		assert.Equal(t, expectedGeomT, geobabel.NewGeomTFromGEOSGeom(geosGeom, options...))
+		assert.Equal(t, orb.Point{expectedX, expectedY}, geobabel.NewOrbGeometryFromGEOSGeom(geosGeom, options...))
+
+		ewkb, err := geobabel.EWKBFromOrbGeometryE(orbPoint, geobabel.SRIDWGS84, options...)
+		require.NoError(t, err)
+		actualGeomT, err := geobabel.NewGeomTFromEWKB(ewkb)
+		require.NoError(t, err)
+		assert.Equal(t, expectedGeomT, actualGeomT)
+		ewkb, err = geobabel.EWKBFromGeomT(geomT, options...)
+		require.NoError(t, err)
+		actualGeomT, err = geobabel.NewGeomTFromEWKB(ewkb)
+		require.NoError(t, err)
+		assert.Equal(t, expectedGeomT, actualGeomT)
+	})
+
+	t.Run("replaced_by_transform", func(t *testing.T) {
+		geosContext := geos.NewContext()
+		projection, err := geobabel.NewProjection(geobabel.SRIDWGS84, geobabel.SRIDWebMercator)
+		require.NoError(t, err)
+		options := []geobabel.Option{
+			geobabel.WithProjection(projection),
+			geobabel.WithTransform(func(x, y float64) (float64, float64) {
+				return 2 * x, 2 * y
+			}),
+		}
+		geomT := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}).SetSRID(geobabel.SRIDWGS84)
+		expectedGeomT := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{2, 4}).SetSRID(geobabel.SRIDWGS84)
+		assert.Equal(t, expectedGeomT, geobabel.NewGeomTFromGEOSGeom(geobabel.NewGEOSGeomFromGeomT(geosContext, geomT, options...)))
+		assert.Equal(t, expectedGeomT.SetSRID(0), geobabel.NewGeomTFromOrbGeometry(orb.Point{1, 2}, options...))
+	})
+}
+
+func TestBounds(t *testing.T) {
+	geosContext := geos.NewContext()
+	orbBound := orb.Bound{Min: orb.Point{1, 2}, Max: orb.Point{3, 4}}
+	geomBounds := geom.NewBounds(geom.XY).Set(1, 2, 3, 4)
+
+	assert.Equal(t, geomBounds, geobabel.NewGeomBoundsFromOrbBound(orbBound))
+	assert.Equal(t, orbBound, geobabel.NewOrbBoundFromGeomBounds(geomBounds))
+
+	geosGeom := geobabel.NewGEOSGeomFromOrbBound(geosContext, orbBound)
+	assert.Equal(t, geos.TypeIDPolygon, geosGeom.TypeID())
+	assert.True(t, geosContext.NewPolygon([][][]float64{{{1, 2}, {3, 2}, {3, 4}, {1, 4}, {1, 2}}}).EqualsExact(geosGeom, 0))
+	assert.Equal(t, orbBound, geobabel.NewOrbBoundFromGEOSGeom(geosGeom))
+	assert.Equal(t, orbBound, geobabel.NewOrbBoundFromGEOSGeom(geosContext.NewLineString([][]float64{{1, 4}, {2, 3}, {3, 2}})))
+
+	geosGeom = geobabel.NewGEOSGeomFromOrbBound(geosContext, orb.Bound{Min: orb.Point{1, 2}, Max: orb.Point{1, 2}})
+	assert.Equal(t, geos.TypeIDPolygon, geosGeom.TypeID())
+
+	t.Run("empty", func(t *testing.T) {
+		emptyOrbBound := orb.MultiPoint{}.Bound()
+		assert.True(t, geobabel.NewGeomBoundsFromOrbBound(emptyOrbBound).IsEmpty())
+		assert.Equal(t, emptyOrbBound, geobabel.NewOrbBoundFromGeomBounds(geom.NewBounds(geom.XY)))
+		assert.Equal(t, emptyOrbBound, geobabel.NewOrbBoundFromGeomBounds(geom.NewBounds(geom.NoLayout)))
+		geosGeom := geobabel.NewGEOSGeomFromOrbBound(geosContext, emptyOrbBound)
+		assert.Equal(t, geos.TypeIDPolygon, geosGeom.TypeID())
+		assert.True(t, geosGeom.IsEmpty())
+		assert.Equal(t, emptyOrbBound, geobabel.NewOrbBoundFromGEOSGeom(geosGeom))
+		assert.Equal(t, geom.NewPolygon(geom.XY), geobabel.NewGeomTFromOrbGeometry(emptyOrbBound))
+	})
+
+	t.Run("zm", func(t *testing.T) {
+		geomBounds := geom.NewBounds(geom.XYZ).Set(1, 2, 3, 4, 5, 6)
+		assert.Equal(t, orb.Bound{Min: orb.Point{1, 2}, Max: orb.Point{4, 5}}, geobabel.NewOrbBoundFromGeomBounds(geomBounds))
+		_, err := geobabel.NewOrbBoundFromGeomBoundsE(geomBounds, geobabel.WithZMPolicy(geobabel.ZMPolicyError))
+		assert.ErrorIs(t, err, geobabel.ErrUnsupportedLayout)
+		assert.EqualError(t, err, "*geom.Bounds: XYZ: unsupported layout")
+	})
+
+	t.Run("projection", func(t *testing.T) {
+		projection, err := geobabel.NewProjection(geobabel.SRIDWGS84, geobabel.SRIDWebMercator)
+		require.NoError(t, err)
+		geosGeom := geobabel.NewGEOSGeomFromOrbBound(geosContext, orbBound, geobabel.WithProjection(projection))
+		assert.Equal(t, geobabel.SRIDWebMercator, geosGeom.SRID())
+		minX, minY := projection.Transform(1, 2)
+		maxX, maxY := projection.Transform(3, 4)
+		assert.Equal(t, orb.Bound{Min: orb.Point{minX, minY}, Max: orb.Point{maxX, maxY}}, geobabel.NewOrbBoundFromGEOSGeom(geosGeom))
+	})
+}
+
+func TestOrientation(t *testing.T) {
+	geosContext := geos.NewContext()
+	decodeWKB := func(wkb []byte) (orb.Geometry, error) {
+		return geobabel.NewOrbGeometryFromWKB(wkb)
+	}
+	decodeEWKB := func(ewkb []byte) (orb.Geometry, error) {
+		orbGeometry, _, err := geobabel.NewOrbGeometryFromEWKB(ewkb)
+		return orbGeometry, err
+	}
+	decodeTWKB := func(twkb []byte) (orb.Geometry, error) {
+		return geobabel.NewOrbGeometryFromTWKB(twkb)
+	}
+	clockwiseOrbPolygon := orb.Polygon{
+		{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {0, 0}},
+		{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+	}
+	counterclockwiseOrbPolygon := orb.Polygon{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {2, 2}, {2, 1}, {1, 1}},
+	}
+	for _, tc := range []struct {
+		name               string
+		orientation        geobabel.Orientation
+		orbPolygon         orb.Polygon
+		expectedOrbPolygon orb.Polygon
+	}{
+		{"ClockwiseToClockwise", geobabel.OrientationClockwise, clockwiseOrbPolygon, clockwiseOrbPolygon},
+		{"CounterclockwiseToClockwise", geobabel.OrientationClockwise, counterclockwiseOrbPolygon, clockwiseOrbPolygon},
+		{"ClockwiseToCounterclockwise", geobabel.OrientationCounterclockwise, clockwiseOrbPolygon, counterclockwiseOrbPolygon},
+		{"CounterclockwiseToCounterclockwise", geobabel.OrientationCounterclockwise, counterclockwiseOrbPolygon, counterclockwiseOrbPolygon},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			orbPolygon, expectedOrbPolygon := tc.orbPolygon.Clone(), tc.expectedOrbPolygon
+			option := geobabel.WithOrientation(tc.orientation)
+			expectedGeomPolygon := geobabel.NewGeomPolygonFromOrbPolygon(expectedOrbPolygon)
+			expectedGEOSGeom := geobabel.NewGEOSGeomFromOrbGeometry(geosContext, expectedOrbPolygon)
+
+			assert.Equal(t, expectedGeomPolygon, geobabel.NewGeomPolygonFromOrbPolygon(orbPolygon, option))
+			assert.Equal(t, expectedGeomPolygon, geobabel.NewGeomTFromOrbGeometry(orb.MultiPolygon{orbPolygon}, option).(*geom.MultiPolygon).Polygon(0))
+			assert.True(t, expectedGEOSGeom.EqualsExact(geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbPolygon, option), 0))
+
+			geomPolygon := geobabel.NewGeomPolygonFromOrbPolygon(orbPolygon)
+			assert.Equal(t, expectedOrbPolygon, geobabel.NewOrbGeometryFromGeomT(geomPolygon, option))
+			assert.Equal(t, orb.MultiPolygon{expectedOrbPolygon}, geobabel.NewOrbGeometryFromGeomT(geobabel.NewGeomTFromOrbGeometry(orb.MultiPolygon{orbPolygon}), option))
+			assert.True(t, expectedGEOSGeom.EqualsExact(geobabel.NewGEOSGeomFromGeomT(geosContext, geomPolygon, option), 0))
+			assert.Equal(t, orbPolygon, geobabel.NewOrbGeometryFromGeomT(geomPolygon), "source modified")
+
+			geosGeom := geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbPolygon)
+			assert.Equal(t, expectedOrbPolygon, geobabel.NewOrbGeometryFromGEOSGeom(geosGeom, option))
+			assert.Equal(t, expectedGeomPolygon, geobabel.NewGeomTFromGEOSGeom(geosGeom, option))
+
+			expectedWKT, err := geobabel.WKTFromOrbGeometry(expectedOrbPolygon)
+			require.NoError(t, err)
+			for _, wktFunc := range []func() (string, error){
+				func() (string, error) { return geobabel.WKTFromGEOSGeom(geosGeom, option) },
+				func() (string, error) { return geobabel.WKTFromGeomT(geomPolygon, option) },
+				func() (string, error) { return geobabel.WKTFromOrbGeometry(orbPolygon, option) },
+				func() (string, error) { return geobabel.EWKTFromGEOSGeom(geosGeom, option) },
+				func() (string, error) { return geobabel.EWKTFromGeomT(geomPolygon, option) },
+				func() (string, error) { return geobabel.EWKTFromOrbGeometry(orbPolygon, 0, option) },
+			} {
+				wkt, err := wktFunc()
+				require.NoError(t, err)
+				assert.Equal(t, expectedWKT, wkt)
+			}
+
+			for _, tc := range []struct {
+				name   string
+				encode func() ([]byte, error)
+				decode func([]byte) (orb.Geometry, error)
+			}{
+				{"WKBFromGEOSGeom", func() ([]byte, error) { return geobabel.WKBFromGEOSGeomE(geosGeom, option) }, decodeWKB},
+				{"WKBFromGeomT", func() ([]byte, error) { return geobabel.WKBFromGeomT(geomPolygon, option) }, decodeWKB},
+				{"WKBFromOrbGeometry", func() ([]byte, error) { return geobabel.WKBFromOrbGeometryE(orbPolygon, option) }, decodeWKB},
+				{"EWKBFromGEOSGeom", func() ([]byte, error) { return geobabel.EWKBFromGEOSGeom(geosGeom, option) }, decodeEWKB},
+				{"EWKBFromGeomT", func() ([]byte, error) { return geobabel.EWKBFromGeomT(geomPolygon, option) }, decodeEWKB},
+				{"EWKBFromOrbGeometry", func() ([]byte, error) { return geobabel.EWKBFromOrbGeometry(orbPolygon, 0, option), nil }, decodeEWKB},
+				{"TWKBFromGEOSGeom", func() ([]byte, error) { return geobabel.TWKBFromGEOSGeom(geosGeom, option) }, decodeTWKB},
+				{"TWKBFromGeomT", func() ([]byte, error) { return geobabel.TWKBFromGeomT(geomPolygon, option) }, decodeTWKB},
+				{"TWKBFromOrbGeometry", func() ([]byte, error) { return geobabel.TWKBFromOrbGeometry(orbPolygon, option) }, decodeTWKB},
+			} {
+				data, err := tc.encode()
+				require.NoError(t, err, tc.name)
+				actualOrbGeometry, err := tc.decode(data)
+				require.NoError(t, err, tc.name)
+				assert.Equal(t, expectedOrbPolygon, actualOrbGeometry, tc.name)
+			}
+		})
+	}
+
+	t.Run("keep", func(t *testing.T) {
+		assert.Equal(t, clockwiseOrbPolygon, geobabel.NewOrbGeometryFromGeomT(geobabel.NewGeomPolygonFromOrbPolygon(clockwiseOrbPolygon)))
+	})
+
+	t.Run("transform", func(t *testing.T) {
+		flipY := geobabel.WithTransform(func(x, y float64) (float64, float64) {
+			return x, -y
+		})
+		assert.Equal(t,
+			orb.Polygon{
+				{{0, 0}, {4, 0}, {4, -4}, {0, -4}, {0, 0}},
+				{{1, -1}, {2, -2}, {2, -1}, {1, -1}},
+			},
+			geobabel.NewOrbGeometryFromGeomT(geobabel.NewGeomPolygonFromOrbPolygon(counterclockwiseOrbPolygon), flipY, geobabel.WithOrientation(geobabel.OrientationClockwise)),
+		)
+	})
+}
+
+func TestPrecision(t *testing.T) {
+	geosContext := geos.NewContext()
+	precision := geobabel.WithPrecision(3)
+	orbLineString := orb.LineString{{0.1234, 1.0001}, {0.1231, 1.0004}, {1.5, 2.5}}
+	expectedOrbLineString := orb.LineString{{0.123, 1}, {1.5, 2.5}}
+	expectedGeomLineString := geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{0.123, 1}, {1.5, 2.5}})
+	geomLineString := geobabel.NewGeomLineStringFromOrbLineString(orbLineString)
+	geosLineString := geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbLineString)
+
+	assert.Equal(t, expectedGeomLineString, geobabel.NewGeomTFromOrbGeometry(orbLineString, precision))
+	assert.Equal(t, expectedOrbLineString, geobabel.NewOrbGeometryFromGeomT(geomLineString, precision))
+	assert.Equal(t, expectedOrbLineString, geobabel.NewOrbGeometryFromGEOSGeom(geosLineString, precision))
+	assert.Equal(t, expectedGeomLineString, geobabel.NewGeomTFromGEOSGeom(geosLineString, precision))
+	expectedWKB := geobabel.WKBFromOrbGeometry(expectedOrbLineString)
+	assert.Equal(t, expectedWKB, geobabel.WKBFromGEOSGeom(geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbLineString, precision)))
+	assert.Equal(t, expectedWKB, geobabel.WKBFromGEOSGeom(geobabel.NewGEOSGeomFromGeomT(geosContext, geomLineString, precision)))
+	assert.Equal(t, expectedWKB, geobabel.WKBFromGEOSGeom(geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbLineString, precision, geobabel.WithGEOSPrecision(true))))
+	expectedWKT, err := geobabel.WKTFromOrbGeometry(expectedOrbLineString)
+	require.NoError(t, err)
+	for _, wktFunc := range []func() (string, error){
+		func() (string, error) { return geobabel.WKTFromGEOSGeom(geosLineString, precision) },
+		func() (string, error) { return geobabel.WKTFromGeomT(geomLineString, precision) },
+		func() (string, error) { return geobabel.WKTFromOrbGeometry(orbLineString, precision) },
+		func() (string, error) { return geobabel.EWKTFromGEOSGeom(geosLineString, precision) },
+		func() (string, error) { return geobabel.EWKTFromGeomT(geomLineString, precision) },
+		func() (string, error) { return geobabel.EWKTFromOrbGeometry(orbLineString, 0, precision) },
+	} {
+		wkt, err := wktFunc()
+		require.NoError(t, err)
+		assert.Equal(t, expectedWKT, wkt)
+	}
+	for _, wkbFunc := range []func() ([]byte, error){
+		func() ([]byte, error) { return geobabel.WKBFromGeomT(geomLineString, precision) },
+		func() ([]byte, error) { return geobabel.WKBFromGEOSGeomE(geosLineString, precision) },
+		func() ([]byte, error) { return geobabel.WKBFromOrbGeometryE(orbLineString, precision) },
+	} {
+		wkb, err := wkbFunc()
+		require.NoError(t, err)
+		assert.Equal(t, expectedWKB, wkb)
+	}
+	expectedEWKB, err := geobabel.EWKBFromGeomT(expectedGeomLineString)
+	require.NoError(t, err)
+	ewkb, err := geobabel.EWKBFromGeomT(geomLineString, precision)
+	require.NoError(t, err)
+	assert.Equal(t, expectedEWKB, ewkb)
+	assert.Equal(t, expectedEWKB, geobabel.EWKBFromOrbGeometry(orbLineString, 0, precision))
+	hexWKB, err := geobabel.HexWKBFromGeomT(geomLineString, precision)
+	require.NoError(t, err)
+	assert.Equal(t, geobabel.HexWKBFromOrbGeometry(expectedOrbLineString), hexWKB)
+	hexEWKB, err := geobabel.HexEWKBFromGeomT(geomLineString, precision)
+	require.NoError(t, err)
+	assert.Equal(t, geobabel.HexEWKBFromOrbGeometry(expectedOrbLineString, 0), hexEWKB)
+	expectedGeoJSON, err := geobabel.GeoJSONFromGeomT(expectedGeomLineString)
+	require.NoError(t, err)
+	twkbPrecision := geobabel.WithTWKBPrecision(4, 0, 0)
+	expectedTWKB, err := geobabel.TWKBFromGeomT(expectedGeomLineString, twkbPrecision)
+	require.NoError(t, err)
+	for _, tc := range []struct {
+		name     string
+		expected []byte
+		f        func() ([]byte, error)
+	}{
+		{"GeoJSONFromGeomT", expectedGeoJSON, func() ([]byte, error) { return geobabel.GeoJSONFromGeomT(geomLineString, precision) }},
+		{"GeoJSONFromGEOSGeom", expectedGeoJSON, func() ([]byte, error) { return geobabel.GeoJSONFromGEOSGeom(geosLineString, precision) }},
+		{"GeoJSONFromOrbGeometry", expectedGeoJSON, func() ([]byte, error) { return geobabel.GeoJSONFromOrbGeometry(orbLineString, precision) }},
+		{"TWKBFromGeomT", expectedTWKB, func() ([]byte, error) { return geobabel.TWKBFromGeomT(geomLineString, precision, twkbPrecision) }},
+		{"TWKBFromGEOSGeom", expectedTWKB, func() ([]byte, error) { return geobabel.TWKBFromGEOSGeom(geosLineString, precision, twkbPrecision) }},
+		{"TWKBFromOrbGeometry", expectedTWKB, func() ([]byte, error) { return geobabel.TWKBFromOrbGeometry(orbLineString, precision, twkbPrecision) }},
+	} {
+		actual, err := tc.f()
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.expected, actual, tc.name)
+	}
+	assert.Equal(t, orb.LineString{{0.1234, 1.0001}, {0.1231, 1.0004}, {1.5, 2.5}}, orbLineString, "source modified")
+	assert.Equal(t, []float64{0.1234, 1.0001, 0.1231, 1.0004, 1.5, 2.5}, geomLineString.FlatCoords(), "source modified")
+
+	t.Run("polygon", func(t *testing.T) {
+		orbPolygon := orb.Polygon{
+			{{0, 0}, {4, 0}, {4.0001, 0}, {4, 4}, {0, 4}, {0, 0}},
+			{{1, 1}, {1.0001, 1}, {1, 1.0001}, {1, 1}},
+		}
+		expectedOrbPolygon := orb.Polygon{
+			{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+			{{1, 1}, {1, 1}, {1, 1}, {1, 1}},
+		}
+		assert.Equal(t, expectedOrbPolygon, geobabel.NewOrbGeometryFromGeomT(geobabel.NewGeomTFromOrbGeometry(orbPolygon), precision))
+		assert.Equal(t, geobabel.NewGeomTFromOrbGeometry(expectedOrbPolygon), geobabel.NewGeomTFromOrbGeometry(orbPolygon, precision))
+		orbMultiPolygon := orb.MultiPolygon{orbPolygon, orbPolygon}
+		expectedOrbMultiPolygon := orb.MultiPolygon{expectedOrbPolygon, expectedOrbPolygon}
+		assert.Equal(t, geobabel.NewGeomTFromOrbGeometry(expectedOrbMultiPolygon), geobabel.NewGeomTFromOrbGeometry(orbMultiPolygon, precision))
+		assert.Equal(t, expectedOrbMultiPolygon, geobabel.NewOrbGeometryFromGeomT(geobabel.NewGeomTFromOrbGeometry(orbMultiPolygon), precision))
+		geosMultiPolygon := geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbMultiPolygon)
+		assert.Equal(t, expectedOrbMultiPolygon, geobabel.NewOrbGeometryFromGEOSGeom(geosMultiPolygon, precision))
+		assert.Equal(t, geobabel.NewGeomTFromOrbGeometry(expectedOrbMultiPolygon), geobabel.NewGeomTFromGEOSGeom(geosMultiPolygon, precision))
+		assert.Equal(t, geobabel.WKBFromOrbGeometry(expectedOrbMultiPolygon), geobabel.WKBFromGEOSGeom(geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbMultiPolygon, precision)))
+		expectedWKT, err := geobabel.WKTFromOrbGeometry(expectedOrbMultiPolygon)
+		require.NoError(t, err)
+		wkt, err := geobabel.WKTFromGeomT(geobabel.NewGeomTFromOrbGeometry(orbMultiPolygon), precision)
+		require.NoError(t, err)
+		assert.Equal(t, expectedWKT, wkt)
+		wkt, err = geobabel.WKTFromGEOSGeom(geosMultiPolygon, precision)
+		require.NoError(t, err)
+		assert.Equal(t, expectedWKT, wkt)
+	})
+
+	t.Run("grid_size", func(t *testing.T) {
+		assert.Equal(t,
+			orb.MultiLineString{{{10, 20}, {30, 40}}},
+			geobabel.NewOrbGeometryFromGeomT(geobabel.NewGeomTFromOrbGeometry(orb.MultiLineString{{{14, 16}, {11, 19}, {33, 38}}}), geobabel.WithGridSize(10)),
+		)
+		wkt, err := geobabel.WKTFromGeomT(geobabel.NewGeomTFromOrbGeometry(orb.MultiLineString{{{14, 16}, {11, 19}, {33, 38}}}), geobabel.WithGridSize(10))
+		require.NoError(t, err)
+		assert.Equal(t, "MULTILINESTRING ((10 20, 30 40))", wkt)
+		wkb, err := geobabel.WKBFromGeomT(geobabel.NewGeomTFromOrbGeometry(orb.MultiLineString{{{14, 16}, {11, 19}, {33, 38}}}), geobabel.WithGridSize(10))
+		require.NoError(t, err)
+		assert.Equal(t, geobabel.WKBFromOrbGeometry(orb.MultiLineString{{{10, 20}, {30, 40}}}), wkb)
+	})
+
+	t.Run("transform", func(t *testing.T) {
+		scale := geobabel.WithTransform(func(x, y float64) (float64, float64) {
+			return 1000 * x, 1000 * y
+		})
+		assert.Equal(t,
+			orb.Point{1235, 2},
+			geobabel.NewOrbGeometryFromGeomT(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1.2346, 0.0021}), geobabel.WithPrecision(0), scale),
+		)
+	})
+}
+
+func TestValidity(t *testing.T) {
+	geosContext := geos.NewContext()
+	errorPolicy := geobabel.WithValidityPolicy(geobabel.ValidityPolicyError)
+	repairPolicy := geobabel.WithValidityPolicy(geobabel.ValidityPolicyRepair)
+
+	t.Run("orb", func(t *testing.T) {
+		orbMultiPolygon := orb.MultiPolygon{
+			{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+			{{{0, 0}, {2, 0}, {2, 2}, {0, 2}}, {{1, 1}, {1, 1}, {1, 1}}},
+		}
+		_, err := geobabel.NewGeomTFromOrbGeometryE(orbMultiPolygon, errorPolicy)
+		assert.EqualError(t, err, "[1][0]: orb.Ring: invalid geometry: unclosed ring at [0 0]")
+		var convertError *geobabel.ConvertError
+		require.True(t, errors.As(err, &convertError))
+		assert.Equal(t, []int{1, 0}, convertError.Path)
+		var validityError *geobabel.ValidityError
+		require.True(t, errors.As(err, &validityError))
+		assert.Equal(t, []float64{0, 0}, validityError.Location)
+		assert.ErrorIs(t, err, geobabel.ErrInvalidGeometry)
+
+		_, err = geobabel.NewGEOSGeomFromOrbGeometryE(geosContext, orb.LineString{{1, 2}}, errorPolicy)
+		assert.EqualError(t, err, "orb.LineString: invalid geometry: too few points at [1 2]")
+
+		_, err = geobabel.EWKBFromOrbGeometryE(orbMultiPolygon, 4326, errorPolicy)
+		assert.ErrorIs(t, err, geobabel.ErrInvalidGeometry)
+		require.True(t, errors.As(err, &convertError))
+		_, err = geobabel.HexEWKBFromOrbGeometryE(orbMultiPolygon, 4326, errorPolicy)
+		assert.ErrorIs(t, err, geobabel.ErrInvalidGeometry)
+		assert.Panics(t, func() {
+			geobabel.EWKBFromOrbGeometry(orbMultiPolygon, 4326, errorPolicy)
+		})
+
+		assert.Equal(t,
+			geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+				{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}},
+			}),
+			geobabel.NewGeomTFromOrbGeometry(orbMultiPolygon, repairPolicy),
+		)
+		assert.Equal(t, orb.Point{1, 1}, orbMultiPolygon[1][1][0], "source modified")
+
+		assert.Equal(t,
+			geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{{{0, 0}, {1, 1}}}),
+			geobabel.NewGeomTFromOrbGeometry(orb.MultiLineString{{{2, 2}}, {{0, 0}, {1, 1}}}, repairPolicy),
+		)
+	})
+
+	t.Run("geom", func(t *testing.T) {
+		geomPolygon := geom.NewPolygon(geom.XYZ).MustSetCoords([][]geom.Coord{{{0, 0, 1}, {1, 0, 2}, {1, 1, 3}}})
+		_, err := geobabel.NewOrbGeometryFromGeomTE(geomPolygon, errorPolicy)
+		assert.EqualError(t, err, "[0]: *geom.LinearRing: invalid geometry: unclosed ring at [0 0]")
+		_, err = geobabel.NewGEOSGeomFromGeomTE(geosContext, geomPolygon, errorPolicy)
+		assert.ErrorIs(t, err, geobabel.ErrInvalidGeometry)
+
+		assert.Equal(t,
+			orb.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+			geobabel.NewOrbGeometryFromGeomT(geomPolygon, repairPolicy),
+		)
+		assert.Equal(t, 3, geomPolygon.NumCoords(), "source modified")
+
+		_, err = geobabel.NewOrbGeometryFromGeomTE(geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 2}}), errorPolicy)
+		assert.EqualError(t, err, "*geom.LineString: invalid geometry: too few points at [1 2]")
+	})
+
+	t.Run("geos", func(t *testing.T) {
+		orbBowtie := orb.Polygon{{{0, 0}, {2, 2}, {2, 0}, {0, 2}, {0, 0}}}
+		_, err := geobabel.NewGEOSGeomFromOrbGeometryE(geosContext, orbBowtie, errorPolicy)
+		assert.EqualError(t, err, "orb.Polygon: invalid geometry: Self-intersection at [1 1]")
+		var validityError *geobabel.ValidityError
+		require.True(t, errors.As(err, &validityError))
+		assert.Equal(t, "Self-intersection", validityError.Reason)
+		assert.Equal(t, []float64{1, 1}, validityError.Location)
+
+		_, err = geobabel.NewGeomTFromOrbGeometryE(orbBowtie, errorPolicy)
+		assert.NoError(t, err, "GEOS is only used with a GEOS context")
+		_, err = geobabel.NewGeomTFromOrbGeometryE(orbBowtie, errorPolicy, geobabel.WithGEOSContext(geosContext))
+		assert.ErrorIs(t, err, geobabel.ErrInvalidGeometry)
+
+		geosBowtie := geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbBowtie)
+		_, err = geobabel.NewGeomTFromGEOSGeomE(geosBowtie, errorPolicy)
+		assert.EqualError(t, err, "Polygon: invalid geometry: Self-intersection at [1 1]")
+		_, err = geobabel.NewOrbGeometryFromGEOSGeomE(geosBowtie)
+		assert.NoError(t, err)
+
+		geosGeom := geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbBowtie, repairPolicy)
+		assert.Equal(t, geos.TypeIDPolygon, geosGeom.TypeID())
+		assert.Equal(t, geos.TypeIDPolygon, geobabel.NewGEOSGeomFromGeomT(geosContext, geobabel.NewGeomTFromOrbGeometry(orbBowtie), repairPolicy).TypeID())
+		_, ok := geobabel.NewOrbGeometryFromGEOSGeom(geosBowtie, repairPolicy).(orb.Polygon)
+		assert.True(t, ok)
+		_, ok = geobabel.NewGeomTFromGEOSGeom(geosBowtie, repairPolicy).(*geom.Polygon)
+		assert.True(t, ok)
+
+		geosCollection := geosContext.NewCollection(geos.TypeIDGeometryCollection, []*geos.Geom{
+			geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orb.Polygon{{{0, 0}, {2.0001, 2}, {2, 0}, {0, 2}, {0, 0}}}),
+		}).SetSRID(4326)
+		options := []geobabel.Option{
+			geobabel.WithOrientation(geobabel.OrientationClockwise),
+			geobabel.WithPrecision(3),
+		}
+		geomT, err := geobabel.NewGeomTFromGEOSGeomE(geosCollection, append(options, repairPolicy)...)
+		require.NoError(t, err)
+		assert.Equal(t, geobabel.NewGeomTFromGEOSGeom(geosCollection.MakeValid(), options...), geomT)
+		assert.Equal(t, 4326, geomT.SRID())
+		assert.Equal(t, 0, geomT.(*geom.GeometryCollection).Geom(0).SRID())
+	})
+}
+
+// A wkbGeometry is a geometry type from another library that can be
+// marshaled to and unmarshaled from WKB.
+type wkbGeometry struct {
+	wkb []byte
+}
+
+func (g *wkbGeometry) MarshalBinary() ([]byte, error) {
+	return g.wkb, nil
+}
+
+func (g *wkbGeometry) UnmarshalBinary(data []byte) error {
+	g.wkb = data
+	return nil
+}
+
+func TestConvert(t *testing.T) {
+	geosContext := geos.NewContext()
+	geomPoint := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2})
+	orbPoint := orb.Point{1, 2}
+	wkb, err := geobabel.WKBFromGeomT(geomPoint)
+	require.NoError(t, err)
+	options := []geobabel.Option{geobabel.WithGEOSContext(geosContext)}
+
+	for _, from := range []any{
+		geomPoint,
+		geosContext.NewPoint([]float64{1, 2}),
+		orbPoint,
+		wkb,
+		&wkbGeometry{wkb: wkb},
+	} {
+		t.Run(fmt.Sprintf("%T", from), func(t *testing.T) {
+			geomT, err := geobabel.Convert[geom.T](from, options...)
+			require.NoError(t, err)
+			assert.Equal(t, geomPoint, geomT)
+
+			actualGeomPoint, err := geobabel.Convert[*geom.Point](from, options...)
+			require.NoError(t, err)
+			assert.Equal(t, geomPoint, actualGeomPoint)
+
+			geosGeom, err := geobabel.Convert[*geos.Geom](from, options...)
+			require.NoError(t, err)
+			assert.True(t, geosContext.NewPoint([]float64{1, 2}).EqualsExact(geosGeom, 0))
+
+			orbGeometry, err := geobabel.Convert[orb.Geometry](from, options...)
+			require.NoError(t, err)
+			assert.Equal(t, orbPoint, orbGeometry)
+
+			actualOrbPoint, err := geobabel.Convert[orb.Point](from, options...)
+			require.NoError(t, err)
+			assert.Equal(t, orbPoint, actualOrbPoint)
+
+			actualWKB, err := geobabel.Convert[[]byte](from, options...)
+			require.NoError(t, err)
+			assert.Equal(t, wkb, actualWKB)
+
+			actualWKBGeometry, err := geobabel.Convert[*wkbGeometry](from, options...)
+			require.NoError(t, err)
+			assert.Equal(t, wkb, actualWKBGeometry.wkb)
+
+			_, err = geobabel.Convert[*geom.LineString](from, options...)
+			assert.ErrorIs(t, err, geobabel.ErrUnsupportedType)
+
+			_, err = geobabel.Convert[orb.LineString](from, options...)
+			assert.ErrorIs(t, err, geobabel.ErrUnsupportedType)
+		})
+	}
+
+	_, err = geobabel.Convert[geom.T]("POINT (1 2)")
+	assert.ErrorIs(t, err, geobabel.ErrUnsupportedType)
+
+	_, err = geobabel.Convert[string](geomPoint)
+	assert.ErrorIs(t, err, geobabel.ErrUnsupportedType)
+
+	_, err = geobabel.Convert[*geos.Geom](orbPoint)
+	assert.ErrorIs(t, err, geobabel.ErrNoGEOSContext)
+	var convertError *geobabel.ConvertError
+	assert.True(t, errors.As(err, &convertError))
+
+	t.Run("options", func(t *testing.T) {
+		precision := geobabel.WithPrecision(0)
+		orbLineString := orb.LineString{{1.4, 2.4}, {3.4, 4.4}}
+		expectedWKB := geobabel.WKBFromOrbGeometry(orb.LineString{{1, 2}, {3, 4}})
+		geosLineString := geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbLineString)
+		for _, from := range []any{
+			geobabel.NewGeomTFromOrbGeometry(orbLineString),
+			geosLineString,
+			orbLineString,
+		} {
+			actualWKB, err := geobabel.Convert[[]byte](from, precision)
+			require.NoError(t, err)
+			assert.Equal(t, expectedWKB, actualWKB)
+		}
+		lineStringPolicy := geobabel.WithLinearRingPolicy(geobabel.LinearRingPolicyLineString)
+		fromWKB := &wkbGeometry{wkb: geobabel.WKBFromOrbGeometry(orb.LineString{{0, 0}, {1, 0}, {1, 1}, {0, 0}})}
+		geomT, err := geobabel.Convert[geom.T](fromWKB, lineStringPolicy)
+		require.NoError(t, err)
+		assert.IsType(t, &geom.LinearRing{}, geomT)
+		orbGeometry, err := geobabel.Convert[orb.Geometry](fromWKB, lineStringPolicy)
+		require.NoError(t, err)
+		assert.IsType(t, orb.Ring{}, orbGeometry)
+		geosGeom, err := geobabel.Convert[*geos.Geom](fromWKB, lineStringPolicy, geobabel.WithGEOSContext(geosContext))
+		require.NoError(t, err)
+		assert.Equal(t, geos.TypeIDLinearRing, geosGeom.TypeID())
+
+		geosLinearRing := geosContext.NewLinearRing([][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}})
+		_, err = geobabel.Convert[[]byte](geosLinearRing)
+		assert.ErrorIs(t, err, geobabel.ErrUnsupportedType)
+		_, err = geobabel.Convert[[]byte](geosLinearRing, lineStringPolicy)
+		assert.NoError(t, err)
+	})
+}
+
+func mustEWKTFromGeomT(t *testing.T, geomT geom.T, options ...geobabel.Option) string {
+	t.Helper()
+	ewkt, err := geobabel.EWKTFromGeomT(geomT, options...)
+	require.NoError(t, err)
+	return ewkt
+}
+
+type customOrbGeometry struct {
+	orb.Point
+}
+
+type customPolygon struct {
+	*geom.Polygon
+}
+
+func TestUnsupportedType(t *testing.T) {
+	geosContext := geos.NewContext()
+
+	orbGeometry := customOrbGeometry{}
+	_, err := geobabel.NewGeomTFromOrbGeometryE(orbGeometry)
+	assert.True(t, errors.Is(err, geobabel.ErrUnsupportedType))
+	assert.EqualError(t, err, "geobabel_test.customOrbGeometry: unsupported type")
+	assert.Panics(t, func() {
+		geobabel.NewGeomTFromOrbGeometry(orbGeometry)
+	})
+
+	_, err = geobabel.NewGEOSGeomFromOrbGeometryE(geosContext, orb.Collection{
+		orb.Point{1, 2},
+		orb.Collection{orb.LineString{{1, 2}, {3, 4}}, orbGeometry},
+	})
+	var convertError *geobabel.ConvertError
+	require.True(t, errors.As(err, &convertError))
+	assert.Equal(t, "geobabel_test.customOrbGeometry", convertError.Type)
+	assert.Equal(t, []int{1, 1}, convertError.Path)
+	assert.EqualError(t, err, "[1][1]: geobabel_test.customOrbGeometry: unsupported type")
+
+	geomT := customPolygon{geom.NewPolygon(geom.XY)}
+	_, err = geobabel.NewOrbGeometryFromGeomTE(geomT)
+	assert.True(t, errors.Is(err, geobabel.ErrUnsupportedType))
+	_, err = geobabel.NewGEOSGeomFromGeomTE(geosContext, geom.NewGeometryCollection().MustPush(geomT))
+	assert.EqualError(t, err, "[0]: geobabel_test.customPolygon: unsupported type")
+	assert.Panics(t, func() {
+		geobabel.NewOrbGeometryFromGeomT(geomT)
+	})
+}
+
+// assertOrbGeometryEqual asserts that expected and actual are equal. orb
+// represents empty points with NaN coordinates, which are not equal to
+// themselves, so geometries are compared by their Go syntax representations.
+func assertOrbGeometryEqual(t *testing.T, expected, actual orb.Geometry) {
+	t.Helper()
+	assert.Equal(t, fmt.Sprintf("%#v", expected), fmt.Sprintf("%#v", actual))
+}
+
+// benchmarkOrbPolygon returns a building footprint-sized polygon.
+func benchmarkOrbPolygon() orb.Polygon {
+	orbRing := make(orb.Ring, 0, 33)
+	for i := 0; i < 32; i++ {
+		angle := 2 * math.Pi * float64(i) / 32
+		orbRing = append(orbRing, orb.Point{math.Cos(angle), math.Sin(angle)})
+	}
+	orbRing = append(orbRing, orbRing[0])
+	return orb.Polygon{orbRing}
+}
+
+func BenchmarkGeomFlatCoordsFromOrbPolygon(b *testing.B) {
+	orbPolygon := benchmarkOrbPolygon()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = geobabel.GeomFlatCoordsFromOrbPolygon(orbPolygon)
+	}
+}
+
+func BenchmarkAppendGeomFlatCoordsFromOrbPolygon(b *testing.B) {
+	orbPolygon := benchmarkOrbPolygon()
+	var geomFlatCoords []float64
+	var geomEnds []int
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		geomFlatCoords, geomEnds = geobabel.AppendGeomFlatCoordsFromOrbPolygon(geomFlatCoords[:0], geomEnds[:0], orbPolygon)
+	}
+}
+
+func BenchmarkNewOrbGeometryFromGeomT(b *testing.B) {
+	geomPolygon := geobabel.NewGeomPolygonFromOrbPolygon(benchmarkOrbPolygon())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = geobabel.NewOrbGeometryFromGeomT(geomPolygon)
+	}
+}
+
+func BenchmarkAppendOrbPolygonFromGeomFlatCoords(b *testing.B) {
+	geomPolygon := geobabel.NewGeomPolygonFromOrbPolygon(benchmarkOrbPolygon())
+	var orbPolygon orb.Polygon
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		orbPolygon = geobabel.AppendOrbPolygonFromGeomFlatCoords(orbPolygon[:0], geomPolygon.FlatCoords(), geomPolygon.Ends(), geomPolygon.Stride())
+	}
+}
+
+func BenchmarkNewGEOSGeomFromOrbGeometry(b *testing.B) {
+	geosContext := geos.NewContext()
+	orbPolygon := benchmarkOrbPolygon()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = geobabel.NewGEOSGeomFromOrbGeometry(geosContext, orbPolygon)
+	}
+}
+
+func BenchmarkNewGEOSGeomFromGeomT(b *testing.B) {
+	geosContext := geos.NewContext()
+	geomPolygon := geobabel.NewGeomPolygonFromOrbPolygon(benchmarkOrbPolygon())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = geobabel.NewGEOSGeomFromGeomT(geosContext, geomPolygon)
+	}
+}
+
+func BenchmarkNewGeomTFromGEOSGeom(b *testing.B) {
+	geosPolygon := geobabel.NewGEOSGeomFromOrbGeometry(geos.NewContext(), benchmarkOrbPolygon())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = geobabel.NewGeomTFromGEOSGeom(geosPolygon)
+	}
+}
+
+func BenchmarkNewGEOSGeomsFromOrbGeometries(b *testing.B) {
+	geosContext := geos.NewContext()
+	orbGeometries := make([]orb.Geometry, 0, 100)
+	for i := 0; i < 100; i++ {
+		orbGeometries = append(orbGeometries, benchmarkOrbPolygon())
+	}
+	converter := geobabel.NewConverter()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = converter.NewGEOSGeomsFromOrbGeometries(geosContext, orbGeometries)
+	}
+}
+
+func BenchmarkConverterNewGEOSGeomFromOrbGeometry(b *testing.B) {
+	geosContext := geos.NewContext()
+	converter := geobabel.NewConverter()
+	orbPolygon := benchmarkOrbPolygon()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = converter.NewGEOSGeomFromOrbGeometry(geosContext, orbPolygon)
+	}
+}
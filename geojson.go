@@ -0,0 +1,120 @@
+package geobabel
+
+import (
+	"fmt"
+
+	"github.com/paulmach/orb"
+	"github.com/twpayne/go-geom"
+	geomgeojson "github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/twpayne/go-geos"
+)
+
+// All GeoJSON is parsed and formatted by go-geom so that the result does not
+// depend on which library produced a geometry. Coordinates are formatted
+// without exponents and with trailing zeros removed. Following RFC 7946,
+// positions have at most three elements, so M ordinates are handled according
+// to the ZM policy.
+
+// NewGEOSGeomFromGeoJSON returns a new *geos.Geom parsed from geoJSON.
+func NewGEOSGeomFromGeoJSON(geosContext *geos.Context, geoJSON []byte, options ...Option) (*geos.Geom, error) {
+	geomT, err := NewGeomTFromGeoJSON(geoJSON)
+	if err != nil {
+		return nil, err
+	}
+	return NewGEOSGeomFromGeomTE(geosContext, geomT, options...)
+}
+
+// NewGeomTFromGeoJSON returns a new geom.T parsed from geoJSON.
+func NewGeomTFromGeoJSON(geoJSON []byte) (geom.T, error) {
+	var geomT geom.T
+	if err := geomgeojson.Unmarshal(geoJSON, &geomT); err != nil {
+		return nil, err
+	}
+	return geomT, nil
+}
+
+// NewOrbGeometryFromGeoJSON returns a new orb.Geometry parsed from geoJSON.
+func NewOrbGeometryFromGeoJSON(geoJSON []byte, options ...Option) (orb.Geometry, error) {
+	geomT, err := NewGeomTFromGeoJSON(geoJSON)
+	if err != nil {
+		return nil, err
+	}
+	return NewOrbGeometryFromGeomTE(geomT, options...)
+}
+
+// GeoJSONFromGEOSGeom returns geosGeom encoded as GeoJSON.
+func GeoJSONFromGEOSGeom(geosGeom *geos.Geom, options ...Option) ([]byte, error) {
+	c := newConverter(options)
+	geomT, err := c.newGeomTFromGEOSGeomE(geosGeom)
+	if err != nil {
+		return nil, err
+	}
+	return c.marshalGeoJSON(geomT)
+}
+
+// GeoJSONFromGeomT returns geomT encoded as GeoJSON.
+func GeoJSONFromGeomT(geomT geom.T, options ...Option) ([]byte, error) {
+	return newConverter(options).geoJSONFromGeomT(geomT)
+}
+
+// GeoJSONFromOrbGeometry returns orbGeometry encoded as GeoJSON.
+func GeoJSONFromOrbGeometry(orbGeometry orb.Geometry, options ...Option) ([]byte, error) {
+	c := newConverter(options)
+	geomT, err := c.newGeomTFromOrbGeometryE(orbGeometry)
+	if err != nil {
+		return nil, err
+	}
+	return c.marshalGeoJSON(geomT)
+}
+
+// geoJSONFromGeomT returns geomT encoded as GeoJSON, transformed like
+// conversions from GEOS and orb.
+func (c *converter) geoJSONFromGeomT(geomT geom.T) ([]byte, error) {
+	return c.marshalGeoJSON(c.transformGeomT(geomT))
+}
+
+// marshalGeoJSON returns geomT, which has already been transformed, encoded as
+// GeoJSON.
+func (c *converter) marshalGeoJSON(geomT geom.T) ([]byte, error) {
+	geomT, err := c.geoJSONGeomT(geomT)
+	if err != nil {
+		return nil, err
+	}
+	return geomgeojson.Marshal(geomT, c.geoJSONEncodeGeometryOptions(geomT)...)
+}
+
+// geoJSONGeomT returns geomT prepared for encoding as GeoJSON according to c's
+// options.
+func (c *converter) geoJSONGeomT(geomT geom.T) (geom.T, error) {
+	if geomGeometryCollection, ok := geomT.(*geom.GeometryCollection); ok && geomGeometryCollection.NumGeoms() != 0 {
+		newGeomGeometryCollection := geom.NewGeometryCollection()
+		for i, geomGeom := range geomGeometryCollection.Geoms() {
+			newGeomGeom, err := c.geoJSONGeomT(geomGeom)
+			if err != nil {
+				return nil, withPathIndex(err, i)
+			}
+			newGeomGeometryCollection.MustPush(newGeomGeom)
+		}
+		return newGeomGeometryCollection.SetSRID(geomT.SRID()), nil
+	}
+	switch geomLayout := geomT.Layout(); geomLayout {
+	case geom.XYM, geom.XYZM:
+		if c.zmPolicy != ZMPolicyDrop {
+			return nil, newUnsupportedLayoutError(fmt.Sprintf("%T", geomT), geomLayout.String())
+		}
+		geomT = geomTWithLayout(geomT, geomLayoutFromHasZM(geomLayout == geom.XYZM, false))
+	}
+	return geomT, nil
+}
+
+// geoJSONEncodeGeometryOptions returns the options for encoding geomT as
+// GeoJSON.
+func (c *converter) geoJSONEncodeGeometryOptions(geomT geom.T) []geomgeojson.EncodeGeometryOption {
+	encodeGeometryOptions := []geomgeojson.EncodeGeometryOption{
+		geomgeojson.EncodeGeometryWithMaxDecimalDigits(c.maxDecimalDigits),
+	}
+	if c.geoJSONBBox && !geomT.Empty() {
+		encodeGeometryOptions = append(encodeGeometryOptions, geomgeojson.EncodeGeometryWithBBox())
+	}
+	return encodeGeometryOptions
+}
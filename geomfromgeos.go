@@ -0,0 +1,565 @@
+package geobabel
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geos"
+)
+
+func NewGeomTFromGEOSGeom(geosGeom *geos.Geom, options ...Option) geom.T {
+	geomT, err := NewGeomTFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geomT
+}
+
+// NewGeomTFromGEOSGeomE returns a new geom.T converted from geosGeom. It
+// returns a *ConvertError if geosGeom cannot be converted.
+func NewGeomTFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (geom.T, error) {
+	return newConverter(options).newGeomTFromGEOSGeomE(geosGeom)
+}
+
+func (c *converter) newGeomTFromGEOSGeomE(geosGeom *geos.Geom) (geom.T, error) {
+	repairedGeomT, err := c.validateSourceGEOSGeom(geosGeom)
+	switch {
+	case err != nil:
+		return nil, err
+	case repairedGeomT != nil:
+		return c.transformGeomT(repairedGeomT), nil
+	default:
+		return c.newGeomTFromGEOSGeom(geosGeom)
+	}
+}
+
+func (c *converter) newGeomTFromGEOSGeom(geosGeom *geos.Geom) (geom.T, error) {
+	switch geosGeom.TypeID() {
+	case geos.TypeIDPoint:
+		return c.newGeomPointFromGEOSGeom(geosGeom), nil
+	case geos.TypeIDLineString:
+		return c.newGeomLineStringFromGEOSGeom(geosGeom), nil
+	case geos.TypeIDLinearRing:
+		return c.newGeomLinearRingFromGEOSGeom(geosGeom), nil
+	case geos.TypeIDPolygon:
+		return c.newGeomPolygonFromGEOSGeom(geosGeom), nil
+	case geos.TypeIDMultiPoint:
+		return c.newGeomMultiPointFromGEOSGeom(geosGeom), nil
+	case geos.TypeIDMultiLineString:
+		return c.newGeomMultiLineStringFromGEOSGeom(geosGeom), nil
+	case geos.TypeIDMultiPolygon:
+		return c.newGeomMultiPolygonFromGEOSGeom(geosGeom), nil
+	case geos.TypeIDGeometryCollection:
+		geomGeometryCollection, err := c.newGeomGeometryCollectionFromGEOSGeom(geosGeom)
+		if err != nil {
+			return nil, err
+		}
+		return geomGeometryCollection, nil
+	default:
+		return nil, newUnsupportedTypeError(geosGeom.Type())
+	}
+}
+
+func NewGeomPointFromGEOSGeom(geosGeom *geos.Geom, options ...Option) *geom.Point {
+	geomPoint, err := NewGeomPointFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geomPoint
+}
+
+// NewGeomPointFromGEOSGeomE returns a new *geom.Point converted from geosGeom.
+// It returns a *ConvertError wrapping ErrUnexpectedType if geosGeom is not a
+// point.
+func NewGeomPointFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (*geom.Point, error) {
+	c := newConverter(options)
+	geosGeom, err := c.geosGeomWithTypeID(geosGeom, geos.TypeIDPoint)
+	if err != nil {
+		return nil, err
+	}
+	return c.newGeomPointFromGEOSGeom(geosGeom), nil
+}
+
+func (c *converter) newGeomPointFromGEOSGeom(geosGeom *geos.Geom) *geom.Point {
+	b := geomFlatCoordsBuilder{transform: c.transform}
+	b.appendGEOSCoordSeq(geosGeom.CoordSeq())
+	geomLayout, geomFlatCoords := b.build()
+	return geom.NewPointFlat(geomLayout, geomFlatCoords).SetSRID(c.targetSRID(geosGeom.SRID()))
+}
+
+func NewGeomLineStringFromGEOSGeom(geosGeom *geos.Geom, options ...Option) *geom.LineString {
+	geomLineString, err := NewGeomLineStringFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geomLineString
+}
+
+// NewGeomLineStringFromGEOSGeomE returns a new *geom.LineString converted from
+// geosGeom. It returns a *ConvertError wrapping ErrUnexpectedType if geosGeom
+// is not a line string.
+func NewGeomLineStringFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (*geom.LineString, error) {
+	c := newConverter(options)
+	geosGeom, err := c.geosGeomWithTypeID(geosGeom, geos.TypeIDLineString)
+	if err != nil {
+		return nil, err
+	}
+	return c.newGeomLineStringFromGEOSGeom(geosGeom), nil
+}
+
+func (c *converter) newGeomLineStringFromGEOSGeom(geosGeom *geos.Geom) *geom.LineString {
+	if geosGeom.IsEmpty() {
+		return geom.NewLineStringFlat(geom.XY, []float64{}).SetSRID(c.targetSRID(geosGeom.SRID()))
+	}
+	b := geomFlatCoordsBuilder{transform: c.transform}
+	b.appendGEOSCoordSeq(geosGeom.CoordSeq())
+	geomLayout, geomFlatCoords := b.build()
+	if c.removeRepeatedPoints() {
+		geomFlatCoords = geomRemoveRepeatedPoints(geomFlatCoords, geomLayout.Stride(), minLineStringPoints)
+	}
+	return geom.NewLineStringFlat(geomLayout, geomFlatCoords).SetSRID(c.targetSRID(geosGeom.SRID()))
+}
+
+func NewGeomLinearRingFromGEOSGeom(geosGeom *geos.Geom, options ...Option) *geom.LinearRing {
+	geomLinearRing, err := NewGeomLinearRingFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geomLinearRing
+}
+
+// NewGeomLinearRingFromGEOSGeomE returns a new *geom.LinearRing converted from
+// geosGeom. It returns a *ConvertError wrapping ErrUnexpectedType if geosGeom
+// is not a linear ring.
+func NewGeomLinearRingFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (*geom.LinearRing, error) {
+	c := newConverter(options)
+	geosGeom, err := c.geosGeomWithTypeID(geosGeom, geos.TypeIDLinearRing)
+	if err != nil {
+		return nil, err
+	}
+	return c.newGeomLinearRingFromGEOSGeom(geosGeom), nil
+}
+
+func (c *converter) newGeomLinearRingFromGEOSGeom(geosGeom *geos.Geom) *geom.LinearRing {
+	if geosGeom.IsEmpty() {
+		return geom.NewLinearRingFlat(geom.XY, []float64{}).SetSRID(c.targetSRID(geosGeom.SRID()))
+	}
+	b := geomFlatCoordsBuilder{transform: c.transform}
+	b.appendGEOSCoordSeq(geosGeom.CoordSeq())
+	geomLayout, geomFlatCoords := b.build()
+	if c.removeRepeatedPoints() {
+		geomFlatCoords = geomRemoveRepeatedPoints(geomFlatCoords, geomLayout.Stride(), minRingPoints)
+	}
+	return geom.NewLinearRingFlat(geomLayout, geomFlatCoords).SetSRID(c.targetSRID(geosGeom.SRID()))
+}
+
+func NewGeomPolygonFromGEOSGeom(geosGeom *geos.Geom, options ...Option) *geom.Polygon {
+	geomPolygon, err := NewGeomPolygonFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geomPolygon
+}
+
+// NewGeomPolygonFromGEOSGeomE returns a new *geom.Polygon converted from
+// geosGeom. It returns a *ConvertError wrapping ErrUnexpectedType if geosGeom
+// is not a polygon.
+func NewGeomPolygonFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (*geom.Polygon, error) {
+	c := newConverter(options)
+	geosGeom, err := c.geosGeomWithTypeID(geosGeom, geos.TypeIDPolygon)
+	if err != nil {
+		return nil, err
+	}
+	return c.newGeomPolygonFromGEOSGeom(geosGeom), nil
+}
+
+func (c *converter) newGeomPolygonFromGEOSGeom(geosGeom *geos.Geom) *geom.Polygon {
+	if geosGeom.IsEmpty() {
+		return geom.NewPolygon(geom.XY).SetSRID(c.targetSRID(geosGeom.SRID()))
+	}
+	b := geomFlatCoordsBuilder{transform: c.transform}
+	geomEnds := b.appendGEOSPolygon(geosGeom)
+	geomLayout, geomFlatCoords := b.build()
+	geomEnds = geomEndsFromNumCoords(geomEnds, geomLayout)
+	if c.removeRepeatedPoints() {
+		geomFlatCoords = geomFlatCoords[:geomRemoveRepeatedPointsEnds(geomFlatCoords, 0, 0, geomEnds, geomLayout.Stride(), minRingPoints)]
+	}
+	geomOrientPolygonFlatCoords(geomFlatCoords, 0, geomEnds, geomLayout.Stride(), c.orientation)
+	return geom.NewPolygonFlat(geomLayout, geomFlatCoords, geomEnds).SetSRID(c.targetSRID(geosGeom.SRID()))
+}
+
+func NewGeomMultiPointFromGEOSGeom(geosGeom *geos.Geom, options ...Option) *geom.MultiPoint {
+	geomMultiPoint, err := NewGeomMultiPointFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geomMultiPoint
+}
+
+// NewGeomMultiPointFromGEOSGeomE returns a new *geom.MultiPoint converted from
+// geosGeom. It returns a *ConvertError wrapping ErrUnexpectedType if geosGeom
+// is not a multipoint.
+func NewGeomMultiPointFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (*geom.MultiPoint, error) {
+	c := newConverter(options)
+	geosGeom, err := c.geosGeomWithTypeID(geosGeom, geos.TypeIDMultiPoint)
+	switch {
+	case err != nil:
+		return nil, err
+	case geosGeom.TypeID() != geos.TypeIDPoint:
+		return c.newGeomMultiPointFromGEOSGeom(geosGeom), nil
+	default:
+		geomPoint := c.newGeomPointFromGEOSGeom(geosGeom)
+		if geomPoint.Empty() {
+			return geom.NewMultiPoint(geom.XY).SetSRID(geomPoint.SRID()), nil
+		}
+		return geom.NewMultiPointFlat(geomPoint.Layout(), geomPoint.FlatCoords()).SetSRID(geomPoint.SRID()), nil
+	}
+}
+
+func (c *converter) newGeomMultiPointFromGEOSGeom(geosGeom *geos.Geom) *geom.MultiPoint {
+	geosNumGeometries := geosGeom.NumGeometries()
+	if geosNumGeometries == 0 {
+		return geom.NewMultiPoint(geom.XY).SetSRID(c.targetSRID(geosGeom.SRID()))
+	}
+	b := geomFlatCoordsBuilder{transform: c.transform}
+	geomEnds := make([]int, 0, geosNumGeometries)
+	for i := 0; i < geosNumGeometries; i++ {
+		b.appendGEOSCoordSeq(geosGeom.Geometry(i).CoordSeq())
+		geomEnds = append(geomEnds, b.numCoords())
+	}
+	geomLayout, geomFlatCoords := b.build()
+	return geom.NewMultiPointFlat(geomLayout, geomFlatCoords, geom.NewMultiPointFlatOptionWithEnds(geomEndsFromNumCoords(geomEnds, geomLayout))).SetSRID(c.targetSRID(geosGeom.SRID()))
+}
+
+func NewGeomMultiLineStringFromGEOSGeom(geosGeom *geos.Geom, options ...Option) *geom.MultiLineString {
+	geomMultiLineString, err := NewGeomMultiLineStringFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geomMultiLineString
+}
+
+// NewGeomMultiLineStringFromGEOSGeomE returns a new *geom.MultiLineString
+// converted from geosGeom. It returns a *ConvertError wrapping
+// ErrUnexpectedType if geosGeom is not a multilinestring.
+func NewGeomMultiLineStringFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (*geom.MultiLineString, error) {
+	c := newConverter(options)
+	geosGeom, err := c.geosGeomWithTypeID(geosGeom, geos.TypeIDMultiLineString)
+	switch {
+	case err != nil:
+		return nil, err
+	case geosGeom.TypeID() != geos.TypeIDLineString:
+		return c.newGeomMultiLineStringFromGEOSGeom(geosGeom), nil
+	default:
+		geomLineString := c.newGeomLineStringFromGEOSGeom(geosGeom)
+		if geomLineString.Empty() {
+			return geom.NewMultiLineString(geom.XY).SetSRID(geomLineString.SRID()), nil
+		}
+		geomFlatCoords := geomLineString.FlatCoords()
+		return geom.NewMultiLineStringFlat(geomLineString.Layout(), geomFlatCoords, []int{len(geomFlatCoords)}).SetSRID(geomLineString.SRID()), nil
+	}
+}
+
+func (c *converter) newGeomMultiLineStringFromGEOSGeom(geosGeom *geos.Geom) *geom.MultiLineString {
+	geosNumGeometries := geosGeom.NumGeometries()
+	if geosNumGeometries == 0 {
+		return geom.NewMultiLineString(geom.XY).SetSRID(c.targetSRID(geosGeom.SRID()))
+	}
+	b := geomFlatCoordsBuilder{transform: c.transform}
+	geomEnds := make([]int, 0, geosNumGeometries)
+	for i := 0; i < geosNumGeometries; i++ {
+		b.appendGEOSCoordSeq(geosGeom.Geometry(i).CoordSeq())
+		geomEnds = append(geomEnds, b.numCoords())
+	}
+	geomLayout, geomFlatCoords := b.build()
+	geomEnds = geomEndsFromNumCoords(geomEnds, geomLayout)
+	if c.removeRepeatedPoints() {
+		geomFlatCoords = geomFlatCoords[:geomRemoveRepeatedPointsEnds(geomFlatCoords, 0, 0, geomEnds, geomLayout.Stride(), minLineStringPoints)]
+	}
+	return geom.NewMultiLineStringFlat(geomLayout, geomFlatCoords, geomEnds).SetSRID(c.targetSRID(geosGeom.SRID()))
+}
+
+func NewGeomMultiPolygonFromGEOSGeom(geosGeom *geos.Geom, options ...Option) *geom.MultiPolygon {
+	geomMultiPolygon, err := NewGeomMultiPolygonFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geomMultiPolygon
+}
+
+// NewGeomMultiPolygonFromGEOSGeomE returns a new *geom.MultiPolygon converted
+// from geosGeom. It returns a *ConvertError wrapping ErrUnexpectedType if
+// geosGeom is not a multipolygon.
+func NewGeomMultiPolygonFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (*geom.MultiPolygon, error) {
+	c := newConverter(options)
+	geosGeom, err := c.geosGeomWithTypeID(geosGeom, geos.TypeIDMultiPolygon)
+	switch {
+	case err != nil:
+		return nil, err
+	case geosGeom.TypeID() != geos.TypeIDPolygon:
+		return c.newGeomMultiPolygonFromGEOSGeom(geosGeom), nil
+	default:
+		geomPolygon := c.newGeomPolygonFromGEOSGeom(geosGeom)
+		if geomPolygon.Empty() {
+			return geom.NewMultiPolygon(geom.XY).SetSRID(geomPolygon.SRID()), nil
+		}
+		return geom.NewMultiPolygonFlat(geomPolygon.Layout(), geomPolygon.FlatCoords(), [][]int{geomPolygon.Ends()}).SetSRID(geomPolygon.SRID()), nil
+	}
+}
+
+func (c *converter) newGeomMultiPolygonFromGEOSGeom(geosGeom *geos.Geom) *geom.MultiPolygon {
+	geosNumGeometries := geosGeom.NumGeometries()
+	if geosNumGeometries == 0 {
+		return geom.NewMultiPolygon(geom.XY).SetSRID(c.targetSRID(geosGeom.SRID()))
+	}
+	b := geomFlatCoordsBuilder{transform: c.transform}
+	geomEndss := make([][]int, 0, geosNumGeometries)
+	for i := 0; i < geosNumGeometries; i++ {
+		geomEnds := b.appendGEOSPolygon(geosGeom.Geometry(i))
+		geomEndss = append(geomEndss, geomEnds)
+	}
+	geomLayout, geomFlatCoords := b.build()
+	for _, geomEnds := range geomEndss {
+		geomEndsFromNumCoords(geomEnds, geomLayout)
+	}
+	if c.removeRepeatedPoints() {
+		geomFlatCoords = geomRemoveRepeatedPointsEndss(geomFlatCoords, geomEndss, geomLayout.Stride())
+	}
+	geomOrientMultiPolygonFlatCoords(geomFlatCoords, geomEndss, geomLayout.Stride(), c.orientation)
+	return geom.NewMultiPolygonFlat(geomLayout, geomFlatCoords, geomEndss).SetSRID(c.targetSRID(geosGeom.SRID()))
+}
+
+func NewGeomGeometryCollectionFromGEOSGeom(geosGeom *geos.Geom, options ...Option) *geom.GeometryCollection {
+	geomGeometryCollection, err := NewGeomGeometryCollectionFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geomGeometryCollection
+}
+
+// NewGeomGeometryCollectionFromGEOSGeomE returns a new *geom.GeometryCollection
+// converted from geosGeom. It returns a *ConvertError wrapping
+// ErrUnexpectedType if geosGeom is not a geometry collection.
+func NewGeomGeometryCollectionFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (*geom.GeometryCollection, error) {
+	c := newConverter(options)
+	geosGeom, err := c.geosGeomWithTypeID(geosGeom, geos.TypeIDGeometryCollection)
+	switch {
+	case err != nil:
+		return nil, err
+	case geosGeom.TypeID() == geos.TypeIDGeometryCollection:
+		return c.newGeomGeometryCollectionFromGEOSGeom(geosGeom)
+	default:
+		geomT, err := c.newGeomTFromGEOSGeom(geosGeom)
+		if err != nil {
+			return nil, err
+		}
+		// Like go-geom's decoders, only the collection has an SRID.
+		if _, err := geom.SetSRID(geomT, 0); err != nil {
+			return nil, err
+		}
+		return geom.NewGeometryCollection().MustPush(geomT).SetSRID(c.targetSRID(geosGeom.SRID())), nil
+	}
+}
+
+func (c *converter) newGeomGeometryCollectionFromGEOSGeom(geosGeom *geos.Geom) (*geom.GeometryCollection, error) {
+	geosNumGeometries := geosGeom.NumGeometries()
+	if geosNumGeometries == 0 {
+		// Like go-geom's decoders, mark empty collections with a fixed layout.
+		return geom.NewGeometryCollection().MustSetLayout(geom.XY).SetSRID(c.targetSRID(geosGeom.SRID())), nil
+	}
+	geomGeometries := make([]geom.T, 0, geosNumGeometries)
+	for i := 0; i < geosNumGeometries; i++ {
+		geomT, err := c.newGeomTFromGEOSGeom(geosGeom.Geometry(i))
+		if err != nil {
+			return nil, withPathIndex(err, i)
+		}
+		// Like go-geom's decoders, only the collection has an SRID.
+		if _, err := geom.SetSRID(geomT, 0); err != nil {
+			return nil, withPathIndex(err, i)
+		}
+		geomGeometries = append(geomGeometries, geomT)
+	}
+	return geom.NewGeometryCollection().MustPush(geomGeometries...).SetSRID(c.targetSRID(geosGeom.SRID())), nil
+}
+
+// A geomFlatCoordsBuilder builds go-geom flat coordinates from GEOS coordinate
+// sequences.
+type geomFlatCoordsBuilder struct {
+	transform  TransformFunc
+	stride     int
+	flatCoords []float64
+}
+
+// appendGEOSCoordSeq appends the coordinates in geosCoordSeq. The stride is
+// determined by the first non-empty coordinate sequence. Subsequent
+// coordinates with a different number of dimensions are truncated or padded
+// with NaNs. If b has a transform then it is applied to each coordinate.
+func (b *geomFlatCoordsBuilder) appendGEOSCoordSeq(geosCoordSeq *geos.CoordSeq) {
+	if geosCoordSeq == nil {
+		return
+	}
+	geosFlatCoords, geosDimensions := geosCoordSeqFlatCoords(geosCoordSeq)
+	if len(geosFlatCoords) == 0 {
+		return
+	}
+	if b.stride == 0 {
+		b.stride = geosDimensions
+		if b.stride < 2 {
+			b.stride = 2
+		}
+		if b.stride > 4 {
+			b.stride = 4
+		}
+	}
+	start := len(b.flatCoords)
+	switch {
+	case geosDimensions == b.stride && b.flatCoords == nil:
+		// The flat coordinates were copied from GEOS, so they can be used
+		// directly.
+		b.flatCoords = geosFlatCoords
+	case geosDimensions == b.stride:
+		b.flatCoords = append(b.flatCoords, geosFlatCoords...)
+	default:
+		if b.flatCoords == nil {
+			b.flatCoords = make([]float64, 0, b.stride*len(geosFlatCoords)/geosDimensions)
+		}
+		for i := 0; i < len(geosFlatCoords); i += geosDimensions {
+			geosCoord := geosFlatCoords[i : i+geosDimensions]
+			if len(geosCoord) >= b.stride {
+				b.flatCoords = append(b.flatCoords, geosCoord[:b.stride]...)
+			} else {
+				b.flatCoords = append(b.flatCoords, geosCoord...)
+				for j := len(geosCoord); j < b.stride; j++ {
+					b.flatCoords = append(b.flatCoords, math.NaN())
+				}
+			}
+		}
+	}
+	if b.transform != nil {
+		for i := start; i < len(b.flatCoords); i += b.stride {
+			b.flatCoords[i], b.flatCoords[i+1] = b.transform(b.flatCoords[i], b.flatCoords[i+1])
+		}
+	}
+}
+
+// geosCoordSeqFlatCoords returns the flat coordinates of geosCoordSeq and the
+// number of ordinates in each coordinate. ToCoords copies the whole coordinate
+// sequence into a single array with one cgo call and returns slices of it, so
+// the array is returned directly instead of copying each coordinate again.
+func geosCoordSeqFlatCoords(geosCoordSeq *geos.CoordSeq) ([]float64, int) {
+	geosCoords := geosCoordSeq.ToCoords()
+	n := len(geosCoords)
+	if n == 0 {
+		return nil, 0
+	}
+	geosDimensions := len(geosCoords[0])
+	if flatLen := n * geosDimensions; geosDimensions > 0 && cap(geosCoords[0]) >= flatLen {
+		if geosFlatCoords := geosCoords[0][:flatLen:flatLen]; &geosFlatCoords[flatLen-geosDimensions] == &geosCoords[n-1][0] {
+			return geosFlatCoords, geosDimensions
+		}
+	}
+	geosFlatCoords := make([]float64, 0, n*geosDimensions)
+	for _, geosCoord := range geosCoords {
+		geosFlatCoords = append(geosFlatCoords, geosCoord...)
+	}
+	return geosFlatCoords, geosDimensions
+}
+
+// appendGEOSPolygon appends the coordinates of geosPolygon's rings and returns
+// the number of coordinates after each ring. Empty polygons have no rings.
+func (b *geomFlatCoordsBuilder) appendGEOSPolygon(geosPolygon *geos.Geom) []int {
+	if geosPolygon.IsEmpty() {
+		return nil
+	}
+	geosNumInteriorRings := geosPolygon.NumInteriorRings()
+	numCoords := make([]int, 0, 1+geosNumInteriorRings)
+	b.appendGEOSCoordSeq(geosPolygon.ExteriorRing().CoordSeq())
+	numCoords = append(numCoords, b.numCoords())
+	for i := 0; i < geosNumInteriorRings; i++ {
+		b.appendGEOSCoordSeq(geosPolygon.InteriorRing(i).CoordSeq())
+		numCoords = append(numCoords, b.numCoords())
+	}
+	return numCoords
+}
+
+// numCoords returns the number of coordinates appended so far.
+func (b *geomFlatCoordsBuilder) numCoords() int {
+	if b.stride == 0 {
+		return 0
+	}
+	return len(b.flatCoords) / b.stride
+}
+
+// build returns the layout and flat coordinates. GEOS represents missing Z and
+// M ordinates with NaNs, so Z and M ordinates that are NaN in every coordinate
+// are removed.
+func (b *geomFlatCoordsBuilder) build() (geom.Layout, []float64) {
+	if b.stride <= 2 {
+		return geom.XY, b.flatCoords
+	}
+	hasZ := !geomFlatCoordsAllNaN(b.flatCoords, b.stride, 2)
+	hasM := b.stride == 4 && !geomFlatCoordsAllNaN(b.flatCoords, b.stride, 3)
+	geomLayout := geomLayoutFromHasZM(hasZ, hasM)
+	geomStride := geomLayout.Stride()
+	if geomStride == b.stride {
+		return geomLayout, b.flatCoords
+	}
+	geomFlatCoords := b.flatCoords[:0]
+	for i := 0; i < len(b.flatCoords); i += b.stride {
+		geomFlatCoords = append(geomFlatCoords, b.flatCoords[i], b.flatCoords[i+1])
+		if hasZ {
+			geomFlatCoords = append(geomFlatCoords, b.flatCoords[i+2])
+		}
+		if hasM {
+			geomFlatCoords = append(geomFlatCoords, b.flatCoords[i+3])
+		}
+	}
+	return geomLayout, geomFlatCoords
+}
+
+// geomLayoutFromGEOSCoordSeq returns the layout of geosCoordSeq, ignoring Z and
+// M ordinates that are NaN in every coordinate.
+func geomLayoutFromGEOSCoordSeq(geosCoordSeq *geos.CoordSeq) geom.Layout {
+	geosDimensions := geosCoordSeq.Dimensions()
+	if geosDimensions <= 2 {
+		return geom.XY
+	}
+	geosFlatCoords, _ := geosCoordSeqFlatCoords(geosCoordSeq)
+	hasZ := !geomFlatCoordsAllNaN(geosFlatCoords, geosDimensions, 2)
+	hasM := geosDimensions > 3 && !geomFlatCoordsAllNaN(geosFlatCoords, geosDimensions, 3)
+	return geomLayoutFromHasZM(hasZ, hasM)
+}
+
+func geomLayoutFromHasZM(hasZ, hasM bool) geom.Layout {
+	switch {
+	case hasZ && hasM:
+		return geom.XYZM
+	case hasZ:
+		return geom.XYZ
+	case hasM:
+		return geom.XYM
+	default:
+		return geom.XY
+	}
+}
+
+// geomFlatCoordsAllNaN returns if the index-th ordinate of every coordinate in
+// geomFlatCoords is NaN.
+func geomFlatCoordsAllNaN(geomFlatCoords []float64, stride, index int) bool {
+	for i := index; i < len(geomFlatCoords); i += stride {
+		if !math.IsNaN(geomFlatCoords[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// geomEndsFromNumCoords converts numCoords, the number of coordinates at each
+// end, to ends in flat coordinates with geomLayout, in place.
+func geomEndsFromNumCoords(numCoords []int, geomLayout geom.Layout) []int {
+	geomStride := geomLayout.Stride()
+	for i := range numCoords {
+		numCoords[i] *= geomStride
+	}
+	return numCoords
+}
@@ -2,156 +2,286 @@ package geobabel
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/paulmach/orb"
 	"github.com/twpayne/go-geom"
 )
 
-func NewGeomTFromOrbGeometry(orbGeometry orb.Geometry) geom.T {
+func NewGeomTFromOrbGeometry(orbGeometry orb.Geometry, options ...Option) geom.T {
+	geomT, err := NewGeomTFromOrbGeometryE(orbGeometry, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geomT
+}
+
+// NewGeomTFromOrbGeometryE returns a new geom.T converted from orbGeometry. It
+// returns a *ConvertError if orbGeometry cannot be converted.
+func NewGeomTFromOrbGeometryE(orbGeometry orb.Geometry, options ...Option) (geom.T, error) {
+	return newConverter(options).newGeomTFromOrbGeometryE(orbGeometry)
+}
+
+func (c *converter) newGeomTFromOrbGeometryE(orbGeometry orb.Geometry) (geom.T, error) {
+	orbGeometry, err := c.validateOrbGeometry(orbGeometry)
+	if err != nil {
+		return nil, err
+	}
+	geomT, err := c.newGeomTFromOrbGeometry(orbGeometry)
+	if err != nil {
+		return nil, err
+	}
+	geomT, err = c.validateGeomTWithGEOS(geomT, fmt.Sprintf("%T", orbGeometry))
+	if err != nil {
+		return nil, err
+	}
+	return geom.SetSRID(geomT, c.srid)
+}
+
+func (c *converter) newGeomTFromOrbGeometry(orbGeometry orb.Geometry) (geom.T, error) {
 	switch orbGeometry := orbGeometry.(type) {
 	case orb.Point:
-		return NewGeomPointFromOrbPoint(orbGeometry)
+		return c.newGeomPointFromOrbPoint(orbGeometry), nil
 	case orb.LineString:
-		return NewGeomLineStringFromOrbLineString(orbGeometry)
+		return c.newGeomLineStringFromOrbLineString(orbGeometry), nil
 	case orb.Ring:
-		return NewGeomLinearRingFromOrbRing(orbGeometry)
+		return c.newGeomLinearRingFromOrbRing(orbGeometry), nil
 	case orb.Polygon:
-		return NewGeomPolygonFromOrbPolygon(orbGeometry)
+		return c.newGeomPolygonFromOrbPolygon(orbGeometry), nil
 	case orb.MultiPoint:
-		return NewGeomMultiPointFromOrbMultiPoint(orbGeometry)
+		return c.newGeomMultiPointFromOrbMultiPoint(orbGeometry), nil
 	case orb.MultiLineString:
-		return NewGeomMultiLineStringFromOrbMultiLineString(orbGeometry)
+		return c.newGeomMultiLineStringFromOrbMultiLineString(orbGeometry), nil
 	case orb.MultiPolygon:
-		return NewGeomMultiPolygonFromOrbMultiPolygon(orbGeometry)
+		return c.newGeomMultiPolygonFromOrbMultiPolygon(orbGeometry), nil
+	case orb.Bound:
+		return c.newGeomPolygonFromOrbPolygon(orbPolygonFromOrbBound(orbGeometry)), nil
 	case orb.Collection:
-		return NewGeomGeometryCollectionFromOrbCollection(orbGeometry)
+		geomGeometryCollection, err := c.newGeomGeometryCollectionFromOrbCollection(orbGeometry)
+		if err != nil {
+			return nil, err
+		}
+		return geomGeometryCollection, nil
 	default:
-		panic(fmt.Sprintf("%T: unsupported orb type", orbGeometry))
+		return nil, newUnsupportedTypeError(fmt.Sprintf("%T", orbGeometry))
 	}
 }
 
-func NewGeomPointFromOrbPoint(orbPoint orb.Point) *geom.Point {
-	geomFlatCoords := GeomFlatCoordsFromOrbPoint(orbPoint)
+func NewGeomPointFromOrbPoint(orbPoint orb.Point, options ...Option) *geom.Point {
+	c := newConverter(options)
+	return c.newGeomPointFromOrbPoint(orbPoint).SetSRID(c.srid)
+}
+
+func NewGeomLineStringFromOrbLineString(orbLineString orb.LineString, options ...Option) *geom.LineString {
+	c := newConverter(options)
+	return c.newGeomLineStringFromOrbLineString(orbLineString).SetSRID(c.srid)
+}
+
+func NewGeomLinearRingFromOrbRing(orbRing orb.Ring, options ...Option) *geom.LinearRing {
+	c := newConverter(options)
+	return c.newGeomLinearRingFromOrbRing(orbRing).SetSRID(c.srid)
+}
+
+func NewGeomPolygonFromOrbPolygon(orbPolygon orb.Polygon, options ...Option) *geom.Polygon {
+	c := newConverter(options)
+	return c.newGeomPolygonFromOrbPolygon(orbPolygon).SetSRID(c.srid)
+}
+
+func NewGeomMultiPointFromOrbMultiPoint(orbMultiPoint orb.MultiPoint, options ...Option) *geom.MultiPoint {
+	c := newConverter(options)
+	return c.newGeomMultiPointFromOrbMultiPoint(orbMultiPoint).SetSRID(c.srid)
+}
+
+func NewGeomMultiLineStringFromOrbMultiLineString(orbMultiLineString orb.MultiLineString, options ...Option) *geom.MultiLineString {
+	c := newConverter(options)
+	return c.newGeomMultiLineStringFromOrbMultiLineString(orbMultiLineString).SetSRID(c.srid)
+}
+
+func NewGeomMultiPolygonFromOrbMultiPolygon(orbMultiPolygon orb.MultiPolygon, options ...Option) *geom.MultiPolygon {
+	c := newConverter(options)
+	return c.newGeomMultiPolygonFromOrbMultiPolygon(orbMultiPolygon).SetSRID(c.srid)
+}
+
+func NewGeomGeometryCollectionFromOrbCollection(orbCollection orb.Collection, options ...Option) *geom.GeometryCollection {
+	c := newConverter(options)
+	geomGeometryCollection, err := c.newGeomGeometryCollectionFromOrbCollection(orbCollection)
+	if err != nil {
+		panic(err)
+	}
+	return geomGeometryCollection.SetSRID(c.srid)
+}
+
+func (c *converter) newGeomPointFromOrbPoint(orbPoint orb.Point) *geom.Point {
+	if orbPointIsEmpty(orbPoint) {
+		return geom.NewPointEmpty(geom.XY)
+	}
+	geomFlatCoords := c.geomFlatCoordsFromOrbPoint(orbPoint)
 	return geom.NewPointFlat(geom.XY, geomFlatCoords)
 }
 
-func NewGeomLineStringFromOrbLineString(orbLineString orb.LineString) *geom.LineString {
-	geomFlatCoords := GeomFlatCoordsFromOrbLineString(orbLineString)
+func (c *converter) newGeomLineStringFromOrbLineString(orbLineString orb.LineString) *geom.LineString {
+	geomFlatCoords := c.geomFlatCoordsFromOrbLineString(orbLineString)
 	return geom.NewLineStringFlat(geom.XY, geomFlatCoords)
 }
 
-func NewGeomLinearRingFromOrbRing(orbRing orb.Ring) *geom.LinearRing {
-	geomFlatCoords := GeomFlatCoordsFromOrbRing(orbRing)
+func (c *converter) newGeomLinearRingFromOrbRing(orbRing orb.Ring) *geom.LinearRing {
+	geomFlatCoords := c.geomFlatCoordsFromOrbRing(orbRing)
 	return geom.NewLinearRingFlat(geom.XY, geomFlatCoords)
 }
 
-func NewGeomPolygonFromOrbPolygon(orbPolygon orb.Polygon) *geom.Polygon {
-	geomFlatCoords, geomEnds := GeomFlatCoordsFromOrbPolygon(orbPolygon)
+func (c *converter) newGeomPolygonFromOrbPolygon(orbPolygon orb.Polygon) *geom.Polygon {
+	if len(orbPolygon) == 0 {
+		return geom.NewPolygon(geom.XY)
+	}
+	geomFlatCoords, geomEnds := c.geomFlatCoordsFromOrbPolygon(orbPolygon)
 	return geom.NewPolygonFlat(geom.XY, geomFlatCoords, geomEnds)
 }
 
-func NewGeomMultiPointFromOrbMultiPoint(orbMultiPoint orb.MultiPoint) *geom.MultiPoint {
-	geomFlatCoords := GeomFlatCoordsFromOrbMultiPoint(orbMultiPoint)
-	return geom.NewMultiPointFlat(geom.XY, geomFlatCoords)
+func (c *converter) newGeomMultiPointFromOrbMultiPoint(orbMultiPoint orb.MultiPoint) *geom.MultiPoint {
+	if len(orbMultiPoint) == 0 {
+		return geom.NewMultiPoint(geom.XY)
+	}
+	geomFlatCoords := make([]float64, 0, 2*len(orbMultiPoint))
+	geomEnds := make([]int, 0, len(orbMultiPoint))
+	for _, orbPoint := range orbMultiPoint {
+		if !orbPointIsEmpty(orbPoint) {
+			geomFlatCoords = appendGeomFlatCoordsFromOrbPoint(geomFlatCoords, orbPoint, c.transform)
+		}
+		geomEnds = append(geomEnds, len(geomFlatCoords))
+	}
+	return geom.NewMultiPointFlat(geom.XY, geomFlatCoords, geom.NewMultiPointFlatOptionWithEnds(geomEnds))
 }
 
-func NewGeomMultiLineStringFromOrbMultiLineString(orbMultiLineString orb.MultiLineString) *geom.MultiLineString {
-	geomFlatCoords, geomEnds := GeomFlatCoordsFromOrbMultiLineString(orbMultiLineString)
+func (c *converter) newGeomMultiLineStringFromOrbMultiLineString(orbMultiLineString orb.MultiLineString) *geom.MultiLineString {
+	if len(orbMultiLineString) == 0 {
+		return geom.NewMultiLineString(geom.XY)
+	}
+	geomFlatCoords, geomEnds := c.geomFlatCoordsFromOrbMultiLineString(orbMultiLineString)
 	return geom.NewMultiLineStringFlat(geom.XY, geomFlatCoords, geomEnds)
 }
 
-func NewGeomMultiPolygonFromOrbMultiPolygon(orbMultiPolygon orb.MultiPolygon) *geom.MultiPolygon {
-	geomFlatCoords, geomEndss := GeomFlatCoordsFromOrbMultiPolygon(orbMultiPolygon)
+func (c *converter) newGeomMultiPolygonFromOrbMultiPolygon(orbMultiPolygon orb.MultiPolygon) *geom.MultiPolygon {
+	if len(orbMultiPolygon) == 0 {
+		return geom.NewMultiPolygon(geom.XY)
+	}
+	geomFlatCoords, geomEndss := c.geomFlatCoordsFromOrbMultiPolygon(orbMultiPolygon)
 	return geom.NewMultiPolygonFlat(geom.XY, geomFlatCoords, geomEndss)
 }
 
-func NewGeomGeometryCollectionFromOrbCollection(orbCollection orb.Collection) *geom.GeometryCollection {
+func (c *converter) newGeomGeometryCollectionFromOrbCollection(orbCollection orb.Collection) (*geom.GeometryCollection, error) {
+	if len(orbCollection) == 0 {
+		// Like go-geom's decoders, mark empty collections with a fixed layout.
+		return geom.NewGeometryCollection().MustSetLayout(geom.XY), nil
+	}
 	geomGeometries := make([]geom.T, 0, len(orbCollection))
-	for _, orbGeometery := range orbCollection {
-		geomT := NewGeomTFromOrbGeometry(orbGeometery)
+	for i, orbGeometery := range orbCollection {
+		geomT, err := c.newGeomTFromOrbGeometry(orbGeometery)
+		if err != nil {
+			return nil, withPathIndex(err, i)
+		}
 		geomGeometries = append(geomGeometries, geomT)
 	}
-	return geom.NewGeometryCollection().MustPush(geomGeometries...)
+	return geom.NewGeometryCollection().MustPush(geomGeometries...), nil
+}
+
+func GeomFlatCoordsFromOrbPoint(orbPoint orb.Point, options ...Option) []float64 {
+	return newConverter(options).geomFlatCoordsFromOrbPoint(orbPoint)
 }
 
-func GeomFlatCoordsFromOrbPoint(orbPoint orb.Point) []float64 {
-	return []float64{orbPoint.X(), orbPoint.Y()}
+func GeomFlatCoordsFromOrbLineString(orbLineString orb.LineString, options ...Option) []float64 {
+	return newConverter(options).geomFlatCoordsFromOrbLineString(orbLineString)
 }
 
-func GeomFlatCoordsFromOrbLineString(orbLineString orb.LineString) []float64 {
-	geomFlatCoords := make([]float64, 0, 2*len(orbLineString))
-	for _, orbPoint := range orbLineString {
-		geomFlatCoords = append(geomFlatCoords, orbPoint[0], orbPoint[1])
+func GeomFlatCoordsFromOrbRing(orbRing orb.Ring, options ...Option) []float64 {
+	return newConverter(options).geomFlatCoordsFromOrbRing(orbRing)
+}
+
+func GeomFlatCoordsFromOrbPolygon(orbPolygon orb.Polygon, options ...Option) ([]float64, []int) {
+	return newConverter(options).geomFlatCoordsFromOrbPolygon(orbPolygon)
+}
+
+func GeomFlatCoordsFromOrbMultiPoint(orbMultiPoint orb.MultiPoint, options ...Option) []float64 {
+	return newConverter(options).geomFlatCoordsFromOrbMultiPoint(orbMultiPoint)
+}
+
+func GeomFlatCoordsFromOrbMultiLineString(orbMultiLineString orb.MultiLineString, options ...Option) ([]float64, []int) {
+	return newConverter(options).geomFlatCoordsFromOrbMultiLineString(orbMultiLineString)
+}
+
+func GeomFlatCoordsFromOrbMultiPolygon(orbMultiPolygon orb.MultiPolygon, options ...Option) ([]float64, [][]int) {
+	return newConverter(options).geomFlatCoordsFromOrbMultiPolygon(orbMultiPolygon)
+}
+
+func (c *converter) geomFlatCoordsFromOrbPoint(orbPoint orb.Point) []float64 {
+	return appendGeomFlatCoordsFromOrbPoint(make([]float64, 0, 2), orbPoint, c.transform)
+}
+
+func (c *converter) geomFlatCoordsFromOrbLineString(orbLineString orb.LineString) []float64 {
+	geomFlatCoords := appendGeomFlatCoordsFromOrbPoints(make([]float64, 0, 2*len(orbLineString)), orbLineString, c.transform)
+	if c.removeRepeatedPoints() {
+		geomFlatCoords = geomRemoveRepeatedPoints(geomFlatCoords, 2, minLineStringPoints)
 	}
 	return geomFlatCoords
 }
 
-func GeomFlatCoordsFromOrbRing(orbRing orb.Ring) []float64 {
-	geomFlatCoords := make([]float64, 0, 2*len(orbRing))
-	for _, orbPoint := range orbRing {
-		geomFlatCoords = append(geomFlatCoords, orbPoint[0], orbPoint[1])
+func (c *converter) geomFlatCoordsFromOrbRing(orbRing orb.Ring) []float64 {
+	geomFlatCoords := appendGeomFlatCoordsFromOrbPoints(make([]float64, 0, 2*len(orbRing)), orbRing, c.transform)
+	if c.removeRepeatedPoints() {
+		geomFlatCoords = geomRemoveRepeatedPoints(geomFlatCoords, 2, minRingPoints)
 	}
 	return geomFlatCoords
 }
 
-func GeomFlatCoordsFromOrbPolygon(orbPolygon orb.Polygon) ([]float64, []int) {
-	geomFlatCoordsLen := 0
-	geomEnds := make([]int, 0, len(orbPolygon))
+func (c *converter) geomFlatCoordsFromOrbPolygon(orbPolygon orb.Polygon) ([]float64, []int) {
+	orbNumPoints := 0
 	for _, orbRing := range orbPolygon {
-		geomFlatCoordsLen += 2 * len(orbRing)
-		geomEnds = append(geomEnds, geomFlatCoordsLen)
+		orbNumPoints += len(orbRing)
 	}
-	geomFlatCoords := make([]float64, 0, geomFlatCoordsLen)
-	for _, orbRing := range orbPolygon {
-		for _, orbPoint := range orbRing {
-			geomFlatCoords = append(geomFlatCoords, orbPoint[0], orbPoint[1])
-		}
+	geomFlatCoords, geomEnds := appendGeomFlatCoordsFromOrbPolygon(make([]float64, 0, 2*orbNumPoints), make([]int, 0, len(orbPolygon)), orbPolygon, c.transform)
+	if c.removeRepeatedPoints() {
+		geomFlatCoords = geomFlatCoords[:geomRemoveRepeatedPointsEnds(geomFlatCoords, 0, 0, geomEnds, 2, minRingPoints)]
 	}
+	geomOrientPolygonFlatCoords(geomFlatCoords, 0, geomEnds, 2, c.orientation)
 	return geomFlatCoords, geomEnds
 }
 
-func GeomFlatCoordsFromOrbMultiPoint(orbMultiPoint orb.MultiPoint) []float64 {
-	geomFlatCoords := make([]float64, 0, 2*len(orbMultiPoint))
-	for _, orbPoint := range orbMultiPoint {
-		geomFlatCoords = append(geomFlatCoords, orbPoint[0], orbPoint[1])
-	}
-	return geomFlatCoords
+func (c *converter) geomFlatCoordsFromOrbMultiPoint(orbMultiPoint orb.MultiPoint) []float64 {
+	return appendGeomFlatCoordsFromOrbPoints(make([]float64, 0, 2*len(orbMultiPoint)), orbMultiPoint, c.transform)
 }
 
-func GeomFlatCoordsFromOrbMultiLineString(orbMultiLineString orb.MultiLineString) ([]float64, []int) {
-	geomFlatCoordsLen := 0
-	geomEnds := make([]int, 0, len(orbMultiLineString))
+func (c *converter) geomFlatCoordsFromOrbMultiLineString(orbMultiLineString orb.MultiLineString) ([]float64, []int) {
+	orbNumPoints := 0
 	for _, orbLineString := range orbMultiLineString {
-		geomFlatCoordsLen += 2 * len(orbLineString)
-		geomEnds = append(geomEnds, geomFlatCoordsLen)
+		orbNumPoints += len(orbLineString)
 	}
-	geomFlatCoords := make([]float64, 0, geomFlatCoordsLen)
-	for _, orbLineString := range orbMultiLineString {
-		for _, orbPoint := range orbLineString {
-			geomFlatCoords = append(geomFlatCoords, orbPoint[0], orbPoint[1])
-		}
+	geomFlatCoords, geomEnds := appendGeomFlatCoordsFromOrbMultiLineString(make([]float64, 0, 2*orbNumPoints), make([]int, 0, len(orbMultiLineString)), orbMultiLineString, c.transform)
+	if c.removeRepeatedPoints() {
+		geomFlatCoords = geomFlatCoords[:geomRemoveRepeatedPointsEnds(geomFlatCoords, 0, 0, geomEnds, 2, minLineStringPoints)]
 	}
 	return geomFlatCoords, geomEnds
 }
 
-func GeomFlatCoordsFromOrbMultiPolygon(orbMultiPolygon orb.MultiPolygon) ([]float64, [][]int) {
-	geomFlatCoordsLen := 0
+func (c *converter) geomFlatCoordsFromOrbMultiPolygon(orbMultiPolygon orb.MultiPolygon) ([]float64, [][]int) {
+	orbNumPoints := 0
 	geomEndss := make([][]int, 0, len(orbMultiPolygon))
 	for _, orbPolygon := range orbMultiPolygon {
-		geomEnds := make([]int, 0, len(orbPolygon))
 		for _, orbRing := range orbPolygon {
-			geomFlatCoordsLen += 2 * len(orbRing)
-			geomEnds = append(geomEnds, geomFlatCoordsLen)
+			orbNumPoints += len(orbRing)
 		}
-		geomEndss = append(geomEndss, geomEnds)
+		geomEndss = append(geomEndss, make([]int, 0, len(orbPolygon)))
 	}
-	geomFlatCoords := make([]float64, 0, geomFlatCoordsLen)
-	for _, orbPolygon := range orbMultiPolygon {
-		for _, orbRing := range orbPolygon {
-			for _, orbPoint := range orbRing {
-				geomFlatCoords = append(geomFlatCoords, orbPoint[0], orbPoint[1])
-			}
-		}
+	// Reuse the preallocated ends in geomEndss's spare capacity.
+	geomFlatCoords, geomEndss := appendGeomFlatCoordsFromOrbMultiPolygon(make([]float64, 0, 2*orbNumPoints), geomEndss[:0], orbMultiPolygon, c.transform)
+	if c.removeRepeatedPoints() {
+		geomFlatCoords = geomRemoveRepeatedPointsEndss(geomFlatCoords, geomEndss, 2)
 	}
+	geomOrientMultiPolygonFlatCoords(geomFlatCoords, geomEndss, 2, c.orientation)
 	return geomFlatCoords, geomEndss
 }
+
+// orbPointIsEmpty returns if orbPoint represents an empty point. orb has no
+// empty point type, so, like orb's WKB decoder, empty points are represented
+// by NaN coordinates.
+func orbPointIsEmpty(orbPoint orb.Point) bool {
+	return math.IsNaN(orbPoint[0]) && math.IsNaN(orbPoint[1])
+}
@@ -0,0 +1,322 @@
+package geobabel
+
+import (
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geos"
+)
+
+func NewGEOSGeomFromGeomT(geosContext *geos.Context, geomT geom.T, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromGeomTE(geosContext, geomT, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromGeomTE returns a new *geos.Geom converted from geomT. It
+// returns a *ConvertError if geomT cannot be converted, including when GEOS
+// rejects the converted geometry.
+func NewGEOSGeomFromGeomTE(geosContext *geos.Context, geomT geom.T, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromGeomTE(geosContext, geomT)
+}
+
+func NewGEOSGeomFromGeomPoint(geosContext *geos.Context, geomPoint *geom.Point, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromGeomPointE(geosContext, geomPoint, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromGeomPointE returns a new point converted from geomPoint. It
+// returns a *ConvertError if geomPoint cannot be converted, including when GEOS
+// rejects the converted geometry.
+func NewGEOSGeomFromGeomPointE(geosContext *geos.Context, geomPoint *geom.Point, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromGeomTE(geosContext, geomPoint)
+}
+
+func NewGEOSGeomFromGeomLineString(geosContext *geos.Context, geomLineString *geom.LineString, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromGeomLineStringE(geosContext, geomLineString, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromGeomLineStringE returns a new line string converted from
+// geomLineString. It returns a *ConvertError if geomLineString cannot be
+// converted, including when GEOS rejects the converted geometry.
+func NewGEOSGeomFromGeomLineStringE(geosContext *geos.Context, geomLineString *geom.LineString, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromGeomTE(geosContext, geomLineString)
+}
+
+func NewGEOSGeomFromGeomLinearRing(geosContext *geos.Context, geomLinearRing *geom.LinearRing, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromGeomLinearRingE(geosContext, geomLinearRing, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromGeomLinearRingE returns a new linear ring converted from
+// geomLinearRing. It returns a *ConvertError if geomLinearRing cannot be
+// converted, including when GEOS rejects the converted geometry.
+func NewGEOSGeomFromGeomLinearRingE(geosContext *geos.Context, geomLinearRing *geom.LinearRing, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromGeomTE(geosContext, geomLinearRing)
+}
+
+func NewGEOSGeomFromGeomPolygon(geosContext *geos.Context, geomPolygon *geom.Polygon, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromGeomPolygonE(geosContext, geomPolygon, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromGeomPolygonE returns a new polygon converted from geomPolygon.
+// It returns a *ConvertError if geomPolygon cannot be converted, including when
+// GEOS rejects the converted geometry.
+func NewGEOSGeomFromGeomPolygonE(geosContext *geos.Context, geomPolygon *geom.Polygon, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromGeomTE(geosContext, geomPolygon)
+}
+
+func NewGEOSGeomFromGeomMultiPoint(geosContext *geos.Context, geomMultiPoint *geom.MultiPoint, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromGeomMultiPointE(geosContext, geomMultiPoint, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromGeomMultiPointE returns a new multipoint converted from
+// geomMultiPoint. It returns a *ConvertError if geomMultiPoint cannot be
+// converted, including when GEOS rejects the converted geometry.
+func NewGEOSGeomFromGeomMultiPointE(geosContext *geos.Context, geomMultiPoint *geom.MultiPoint, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromGeomTE(geosContext, geomMultiPoint)
+}
+
+func NewGEOSGeomFromGeomMultiLineString(geosContext *geos.Context, geomMultiLineString *geom.MultiLineString, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromGeomMultiLineStringE(geosContext, geomMultiLineString, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromGeomMultiLineStringE returns a new multilinestring converted
+// from geomMultiLineString. It returns a *ConvertError if geomMultiLineString
+// cannot be converted, including when GEOS rejects the converted geometry.
+func NewGEOSGeomFromGeomMultiLineStringE(geosContext *geos.Context, geomMultiLineString *geom.MultiLineString, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromGeomTE(geosContext, geomMultiLineString)
+}
+
+func NewGEOSGeomFromGeomMultiPolygon(geosContext *geos.Context, geomMultiPolygon *geom.MultiPolygon, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromGeomMultiPolygonE(geosContext, geomMultiPolygon, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromGeomMultiPolygonE returns a new multipolygon converted from
+// geomMultiPolygon. It returns a *ConvertError if geomMultiPolygon cannot be
+// converted, including when GEOS rejects the converted geometry.
+func NewGEOSGeomFromGeomMultiPolygonE(geosContext *geos.Context, geomMultiPolygon *geom.MultiPolygon, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromGeomTE(geosContext, geomMultiPolygon)
+}
+
+func NewGEOSGeomFromGeomGeometryCollection(geosContext *geos.Context, geomGeometryCollection *geom.GeometryCollection, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromGeomGeometryCollectionE(geosContext, geomGeometryCollection, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromGeomGeometryCollectionE returns a new geometry collection
+// converted from geomGeometryCollection. It returns a *ConvertError if
+// geomGeometryCollection cannot be converted, including when GEOS rejects the
+// converted geometry.
+func NewGEOSGeomFromGeomGeometryCollectionE(geosContext *geos.Context, geomGeometryCollection *geom.GeometryCollection, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromGeomTE(geosContext, geomGeometryCollection)
+}
+
+func (c *converter) newGEOSGeomFromGeomTE(geosContext *geos.Context, geomT geom.T) (geosGeom *geos.Geom, err error) {
+	defer recoverGEOSError(&err, fmt.Sprintf("%T", geomT))
+	if geomT, err = c.validateGeomT(geomT); err != nil {
+		return nil, err
+	}
+	c.geosCoords.reset()
+	geosGeom, err = c.newGEOSGeomFromGeomT(geosContext, geomT)
+	if err != nil {
+		return nil, err
+	}
+	if geosGeom, err = c.validateTargetGEOSGeom(geosContext, geosGeom, fmt.Sprintf("%T", geomT)); err != nil {
+		return nil, err
+	}
+	geosGeom = c.setGEOSPrecision(geosGeom)
+	if srid := c.targetSRID(geomT.SRID()); srid != 0 {
+		geosGeom.SetSRID(srid)
+	}
+	return geosGeom, nil
+}
+
+func (c *converter) newGEOSGeomFromGeomT(geosContext *geos.Context, geomT geom.T) (*geos.Geom, error) {
+	// Geometry collections are checked element by element.
+	geosDimensions, ok := c.geosDimensionsFromGeomLayout(geomT.Layout())
+	if _, isCollection := geomT.(*geom.GeometryCollection); !ok && !isCollection {
+		return nil, newUnsupportedLayoutError(fmt.Sprintf("%T", geomT), geomT.Layout().String())
+	}
+	switch geomT := geomT.(type) {
+	case *geom.Point:
+		if geomT.Empty() {
+			return geosContext.NewEmptyPoint(), nil
+		}
+		return geosContext.NewPoint(c.geosCoords.appendGeomFlatCoords(geomT.FlatCoords(), geomT.Layout(), geosDimensions, c.transform)[0]), nil
+	case *geom.LineString:
+		geosCoords := c.geosCoords.appendGeomFlatCoords(geomT.FlatCoords(), geomT.Layout(), geosDimensions, c.transform)
+		if c.removeRepeatedPoints() {
+			geosCoords = geosRemoveRepeatedCoords(geosCoords, minLineStringPoints)
+		}
+		return newGEOSLineString(geosContext, geosCoords), nil
+	case *geom.LinearRing:
+		geosCoords := c.geosCoords.appendGeomFlatCoords(geomT.FlatCoords(), geomT.Layout(), geosDimensions, c.transform)
+		if c.removeRepeatedPoints() {
+			geosCoords = geosRemoveRepeatedCoords(geosCoords, minRingPoints)
+		}
+		return newGEOSLinearRing(geosContext, geosCoords), nil
+	case *geom.Polygon:
+		geosCoordss := c.geosCoords.appendGeomFlatCoordsEnds(geomT.FlatCoords(), 0, geomT.Ends(), geomT.Layout(), geosDimensions, c.transform)
+		if c.removeRepeatedPoints() {
+			geosRemoveRepeatedCoordss(geosCoordss, minRingPoints)
+		}
+		geosOrientPolygonCoords(geosCoordss, c.orientation)
+		return newGEOSPolygon(geosContext, geosCoordss), nil
+	case *geom.MultiPoint:
+		geomNumPoints := geomT.NumPoints()
+		geosPoints := make([]*geos.Geom, 0, geomNumPoints)
+		for i := 0; i < geomNumPoints; i++ {
+			geomCoord := geomT.Coord(i)
+			if geomCoord == nil {
+				geosPoints = append(geosPoints, geosContext.NewEmptyPoint())
+				continue
+			}
+			geosPoint := geosContext.NewPoint(c.geosCoords.appendGeomFlatCoords(geomCoord, geomT.Layout(), geosDimensions, c.transform)[0])
+			geosPoints = append(geosPoints, geosPoint)
+		}
+		return geosContext.NewCollection(geos.TypeIDMultiPoint, geosPoints), nil
+	case *geom.MultiLineString:
+		geosCoordss := c.geosCoords.appendGeomFlatCoordsEnds(geomT.FlatCoords(), 0, geomT.Ends(), geomT.Layout(), geosDimensions, c.transform)
+		if c.removeRepeatedPoints() {
+			geosRemoveRepeatedCoordss(geosCoordss, minLineStringPoints)
+		}
+		geosLineStrings := make([]*geos.Geom, 0, len(geosCoordss))
+		for _, geosCoords := range geosCoordss {
+			geosLineString := newGEOSLineString(geosContext, geosCoords)
+			geosLineStrings = append(geosLineStrings, geosLineString)
+		}
+		return geosContext.NewCollection(geos.TypeIDMultiLineString, geosLineStrings), nil
+	case *geom.MultiPolygon:
+		geomFlatCoords := geomT.FlatCoords()
+		geomEndss := geomT.Endss()
+		geosPolygons := make([]*geos.Geom, 0, len(geomEndss))
+		geomStart := 0
+		for _, geomEnds := range geomEndss {
+			geosCoordss := c.geosCoords.appendGeomFlatCoordsEnds(geomFlatCoords, geomStart, geomEnds, geomT.Layout(), geosDimensions, c.transform)
+			if c.removeRepeatedPoints() {
+				geosRemoveRepeatedCoordss(geosCoordss, minRingPoints)
+			}
+			geosOrientPolygonCoords(geosCoordss, c.orientation)
+			geosPolygon := newGEOSPolygon(geosContext, geosCoordss)
+			geosPolygons = append(geosPolygons, geosPolygon)
+			if len(geomEnds) > 0 {
+				geomStart = geomEnds[len(geomEnds)-1]
+			}
+		}
+		return geosContext.NewCollection(geos.TypeIDMultiPolygon, geosPolygons), nil
+	case *geom.GeometryCollection:
+		geosGeoms := make([]*geos.Geom, 0, geomT.NumGeoms())
+		for i, geomGeom := range geomT.Geoms() {
+			geosGeom, err := c.newGEOSGeomFromGeomT(geosContext, geomGeom)
+			if err != nil {
+				return nil, withPathIndex(err, i)
+			}
+			geosGeoms = append(geosGeoms, geosGeom)
+		}
+		return geosContext.NewCollection(geos.TypeIDGeometryCollection, geosGeoms), nil
+	default:
+		return nil, newUnsupportedTypeError(fmt.Sprintf("%T", geomT))
+	}
+}
+
+// geosSupportsM is whether GEOS coordinate sequences can store M ordinates.
+var geosSupportsM = geos.VersionMajor > 3 || geos.VersionMajor == 3 && geos.VersionMinor >= 12
+
+// geosDimensionsFromGeomLayout returns the number of ordinates in a GEOS
+// coordinate sequence that represents a geometry with layout geomLayout. GEOS
+// coordinate sequences cannot represent M without Z, so XYM geometries are
+// either converted to XY or, with ZMPolicyKeep, to XYZM with NaN Z ordinates.
+// It returns false if c's ZM policy does not allow the conversion.
+func (c *converter) geosDimensionsFromGeomLayout(geomLayout geom.Layout) (int, bool) {
+	switch geomLayout {
+	case geom.XYZ:
+		return 3, true
+	case geom.XYM:
+		switch {
+		case c.zmPolicy == ZMPolicyDrop:
+			return 2, true
+		case c.zmPolicy == ZMPolicyKeep && geosSupportsM:
+			return 4, true
+		default:
+			return 0, false
+		}
+	case geom.XYZM:
+		switch {
+		case geosSupportsM:
+			return 4, true
+		case c.zmPolicy == ZMPolicyDrop:
+			return 3, true
+		default:
+			return 0, false
+		}
+	default:
+		return 2, true
+	}
+}
+
+// newGEOSLineString returns a new line string populated with geosCoords, which
+// may be empty.
+func newGEOSLineString(geosContext *geos.Context, geosCoords [][]float64) *geos.Geom {
+	if len(geosCoords) == 0 {
+		return geosContext.NewEmptyLineString()
+	}
+	return geosContext.NewLineString(geosCoords)
+}
+
+// newGEOSLinearRing returns a new linear ring populated with geosCoords, which
+// may be empty. GEOS has no constructor for empty linear rings, so the exterior
+// ring of an empty polygon is used instead.
+func newGEOSLinearRing(geosContext *geos.Context, geosCoords [][]float64) *geos.Geom {
+	if len(geosCoords) == 0 {
+		return geosContext.NewEmptyPolygon().ExteriorRing().Clone()
+	}
+	return geosContext.NewLinearRing(geosCoords)
+}
+
+// newGEOSPolygon returns a new polygon populated with geosCoordss. A polygon
+// with an empty exterior ring is empty. Empty interior rings are removed from
+// geosCoordss in place.
+func newGEOSPolygon(geosContext *geos.Context, geosCoordss [][][]float64) *geos.Geom {
+	if len(geosCoordss) == 0 || len(geosCoordss[0]) == 0 {
+		return geosContext.NewEmptyPolygon()
+	}
+	nonEmptyGEOSCoordss := geosCoordss[:1]
+	for _, geosCoords := range geosCoordss[1:] {
+		if len(geosCoords) != 0 {
+			nonEmptyGEOSCoordss = append(nonEmptyGEOSCoordss, geosCoords)
+		}
+	}
+	return geosContext.NewPolygon(nonEmptyGEOSCoordss)
+}
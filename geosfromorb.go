@@ -7,73 +7,287 @@ import (
 	"github.com/twpayne/go-geos"
 )
 
-func NewGEOSGeomFromOrbGeometry(geosContext *geos.Context, orbGeometry orb.Geometry) *geos.Geom {
+// orb geometries are converted to GEOS by copying their coordinates, once, into
+// the converter's contiguous buffers and creating GEOS coordinate sequences
+// directly from them, like geom.Ts. The buffers are reused between
+// conversions, so there are no allocations per point or per ring.
+
+func NewGEOSGeomFromOrbGeometry(geosContext *geos.Context, orbGeometry orb.Geometry, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromOrbGeometryE(geosContext, orbGeometry, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromOrbGeometryE returns a new *geos.Geom converted from
+// orbGeometry. It returns a *ConvertError if orbGeometry cannot be converted,
+// including when GEOS rejects the converted geometry.
+func NewGEOSGeomFromOrbGeometryE(geosContext *geos.Context, orbGeometry orb.Geometry, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromOrbGeometryE(geosContext, orbGeometry)
+}
+
+func NewGEOSGeomsFromOrbGeometries(geosContext *geos.Context, orbGeometries []orb.Geometry, options ...Option) []*geos.Geom {
+	geosGeoms, err := NewGEOSGeomsFromOrbGeometriesE(geosContext, orbGeometries, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeoms
+}
+
+// NewGEOSGeomsFromOrbGeometriesE returns new *geos.Geoms converted from
+// orbGeometries, reusing the same buffers for every geometry. It returns a
+// *ConvertError if any geometry cannot be converted.
+func NewGEOSGeomsFromOrbGeometriesE(geosContext *geos.Context, orbGeometries []orb.Geometry, options ...Option) ([]*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomsFromOrbGeometries(geosContext, orbGeometries)
+}
+
+func NewGEOSGeomFromOrbPoint(geosContext *geos.Context, orbPoint orb.Point, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromOrbPointE(geosContext, orbPoint, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromOrbPointE returns a new point converted from orbPoint. It
+// returns a *ConvertError if orbPoint cannot be converted, including when GEOS
+// rejects the converted geometry.
+func NewGEOSGeomFromOrbPointE(geosContext *geos.Context, orbPoint orb.Point, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromOrbGeometryE(geosContext, orbPoint)
+}
+
+func NewGEOSGeomFromOrbLineString(geosContext *geos.Context, orbLineString orb.LineString, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromOrbLineStringE(geosContext, orbLineString, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromOrbLineStringE returns a new line string converted from
+// orbLineString. It returns a *ConvertError if orbLineString cannot be
+// converted, including when GEOS rejects the converted geometry.
+func NewGEOSGeomFromOrbLineStringE(geosContext *geos.Context, orbLineString orb.LineString, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromOrbGeometryE(geosContext, orbLineString)
+}
+
+func NewGEOSGeomFromOrbRing(geosContext *geos.Context, orbRing orb.Ring, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromOrbRingE(geosContext, orbRing, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromOrbRingE returns a new linear ring converted from orbRing. It
+// returns a *ConvertError if orbRing cannot be converted, including when GEOS
+// rejects the converted geometry.
+func NewGEOSGeomFromOrbRingE(geosContext *geos.Context, orbRing orb.Ring, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromOrbGeometryE(geosContext, orbRing)
+}
+
+func NewGEOSGeomFromOrbPolygon(geosContext *geos.Context, orbPolygon orb.Polygon, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromOrbPolygonE(geosContext, orbPolygon, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromOrbPolygonE returns a new polygon converted from orbPolygon.
+// It returns a *ConvertError if orbPolygon cannot be converted, including when
+// GEOS rejects the converted geometry.
+func NewGEOSGeomFromOrbPolygonE(geosContext *geos.Context, orbPolygon orb.Polygon, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromOrbGeometryE(geosContext, orbPolygon)
+}
+
+func NewGEOSGeomFromOrbMultiPoint(geosContext *geos.Context, orbMultiPoint orb.MultiPoint, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromOrbMultiPointE(geosContext, orbMultiPoint, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromOrbMultiPointE returns a new multipoint converted from
+// orbMultiPoint. It returns a *ConvertError if orbMultiPoint cannot be
+// converted, including when GEOS rejects the converted geometry.
+func NewGEOSGeomFromOrbMultiPointE(geosContext *geos.Context, orbMultiPoint orb.MultiPoint, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromOrbGeometryE(geosContext, orbMultiPoint)
+}
+
+func NewGEOSGeomFromOrbMultiLineString(geosContext *geos.Context, orbMultiLineString orb.MultiLineString, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromOrbMultiLineStringE(geosContext, orbMultiLineString, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromOrbMultiLineStringE returns a new multilinestring converted
+// from orbMultiLineString. It returns a *ConvertError if orbMultiLineString
+// cannot be converted, including when GEOS rejects the converted geometry.
+func NewGEOSGeomFromOrbMultiLineStringE(geosContext *geos.Context, orbMultiLineString orb.MultiLineString, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromOrbGeometryE(geosContext, orbMultiLineString)
+}
+
+func NewGEOSGeomFromOrbMultiPolygon(geosContext *geos.Context, orbMultiPolygon orb.MultiPolygon, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromOrbMultiPolygonE(geosContext, orbMultiPolygon, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromOrbMultiPolygonE returns a new multipolygon converted from
+// orbMultiPolygon. It returns a *ConvertError if orbMultiPolygon cannot be
+// converted, including when GEOS rejects the converted geometry.
+func NewGEOSGeomFromOrbMultiPolygonE(geosContext *geos.Context, orbMultiPolygon orb.MultiPolygon, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromOrbGeometryE(geosContext, orbMultiPolygon)
+}
+
+func NewGEOSGeomFromOrbCollection(geosContext *geos.Context, orbCollection orb.Collection, options ...Option) *geos.Geom {
+	geosGeom, err := NewGEOSGeomFromOrbCollectionE(geosContext, orbCollection, options...)
+	if err != nil {
+		panic(err)
+	}
+	return geosGeom
+}
+
+// NewGEOSGeomFromOrbCollectionE returns a new geometry collection converted
+// from orbCollection. It returns a *ConvertError if orbCollection cannot be
+// converted, including when GEOS rejects the converted geometry.
+func NewGEOSGeomFromOrbCollectionE(geosContext *geos.Context, orbCollection orb.Collection, options ...Option) (*geos.Geom, error) {
+	return newConverter(options).newGEOSGeomFromOrbGeometryE(geosContext, orbCollection)
+}
+
+func (c *converter) newGEOSGeomFromOrbGeometryE(geosContext *geos.Context, orbGeometry orb.Geometry) (*geos.Geom, error) {
+	orbGeometry, err := c.validateOrbGeometry(orbGeometry)
+	if err != nil {
+		return nil, err
+	}
+	geosGeom, err := c.newGEOSGeomFromValidOrbGeometry(geosContext, orbGeometry)
+	if err != nil {
+		return nil, err
+	}
+	if c.srid != 0 {
+		geosGeom.SetSRID(c.srid)
+	}
+	return geosGeom, nil
+}
+
+// newGEOSGeomFromValidOrbGeometry returns a new *geos.Geom converted from
+// orbGeometry, which has already been validated.
+func (c *converter) newGEOSGeomFromValidOrbGeometry(geosContext *geos.Context, orbGeometry orb.Geometry) (geosGeom *geos.Geom, err error) {
+	defer recoverGEOSError(&err, fmt.Sprintf("%T", orbGeometry))
+	c.geosCoords.reset()
+	if geosGeom, err = c.newGEOSGeomFromOrbGeometry(geosContext, orbGeometry); err != nil {
+		return nil, err
+	}
+	if geosGeom, err = c.validateTargetGEOSGeom(geosContext, geosGeom, fmt.Sprintf("%T", orbGeometry)); err != nil {
+		return nil, err
+	}
+	return c.setGEOSPrecision(geosGeom), nil
+}
+
+// newGEOSGeomsFromOrbGeometries converts each of orbGeometries with c's
+// buffers. All geometries are validated before any is converted.
+func (c *converter) newGEOSGeomsFromOrbGeometries(geosContext *geos.Context, orbGeometries []orb.Geometry) ([]*geos.Geom, error) {
+	if c.validityPolicy != ValidityPolicyIgnore {
+		validOrbGeometries := make([]orb.Geometry, 0, len(orbGeometries))
+		for i, orbGeometry := range orbGeometries {
+			validOrbGeometry, err := c.validateOrbGeometry(orbGeometry)
+			if err != nil {
+				return nil, withPathIndex(err, i)
+			}
+			validOrbGeometries = append(validOrbGeometries, validOrbGeometry)
+		}
+		orbGeometries = validOrbGeometries
+	}
+	geosGeoms := make([]*geos.Geom, 0, len(orbGeometries))
+	for i, orbGeometry := range orbGeometries {
+		geosGeom, err := c.newGEOSGeomFromValidOrbGeometry(geosContext, orbGeometry)
+		if err != nil {
+			return nil, withPathIndex(err, i)
+		}
+		if c.srid != 0 {
+			geosGeom.SetSRID(c.srid)
+		}
+		geosGeoms = append(geosGeoms, geosGeom)
+	}
+	return geosGeoms, nil
+}
+
+func (c *converter) newGEOSGeomFromOrbGeometry(geosContext *geos.Context, orbGeometry orb.Geometry) (*geos.Geom, error) {
 	switch orbGeometry := orbGeometry.(type) {
 	case orb.Point:
-		return geosContext.NewPoint(geosCoordsFromOrbPoint(orbGeometry))
+		return c.newGEOSPointFromOrbPoint(geosContext, orbGeometry), nil
 	case orb.LineString:
-		return geosContext.NewLineString(geosCoordsFromOrbLineString(orbGeometry))
+		return c.newGEOSLineStringFromOrbLineString(geosContext, orbGeometry), nil
 	case orb.Ring:
-		return geosContext.NewLinearRing(geosCoordsFromOrbRing(orbGeometry))
+		geosCoords := c.geosCoords.appendOrbPoints(orbGeometry, c.transform)
+		if c.removeRepeatedPoints() {
+			geosCoords = geosRemoveRepeatedCoords(geosCoords, minRingPoints)
+		}
+		return newGEOSLinearRing(geosContext, geosCoords), nil
 	case orb.Polygon:
-		return geosContext.NewPolygon(geosCoordsFromOrbPolygon(orbGeometry))
+		return c.newGEOSPolygonFromOrbPolygon(geosContext, orbGeometry), nil
 	case orb.MultiPoint:
 		geosPoints := make([]*geos.Geom, 0, len(orbGeometry))
 		for _, orbPoint := range orbGeometry {
-			geosPoint := NewGEOSGeomFromOrbGeometry(geosContext, orbPoint)
-			geosPoints = append(geosPoints, geosPoint)
+			geosPoints = append(geosPoints, c.newGEOSPointFromOrbPoint(geosContext, orbPoint))
 		}
-		return geosContext.NewCollection(geos.TypeIDMultiPoint, geosPoints)
+		return geosContext.NewCollection(geos.TypeIDMultiPoint, geosPoints), nil
 	case orb.MultiLineString:
 		geosLineStrings := make([]*geos.Geom, 0, len(orbGeometry))
 		for _, orbLineString := range orbGeometry {
-			geosLineString := NewGEOSGeomFromOrbGeometry(geosContext, orbLineString)
-			geosLineStrings = append(geosLineStrings, geosLineString)
+			geosLineStrings = append(geosLineStrings, c.newGEOSLineStringFromOrbLineString(geosContext, orbLineString))
 		}
-		return geosContext.NewCollection(geos.TypeIDMultiLineString, geosLineStrings)
+		return geosContext.NewCollection(geos.TypeIDMultiLineString, geosLineStrings), nil
 	case orb.MultiPolygon:
 		geosPolygons := make([]*geos.Geom, 0, len(orbGeometry))
 		for _, orbPolygon := range orbGeometry {
-			geosPolygon := NewGEOSGeomFromOrbGeometry(geosContext, orbPolygon)
-			geosPolygons = append(geosPolygons, geosPolygon)
+			geosPolygons = append(geosPolygons, c.newGEOSPolygonFromOrbPolygon(geosContext, orbPolygon))
 		}
-		return geosContext.NewCollection(geos.TypeIDMultiPolygon, geosPolygons)
+		return geosContext.NewCollection(geos.TypeIDMultiPolygon, geosPolygons), nil
+	case orb.Bound:
+		return c.newGEOSPolygonFromOrbPolygon(geosContext, orbPolygonFromOrbBound(orbGeometry)), nil
 	case orb.Collection:
-		geosGeometries := make([]*geos.Geom, 0, len(orbGeometry))
-		for _, orbGeometry := range orbGeometry {
-			geosGeom := NewGEOSGeomFromOrbGeometry(geosContext, orbGeometry)
-			geosGeometries = append(geosGeometries, geosGeom)
+		geosGeoms := make([]*geos.Geom, 0, len(orbGeometry))
+		for i, orbGeometry := range orbGeometry {
+			geosGeom, err := c.newGEOSGeomFromOrbGeometry(geosContext, orbGeometry)
+			if err != nil {
+				return nil, withPathIndex(err, i)
+			}
+			geosGeoms = append(geosGeoms, geosGeom)
 		}
-		return geosContext.NewCollection(geos.TypeIDGeometryCollection, geosGeometries)
+		return geosContext.NewCollection(geos.TypeIDGeometryCollection, geosGeoms), nil
 	default:
-		panic(fmt.Sprintf("%T: unsupported type", orbGeometry))
+		return nil, newUnsupportedTypeError(fmt.Sprintf("%T", orbGeometry))
 	}
 }
 
-func geosCoordsFromOrbPoint(orbPoint orb.Point) []float64 {
-	return []float64{orbPoint[0], orbPoint[1]}
-}
-
-func geosCoordsFromOrbLineString(orbLineString orb.LineString) [][]float64 {
-	geosCoords := make([][]float64, 0, len(orbLineString))
-	for _, orbPoint := range orbLineString {
-		geosCoords = append(geosCoords, geosCoordsFromOrbPoint(orbPoint))
+func (c *converter) newGEOSPointFromOrbPoint(geosContext *geos.Context, orbPoint orb.Point) *geos.Geom {
+	if orbPointIsEmpty(orbPoint) {
+		return geosContext.NewEmptyPoint()
 	}
-	return geosCoords
+	return geosContext.NewPoint(c.geosCoords.appendOrbPoints([]orb.Point{orbPoint}, c.transform)[0])
 }
 
-func geosCoordsFromOrbRing(orbRing orb.Ring) [][]float64 {
-	geosCoords := make([][]float64, 0, len(orbRing))
-	for _, orbPoint := range orbRing {
-		geosCoords = append(geosCoords, geosCoordsFromOrbPoint(orbPoint))
+func (c *converter) newGEOSLineStringFromOrbLineString(geosContext *geos.Context, orbLineString orb.LineString) *geos.Geom {
+	geosCoords := c.geosCoords.appendOrbPoints(orbLineString, c.transform)
+	if c.removeRepeatedPoints() {
+		geosCoords = geosRemoveRepeatedCoords(geosCoords, minLineStringPoints)
 	}
-	return geosCoords
+	return newGEOSLineString(geosContext, geosCoords)
 }
 
-func geosCoordsFromOrbPolygon(orbPolygon orb.Polygon) [][][]float64 {
-	geosCoords := make([][][]float64, 0, len(orbPolygon))
-	for _, orbRing := range orbPolygon {
-		geosCoords = append(geosCoords, geosCoordsFromOrbRing(orbRing))
+func (c *converter) newGEOSPolygonFromOrbPolygon(geosContext *geos.Context, orbPolygon orb.Polygon) *geos.Geom {
+	geosCoordss := c.geosCoords.appendOrbPolygon(orbPolygon, c.transform)
+	if c.removeRepeatedPoints() {
+		geosRemoveRepeatedCoordss(geosCoordss, minRingPoints)
 	}
-	return geosCoords
+	geosOrientPolygonCoords(geosCoordss, c.orientation)
+	return newGEOSPolygon(geosContext, geosCoordss)
 }
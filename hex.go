@@ -0,0 +1,133 @@
+package geobabel
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+
+	"github.com/paulmach/orb"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geos"
+)
+
+// Hex-encoded WKB is decoded case-insensitively, with an optional \x prefix as
+// in PostgreSQL's bytea output, and may have EWKB headers. It is encoded in
+// upper case, like PostGIS.
+
+// NewGEOSGeomFromHexWKB returns a new *geos.Geom parsed from hex-encoded WKB or
+// EWKB. The SRID, if any, is set on the returned geometry.
+func NewGEOSGeomFromHexWKB(geosContext *geos.Context, hexWKB string, options ...Option) (*geos.Geom, error) {
+	wkb, err := decodeHexWKB(hexWKB)
+	if err != nil {
+		return nil, err
+	}
+	return NewGEOSGeomFromWKB(geosContext, wkb, options...)
+}
+
+// NewGeomTFromHexWKB returns a new geom.T parsed from hex-encoded WKB or EWKB.
+// The SRID, if any, is set on the returned geometry.
+func NewGeomTFromHexWKB(hexWKB string, options ...Option) (geom.T, error) {
+	wkb, err := decodeHexWKB(hexWKB)
+	if err != nil {
+		return nil, err
+	}
+	if wkbHasEWKBFlags(wkb) {
+		return NewGeomTFromEWKB(wkb, options...)
+	}
+	return NewGeomTFromWKB(wkb, options...)
+}
+
+// NewOrbGeometryFromHexWKB returns a new orb.Geometry and SRID parsed from
+// hex-encoded WKB or EWKB. Z and M ordinates are handled according to options.
+func NewOrbGeometryFromHexWKB(hexWKB string, options ...Option) (orb.Geometry, int, error) {
+	geomT, err := NewGeomTFromHexWKB(hexWKB, options...)
+	if err != nil {
+		return nil, 0, err
+	}
+	orbGeometry, err := NewOrbGeometryFromGeomTE(geomT, options...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return orbGeometry, geomT.SRID(), nil
+}
+
+func HexWKBFromGEOSGeom(geosGeom *geos.Geom, options ...Option) string {
+	return encodeHexWKB(WKBFromGEOSGeom(geosGeom, options...))
+}
+
+func HexWKBFromGeomT(geomT geom.T, options ...Option) (string, error) {
+	wkb, err := WKBFromGeomT(geomT, options...)
+	if err != nil {
+		return "", err
+	}
+	return encodeHexWKB(wkb), nil
+}
+
+func HexWKBFromOrbGeometry(orbGeometry orb.Geometry, options ...Option) string {
+	return encodeHexWKB(WKBFromOrbGeometry(orbGeometry, options...))
+}
+
+// HexEWKBFromGEOSGeom returns geosGeom encoded as hex EWKB, including its SRID
+// and Z and M ordinates.
+func HexEWKBFromGEOSGeom(geosGeom *geos.Geom, options ...Option) (string, error) {
+	ewkb, err := EWKBFromGEOSGeom(geosGeom, options...)
+	if err != nil {
+		return "", err
+	}
+	return encodeHexWKB(ewkb), nil
+}
+
+// HexEWKBFromGeomT returns geomT encoded as hex EWKB, including its SRID and Z
+// and M ordinates.
+func HexEWKBFromGeomT(geomT geom.T, options ...Option) (string, error) {
+	ewkb, err := EWKBFromGeomT(geomT, options...)
+	if err != nil {
+		return "", err
+	}
+	return encodeHexWKB(ewkb), nil
+}
+
+func HexEWKBFromOrbGeometry(orbGeometry orb.Geometry, srid int, options ...Option) string {
+	return encodeHexWKB(EWKBFromOrbGeometry(orbGeometry, srid, options...))
+}
+
+// HexEWKBFromOrbGeometryE returns orbGeometry encoded as hex EWKB with srid. It
+// returns a *ConvertError if orbGeometry cannot be encoded.
+func HexEWKBFromOrbGeometryE(orbGeometry orb.Geometry, srid int, options ...Option) (string, error) {
+	ewkb, err := EWKBFromOrbGeometryE(orbGeometry, srid, options...)
+	if err != nil {
+		return "", err
+	}
+	return encodeHexWKB(ewkb), nil
+}
+
+// decodeHexWKB returns the WKB encoded in hexWKB.
+func decodeHexWKB(hexWKB string) ([]byte, error) {
+	hexWKB = strings.TrimSpace(hexWKB)
+	if len(hexWKB) >= 2 && hexWKB[0] == '\\' && (hexWKB[1] == 'x' || hexWKB[1] == 'X') {
+		hexWKB = hexWKB[2:]
+	}
+	return hex.DecodeString(hexWKB)
+}
+
+// encodeHexWKB returns wkb encoded as upper case hex.
+func encodeHexWKB(wkb []byte) string {
+	return strings.ToUpper(hex.EncodeToString(wkb))
+}
+
+// wkbHasEWKBFlags returns whether the geometry type of wkb has any EWKB flags.
+func wkbHasEWKBFlags(wkb []byte) bool {
+	if len(wkb) < 5 {
+		return false
+	}
+	var byteOrder binary.ByteOrder
+	switch wkb[0] {
+	case 0:
+		byteOrder = binary.BigEndian
+	case 1:
+		byteOrder = binary.LittleEndian
+	default:
+		return false
+	}
+	return byteOrder.Uint32(wkb[1:5])&(ewkbFlagZ|ewkbFlagM|ewkbFlagSRID) != 0
+}
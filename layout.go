@@ -0,0 +1,111 @@
+package geobabel
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// geomTWithLayout returns a copy of geomT with layout. Ordinates that are not in
+// layout are dropped and ordinates that are not in geomT's layout are NaN.
+// Elements of geometry collections are also converted to layout.
+func geomTWithLayout(geomT geom.T, layout geom.Layout) geom.T {
+	srcLayout := geomT.Layout()
+	switch geomT := geomT.(type) {
+	case *geom.Point:
+		if geomT.Empty() {
+			return geom.NewPointEmpty(layout).SetSRID(geomT.SRID())
+		}
+		return geom.NewPointFlat(layout, geomFlatCoordsWithLayout(geomT.FlatCoords(), srcLayout, layout)).SetSRID(geomT.SRID())
+	case *geom.LineString:
+		return geom.NewLineStringFlat(layout, geomFlatCoordsWithLayout(geomT.FlatCoords(), srcLayout, layout)).SetSRID(geomT.SRID())
+	case *geom.LinearRing:
+		return geom.NewLinearRingFlat(layout, geomFlatCoordsWithLayout(geomT.FlatCoords(), srcLayout, layout)).SetSRID(geomT.SRID())
+	case *geom.Polygon:
+		return geom.NewPolygonFlat(
+			layout,
+			geomFlatCoordsWithLayout(geomT.FlatCoords(), srcLayout, layout),
+			geomEndsWithLayout(geomT.Ends(), srcLayout, layout),
+		).SetSRID(geomT.SRID())
+	case *geom.MultiPoint:
+		return geom.NewMultiPointFlat(
+			layout,
+			geomFlatCoordsWithLayout(geomT.FlatCoords(), srcLayout, layout),
+			geom.NewMultiPointFlatOptionWithEnds(geomEndsWithLayout(geomT.Ends(), srcLayout, layout)),
+		).SetSRID(geomT.SRID())
+	case *geom.MultiLineString:
+		return geom.NewMultiLineStringFlat(
+			layout,
+			geomFlatCoordsWithLayout(geomT.FlatCoords(), srcLayout, layout),
+			geomEndsWithLayout(geomT.Ends(), srcLayout, layout),
+		).SetSRID(geomT.SRID())
+	case *geom.MultiPolygon:
+		var geomEndss [][]int
+		if geomT.Endss() != nil {
+			geomEndss = make([][]int, 0, len(geomT.Endss()))
+			for _, geomEnds := range geomT.Endss() {
+				geomEndss = append(geomEndss, geomEndsWithLayout(geomEnds, srcLayout, layout))
+			}
+		}
+		return geom.NewMultiPolygonFlat(
+			layout,
+			geomFlatCoordsWithLayout(geomT.FlatCoords(), srcLayout, layout),
+			geomEndss,
+		).SetSRID(geomT.SRID())
+	case *geom.GeometryCollection:
+		geomGeometryCollection := geom.NewGeometryCollection()
+		if geomT.NumGeoms() == 0 {
+			geomGeometryCollection.MustSetLayout(layout)
+		}
+		for _, geomGeom := range geomT.Geoms() {
+			geomGeometryCollection.MustPush(geomTWithLayout(geomGeom, layout))
+		}
+		return geomGeometryCollection.SetSRID(geomT.SRID())
+	default:
+		return geomT
+	}
+}
+
+// geomFlatCoordsWithLayout returns a copy of flatCoords with layout converted
+// from srcLayout to dstLayout.
+func geomFlatCoordsWithLayout(flatCoords []float64, srcLayout, dstLayout geom.Layout) []float64 {
+	if flatCoords == nil {
+		return nil
+	}
+	srcStride, dstStride := srcLayout.Stride(), dstLayout.Stride()
+	srcZIndex, srcMIndex := srcLayout.ZIndex(), srcLayout.MIndex()
+	dstZIndex, dstMIndex := dstLayout.ZIndex(), dstLayout.MIndex()
+	dstFlatCoords := make([]float64, 0, len(flatCoords)/srcStride*dstStride)
+	for i := 0; i < len(flatCoords); i += srcStride {
+		dstFlatCoords = append(dstFlatCoords, flatCoords[i], flatCoords[i+1])
+		for j := 2; j < dstStride; j++ {
+			var srcIndex int
+			switch j {
+			case dstZIndex:
+				srcIndex = srcZIndex
+			case dstMIndex:
+				srcIndex = srcMIndex
+			}
+			if srcIndex == -1 {
+				dstFlatCoords = append(dstFlatCoords, math.NaN())
+			} else {
+				dstFlatCoords = append(dstFlatCoords, flatCoords[i+srcIndex])
+			}
+		}
+	}
+	return dstFlatCoords
+}
+
+// geomEndsWithLayout returns a copy of ends with layout converted from
+// srcLayout to dstLayout.
+func geomEndsWithLayout(ends []int, srcLayout, dstLayout geom.Layout) []int {
+	if ends == nil {
+		return nil
+	}
+	srcStride, dstStride := srcLayout.Stride(), dstLayout.Stride()
+	dstEnds := make([]int, 0, len(ends))
+	for _, end := range ends {
+		dstEnds = append(dstEnds, end/srcStride*dstStride)
+	}
+	return dstEnds
+}
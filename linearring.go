@@ -0,0 +1,106 @@
+package geobabel
+
+import (
+	"github.com/paulmach/orb"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geos"
+)
+
+// WKB has no linear ring type, so linear rings are encoded as line strings or
+// polygons according to the linear ring policy, and decoded geometries that
+// could have been encoded from a linear ring with the same policy are replaced
+// by linear rings. Only top-level geometries and members of geometry
+// collections are considered, as multi geometries cannot contain linear rings.
+
+// geomTWithLinearRings returns geomT with the geometries that encode linear
+// rings according to c's linear ring policy replaced by linear rings. geomT
+// must be freshly decoded, as geometry collections are modified in place.
+func (c *converter) geomTWithLinearRings(geomT geom.T) geom.T {
+	switch geomT := geomT.(type) {
+	case *geom.LineString:
+		if c.linearRingPolicy == LinearRingPolicyLineString && (geomT.Empty() || geomFlatCoordsClosed(geomT.FlatCoords(), geomT.Stride())) {
+			return geom.NewLinearRingFlat(geomT.Layout(), geomT.FlatCoords()).SetSRID(geomT.SRID())
+		}
+	case *geom.Polygon:
+		if c.linearRingPolicy == LinearRingPolicyPolygon && geomT.NumLinearRings() <= 1 {
+			geomFlatCoords := geomT.FlatCoords()
+			if geomFlatCoords == nil {
+				// Empty polygons have nil flat coordinates.
+				geomFlatCoords = []float64{}
+			}
+			return geom.NewLinearRingFlat(geomT.Layout(), geomFlatCoords).SetSRID(geomT.SRID())
+		}
+	case *geom.GeometryCollection:
+		for i, geomGeom := range geomT.Geoms() {
+			geomT.Geoms()[i] = c.geomTWithLinearRings(geomGeom)
+		}
+	}
+	return geomT
+}
+
+// orbGeometryWithRings returns orbGeometry with the geometries that encode
+// linear rings according to c's linear ring policy replaced by orb.Rings.
+// orbGeometry must be freshly decoded, as collections are modified in place.
+func (c *converter) orbGeometryWithRings(orbGeometry orb.Geometry) orb.Geometry {
+	switch orbGeometry := orbGeometry.(type) {
+	case orb.LineString:
+		if c.linearRingPolicy == LinearRingPolicyLineString && (len(orbGeometry) == 0 || orbGeometry[0] == orbGeometry[len(orbGeometry)-1]) {
+			return orb.Ring(orbGeometry)
+		}
+	case orb.Polygon:
+		if c.linearRingPolicy == LinearRingPolicyPolygon {
+			switch len(orbGeometry) {
+			case 0:
+				return orb.Ring{}
+			case 1:
+				return orbGeometry[0]
+			}
+		}
+	case orb.Collection:
+		for i, orbMember := range orbGeometry {
+			orbGeometry[i] = c.orbGeometryWithRings(orbMember)
+		}
+	}
+	return orbGeometry
+}
+
+// geosGeomWithLinearRings returns geosGeom with the geometries that encode
+// linear rings according to c's linear ring policy replaced by linear rings.
+func (c *converter) geosGeomWithLinearRings(geosContext *geos.Context, geosGeom *geos.Geom) *geos.Geom {
+	switch geosGeom.TypeID() {
+	case geos.TypeIDLineString:
+		if c.linearRingPolicy != LinearRingPolicyLineString {
+			break
+		}
+		geosCoords := geosGeom.CoordSeq().ToCoords()
+		if n := len(geosCoords); n == 0 || geosCoords[0][0] == geosCoords[n-1][0] && geosCoords[0][1] == geosCoords[n-1][1] {
+			return newGEOSLinearRing(geosContext, geosCoords).SetSRID(geosGeom.SRID())
+		}
+	case geos.TypeIDPolygon:
+		switch {
+		case c.linearRingPolicy != LinearRingPolicyPolygon:
+		case geosGeom.IsEmpty():
+			return newGEOSLinearRing(geosContext, nil).SetSRID(geosGeom.SRID())
+		case geosGeom.NumInteriorRings() == 0:
+			return geosGeom.ExteriorRing().Clone().SetSRID(geosGeom.SRID())
+		}
+	case geos.TypeIDGeometryCollection:
+		if c.linearRingPolicy == LinearRingPolicyError {
+			break
+		}
+		geosNumGeometries := geosGeom.NumGeometries()
+		geosGeoms := make([]*geos.Geom, 0, geosNumGeometries)
+		for i := 0; i < geosNumGeometries; i++ {
+			// Collections take ownership of their members, so members that
+			// are not replaced are cloned.
+			geosMember := geosGeom.Geometry(i)
+			geosNewMember := c.geosGeomWithLinearRings(geosContext, geosMember)
+			if geosNewMember == geosMember {
+				geosNewMember = geosMember.Clone()
+			}
+			geosGeoms = append(geosGeoms, geosNewMember)
+		}
+		return geosContext.NewCollection(geos.TypeIDGeometryCollection, geosGeoms).SetSRID(geosGeom.SRID())
+	}
+	return geosGeom
+}
@@ -0,0 +1,357 @@
+package geobabel
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geos"
+)
+
+// An Option sets an option on a conversion.
+type Option func(*converter)
+
+// A ZMPolicy determines how Z and M ordinates that cannot be represented by
+// the target of a conversion are handled.
+type ZMPolicy int
+
+// ZM policies.
+const (
+	// ZMPolicyDrop silently drops Z and M ordinates that the target cannot
+	// represent. This is the default.
+	ZMPolicyDrop ZMPolicy = iota
+	// ZMPolicyError returns an error wrapping ErrUnsupportedLayout if the
+	// target cannot represent all of the source's Z and M ordinates.
+	ZMPolicyError
+	// ZMPolicyKeep keeps Z and M ordinates by changing their representation
+	// where necessary. XYM geometries are converted to XYZM *geos.Geoms with
+	// NaN Z ordinates. Otherwise it behaves like ZMPolicyError.
+	ZMPolicyKeep
+)
+
+// A LinearRingPolicy determines how linear rings, which WKB and TWKB cannot
+// represent, are encoded, and which decoded geometries are linear rings.
+type LinearRingPolicy int
+
+// Linear ring policies.
+const (
+	// LinearRingPolicyError returns a *ConvertError wrapping
+	// ErrUnsupportedType when encoding a linear ring. This is the default,
+	// except for WKBFromGEOSGeom and WKBFromOrbGeometry.
+	LinearRingPolicyError LinearRingPolicy = iota
+	// LinearRingPolicyLineString encodes linear rings as line strings, like
+	// GEOS. Closed and empty line strings are decoded as linear rings.
+	LinearRingPolicyLineString
+	// LinearRingPolicyPolygon encodes linear rings as polygons with a single
+	// ring. Polygons with a single ring and empty polygons are decoded as
+	// linear rings.
+	LinearRingPolicyPolygon
+)
+
+// An Orientation determines the winding order of the rings of polygons.
+type Orientation int
+
+// Orientations.
+const (
+	// OrientationKeep keeps the orientation of rings. This is the default.
+	OrientationKeep Orientation = iota
+	// OrientationCounterclockwise orients exterior rings counterclockwise and
+	// interior rings clockwise, following the right hand rule of RFC 7946.
+	OrientationCounterclockwise
+	// OrientationClockwise orients exterior rings clockwise and interior rings
+	// counterclockwise, as required by shapefiles. Mapbox vector tiles also
+	// require it for geometries that are later flipped into tile coordinates,
+	// where Y increases downwards.
+	OrientationClockwise
+)
+
+// A ValidityPolicy determines how invalid geometries are handled.
+type ValidityPolicy int
+
+// Validity policies.
+const (
+	// ValidityPolicyIgnore converts geometries without checking their
+	// validity. This is the default.
+	ValidityPolicyIgnore ValidityPolicy = iota
+	// ValidityPolicyError returns a *ConvertError wrapping a *ValidityError
+	// if the geometry is invalid.
+	ValidityPolicyError
+	// ValidityPolicyRepair repairs invalid geometries. Unclosed rings are
+	// closed, rings and line strings with too few points are dropped, and
+	// any remaining problems are repaired with GEOS's MakeValid.
+	ValidityPolicyRepair
+)
+
+// A WKBFlavor determines how WKB encodes Z and M ordinates and SRIDs.
+type WKBFlavor int
+
+// WKB flavors.
+const (
+	// WKBFlavorISO encodes Z and M ordinates with ISO 13249-3 geometry types,
+	// for example 1001 for a point with a Z ordinate, like go-geom. It cannot
+	// encode SRIDs. This is the default.
+	WKBFlavorISO WKBFlavor = iota
+	// WKBFlavorExtended encodes Z and M ordinates and SRIDs with PostGIS's
+	// EWKB flags, like GEOS.
+	WKBFlavorExtended
+)
+
+// A TransformFunc transforms the X and Y ordinates of a point. Z and M
+// ordinates are not transformed.
+type TransformFunc func(x, y float64) (float64, float64)
+
+// A converter holds the options for a conversion.
+type converter struct {
+	zmPolicy         ZMPolicy
+	maxDecimalDigits int
+	geoJSONBBox      bool
+	linearRingPolicy LinearRingPolicy
+	orientation      Orientation
+	geosContext      *geos.Context
+	promoteToMulti   bool
+	unwrapSingletons bool
+	transform        TransformFunc
+	srid             int
+	twkbBBox         bool
+	twkbIDs          []int64
+	twkbPrecisionXY  int
+	twkbPrecisionZ   int
+	twkbPrecisionM   int
+	twkbSize         bool
+	gridSize         float64
+	geosPrecision    bool
+	validityPolicy   ValidityPolicy
+	wkbByteOrder     binary.ByteOrder
+	wkbFlavor        WKBFlavor
+	wkbLayout        geom.Layout
+	wkbSRID          bool
+	geosCoords       geosCoordsBuffer
+}
+
+// WithGeoJSONBBox sets whether encoded GeoJSON geometries include a bbox
+// member. Empty geometries never include a bbox member.
+func WithGeoJSONBBox(geoJSONBBox bool) Option {
+	return func(c *converter) {
+		c.geoJSONBBox = geoJSONBBox
+	}
+}
+
+// WithGEOSContext sets the GEOS context used to create new *geos.Geoms where
+// the conversion function does not take one as an argument.
+func WithGEOSContext(geosContext *geos.Context) Option {
+	return func(c *converter) {
+		c.geosContext = geosContext
+	}
+}
+
+// WithGEOSPrecision sets whether *geos.Geoms created by conversions have their
+// precision model set to the grid size set by WithGridSize or WithPrecision,
+// with GEOS's SetPrecision.
+func WithGEOSPrecision(geosPrecision bool) Option {
+	return func(c *converter) {
+		c.geosPrecision = geosPrecision
+	}
+}
+
+// WithGridSize sets the grid size that X and Y ordinates are snapped to while
+// converting geometries between libraries, after any transformation. Repeated
+// points created by snapping are removed from line strings and rings, unless
+// that would leave too few points. The default, zero, disables snapping.
+func WithGridSize(gridSize float64) Option {
+	return func(c *converter) {
+		c.gridSize = gridSize
+	}
+}
+
+// WithLinearRingPolicy sets the policy for linear rings in WKB and TWKB. It
+// applies to top-level geometries and to members of geometry collections.
+func WithLinearRingPolicy(linearRingPolicy LinearRingPolicy) Option {
+	return func(c *converter) {
+		c.linearRingPolicy = linearRingPolicy
+	}
+}
+
+// WithMaxDecimalDigits sets the maximum number of decimal digits in encoded
+// coordinates. Trailing zeros are always removed. The default, -1, uses the
+// minimum number of digits that represents each coordinate exactly.
+func WithMaxDecimalDigits(maxDecimalDigits int) Option {
+	return func(c *converter) {
+		c.maxDecimalDigits = maxDecimalDigits
+	}
+}
+
+// WithOrientation sets the orientation of the rings of polygons created by
+// conversions and encoders. Rings are oriented while their coordinates are
+// copied. The orientation is determined after any transformation.
+func WithOrientation(orientation Orientation) Option {
+	return func(c *converter) {
+		c.orientation = orientation
+	}
+}
+
+// WithPrecision sets the number of decimal places that X and Y ordinates are
+// snapped to, like WithGridSize with a grid size of 10^-decimalPlaces.
+func WithPrecision(decimalPlaces int) Option {
+	return WithGridSize(math.Pow10(-decimalPlaces))
+}
+
+// WithProjection sets the projection applied to the coordinates of every point
+// while converting geometries between libraries, replacing any transformation
+// set by WithTransform. go-geom and GEOS geometries created by the conversion
+// have the projection's target SRID.
+func WithProjection(projection *Projection) Option {
+	return func(c *converter) {
+		c.transform = projection.Transform
+		c.srid = projection.toSRID
+	}
+}
+
+// WithPromoteToMulti sets whether typed conversions to multi geometries also
+// accept the corresponding single geometry, for example reading a polygon as a
+// multipolygon with one polygon. Typed conversions to collections then accept
+// any geometry.
+func WithPromoteToMulti(promoteToMulti bool) Option {
+	return func(c *converter) {
+		c.promoteToMulti = promoteToMulti
+	}
+}
+
+// WithRightHandRule sets whether the rings of polygons follow the right hand
+// rule of RFC 7946, with exterior rings counterclockwise and interior rings
+// clockwise. It is equivalent to WithOrientation with
+// OrientationCounterclockwise, or, if rightHandRule is false, OrientationKeep.
+func WithRightHandRule(rightHandRule bool) Option {
+	if rightHandRule {
+		return WithOrientation(OrientationCounterclockwise)
+	}
+	return WithOrientation(OrientationKeep)
+}
+
+// WithTransform sets a function that transforms the coordinates of every point
+// while converting geometries between libraries. The transformation is applied
+// while copying coordinates, so it does not require an extra pass over the
+// geometry. geom.Ts are also transformed when they are encoded. It replaces
+// any projection set by WithProjection, including its target SRID.
+func WithTransform(transform TransformFunc) Option {
+	return func(c *converter) {
+		c.transform = transform
+		c.srid = 0
+	}
+}
+
+// WithTWKBBBox sets whether encoded TWKB includes bounding boxes.
+func WithTWKBBBox(twkbBBox bool) Option {
+	return func(c *converter) {
+		c.twkbBBox = twkbBBox
+	}
+}
+
+// WithTWKBIDs sets the IDs of the members of encoded TWKB multi geometries and
+// geometry collections. There must be one ID for each member.
+func WithTWKBIDs(ids []int64) Option {
+	return func(c *converter) {
+		c.twkbIDs = ids
+	}
+}
+
+// WithTWKBPrecision sets the number of decimal places of X and Y, Z, and M
+// ordinates in encoded TWKB, like PostGIS's ST_AsTWKB. precisionXY must be
+// between -8 and 7, and precisionZ and precisionM between 0 and 7. The
+// default is zero for all ordinates.
+func WithTWKBPrecision(precisionXY, precisionZ, precisionM int) Option {
+	return func(c *converter) {
+		c.twkbPrecisionXY = precisionXY
+		c.twkbPrecisionZ = precisionZ
+		c.twkbPrecisionM = precisionM
+	}
+}
+
+// WithTWKBSize sets whether encoded TWKB includes the size of each geometry.
+func WithTWKBSize(twkbSize bool) Option {
+	return func(c *converter) {
+		c.twkbSize = twkbSize
+	}
+}
+
+// WithUnwrapSingletons sets whether typed conversions accept a multi geometry
+// or geometry collection with exactly one member of the target type, and
+// convert that member.
+func WithUnwrapSingletons(unwrapSingletons bool) Option {
+	return func(c *converter) {
+		c.unwrapSingletons = unwrapSingletons
+	}
+}
+
+// WithValidityPolicy sets the policy for invalid geometries. Rings and line
+// strings are always checked for closure and number of points. Geometries are
+// also checked for OGC validity, including self-intersections, with GEOS when
+// converting to or from GEOS, or, for other conversions, if a GEOS context is
+// set with WithGEOSContext. Only the untyped conversions, for example
+// NewGeomTFromOrbGeometryE, and the typed conversions to GEOS check validity.
+func WithValidityPolicy(validityPolicy ValidityPolicy) Option {
+	return func(c *converter) {
+		c.validityPolicy = validityPolicy
+	}
+}
+
+// WithWKBByteOrder sets the byte order of encoded WKB, either
+// binary.LittleEndian or binary.BigEndian. The default is binary.LittleEndian.
+func WithWKBByteOrder(byteOrder binary.ByteOrder) Option {
+	return func(c *converter) {
+		c.wkbByteOrder = byteOrder
+	}
+}
+
+// WithWKBFlavor sets the flavor of encoded WKB.
+func WithWKBFlavor(flavor WKBFlavor) Option {
+	return func(c *converter) {
+		c.wkbFlavor = flavor
+	}
+}
+
+// WithWKBLayout sets the layout of encoded WKB. Ordinates that the geometry
+// does not have are encoded as zero, like PostGIS's ST_Force3D, and ordinates
+// that the layout does not have are dropped. The default, geom.NoLayout, keeps
+// the geometry's layout.
+func WithWKBLayout(layout geom.Layout) Option {
+	return func(c *converter) {
+		c.wkbLayout = layout
+	}
+}
+
+// WithWKBSRID sets whether encoded WKB includes the geometry's SRID, if it is
+// not zero. SRIDs are only encoded with WKBFlavorExtended.
+func WithWKBSRID(wkbSRID bool) Option {
+	return func(c *converter) {
+		c.wkbSRID = wkbSRID
+	}
+}
+
+// WithZMPolicy sets the policy for Z and M ordinates that cannot be
+// represented by the target.
+func WithZMPolicy(zmPolicy ZMPolicy) Option {
+	return func(c *converter) {
+		c.zmPolicy = zmPolicy
+	}
+}
+
+// targetSRID returns the SRID of a converted geometry whose source has srid.
+func (c *converter) targetSRID(srid int) int {
+	if c.srid != 0 {
+		return c.srid
+	}
+	return srid
+}
+
+func newConverter(options []Option) *converter {
+	c := &converter{
+		maxDecimalDigits: -1,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	if c.gridSize != 0 {
+		c.transform = composeTransforms(c.transform, newSnapTransform(c.gridSize))
+	}
+	return c
+}
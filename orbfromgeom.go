@@ -2,137 +2,404 @@ package geobabel
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/paulmach/orb"
 	"github.com/twpayne/go-geom"
 )
 
-func NewOrbGeometryFromGeomT(geomT geom.T) orb.Geometry {
+func NewOrbGeometryFromGeomT(geomT geom.T, options ...Option) orb.Geometry {
+	orbGeometry, err := NewOrbGeometryFromGeomTE(geomT, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbGeometry
+}
+
+// NewOrbGeometryFromGeomTE returns a new orb.Geometry converted from geomT. It
+// returns a *ConvertError if geomT cannot be converted.
+func NewOrbGeometryFromGeomTE(geomT geom.T, options ...Option) (orb.Geometry, error) {
+	c := newConverter(options)
+	geomT, err := c.validateGeomT(geomT)
+	if err != nil {
+		return nil, err
+	}
+	geomT, err = c.validateGeomTWithGEOS(geomT, fmt.Sprintf("%T", geomT))
+	if err != nil {
+		return nil, err
+	}
+	return c.newOrbGeometryFromGeomT(geomT)
+}
+
+func (c *converter) newOrbGeometryFromGeomT(geomT geom.T) (orb.Geometry, error) {
+	if err := c.checkGeomTForOrb(geomT); err != nil {
+		return nil, err
+	}
 	switch geomT := geomT.(type) {
 	case *geom.Point:
-		return orbPointFromGeomPoint(geomT)
+		return c.orbPointFromGeomPoint(geomT), nil
 	case *geom.LineString:
-		return orbLineStringFromGeomLineString(geomT)
+		return c.orbLineStringFromGeomLineString(geomT), nil
 	case *geom.LinearRing:
-		return orbRingFromGeomLinearRing(geomT)
+		return c.orbRingFromGeomLinearRing(geomT), nil
 	case *geom.Polygon:
-		return orbPolygonFromGeomPolygon(geomT)
+		return c.orbPolygonFromGeomPolygon(geomT), nil
 	case *geom.MultiPoint:
-		return orbMultiPointFromGeomMultiPoint(geomT)
+		return c.orbMultiPointFromGeomMultiPoint(geomT), nil
 	case *geom.MultiLineString:
-		return orbMultiLineStringFromGeomMultiLineString(geomT)
+		return c.orbMultiLineStringFromGeomMultiLineString(geomT), nil
 	case *geom.MultiPolygon:
-		return orbMultiPolygonFromGeomMultiPolygon(geomT)
+		return c.orbMultiPolygonFromGeomMultiPolygon(geomT), nil
 	case *geom.GeometryCollection:
-		return orbCollectionFromGeomGeometryCollection(geomT)
+		orbCollection, err := c.orbCollectionFromGeomGeometryCollection(geomT)
+		if err != nil {
+			return nil, err
+		}
+		return orbCollection, nil
+	default:
+		return nil, newUnsupportedTypeError(fmt.Sprintf("%T", geomT))
+	}
+}
+
+// checkGeomTForOrb returns an error if geomT has Z or M ordinates that would be
+// lost by converting it to an orb.Geometry and c's ZM policy does not allow
+// them to be dropped. Geometry collections are checked element by element.
+func (c *converter) checkGeomTForOrb(geomT geom.T) error {
+	if c.zmPolicy == ZMPolicyDrop {
+		return nil
+	}
+	if _, ok := geomT.(*geom.GeometryCollection); ok {
+		return nil
+	}
+	switch geomLayout := geomT.Layout(); geomLayout {
+	case geom.NoLayout, geom.XY:
+		return nil
 	default:
-		panic(fmt.Sprintf("%T: unsupported type", geomT))
+		return newUnsupportedLayoutError(fmt.Sprintf("%T", geomT), geomLayout.String())
+	}
+}
+
+func NewOrbPointFromGeomPoint(geomPoint *geom.Point, options ...Option) orb.Point {
+	orbPoint, err := NewOrbPointFromGeomPointE(geomPoint, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbPoint
+}
+
+// NewOrbPointFromGeomPointE returns a new orb.Point converted from geomPoint.
+// It returns a *ConvertError wrapping ErrUnsupportedLayout if geomPoint has Z
+// or M ordinates that the ZM policy does not allow to be dropped.
+func NewOrbPointFromGeomPointE(geomPoint *geom.Point, options ...Option) (orb.Point, error) {
+	c := newConverter(options)
+	if err := c.checkGeomTForOrb(geomPoint); err != nil {
+		return orb.Point{}, err
+	}
+	return c.orbPointFromGeomPoint(geomPoint), nil
+}
+
+func NewOrbLineStringFromGeomLineString(geomLineString *geom.LineString, options ...Option) orb.LineString {
+	orbLineString, err := NewOrbLineStringFromGeomLineStringE(geomLineString, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbLineString
+}
+
+// NewOrbLineStringFromGeomLineStringE returns a new orb.LineString converted
+// from geomLineString. It returns a *ConvertError wrapping ErrUnsupportedLayout
+// if geomLineString has Z or M ordinates that the ZM policy does not allow to
+// be dropped.
+func NewOrbLineStringFromGeomLineStringE(geomLineString *geom.LineString, options ...Option) (orb.LineString, error) {
+	c := newConverter(options)
+	if err := c.checkGeomTForOrb(geomLineString); err != nil {
+		return nil, err
+	}
+	return c.orbLineStringFromGeomLineString(geomLineString), nil
+}
+
+func NewOrbRingFromGeomLinearRing(geomLinearRing *geom.LinearRing, options ...Option) orb.Ring {
+	orbRing, err := NewOrbRingFromGeomLinearRingE(geomLinearRing, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbRing
+}
+
+// NewOrbRingFromGeomLinearRingE returns a new orb.Ring converted from
+// geomLinearRing. It returns a *ConvertError wrapping ErrUnsupportedLayout if
+// geomLinearRing has Z or M ordinates that the ZM policy does not allow to be
+// dropped.
+func NewOrbRingFromGeomLinearRingE(geomLinearRing *geom.LinearRing, options ...Option) (orb.Ring, error) {
+	c := newConverter(options)
+	if err := c.checkGeomTForOrb(geomLinearRing); err != nil {
+		return nil, err
+	}
+	return c.orbRingFromGeomLinearRing(geomLinearRing), nil
+}
+
+func NewOrbPolygonFromGeomPolygon(geomPolygon *geom.Polygon, options ...Option) orb.Polygon {
+	orbPolygon, err := NewOrbPolygonFromGeomPolygonE(geomPolygon, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbPolygon
+}
+
+// NewOrbPolygonFromGeomPolygonE returns a new orb.Polygon converted from
+// geomPolygon. It returns a *ConvertError wrapping ErrUnsupportedLayout if
+// geomPolygon has Z or M ordinates that the ZM policy does not allow to be
+// dropped.
+func NewOrbPolygonFromGeomPolygonE(geomPolygon *geom.Polygon, options ...Option) (orb.Polygon, error) {
+	c := newConverter(options)
+	if err := c.checkGeomTForOrb(geomPolygon); err != nil {
+		return nil, err
+	}
+	return c.orbPolygonFromGeomPolygon(geomPolygon), nil
+}
+
+func NewOrbMultiPointFromGeomMultiPoint(geomMultiPoint *geom.MultiPoint, options ...Option) orb.MultiPoint {
+	orbMultiPoint, err := NewOrbMultiPointFromGeomMultiPointE(geomMultiPoint, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbMultiPoint
+}
+
+// NewOrbMultiPointFromGeomMultiPointE returns a new orb.MultiPoint converted
+// from geomMultiPoint. It returns a *ConvertError wrapping ErrUnsupportedLayout
+// if geomMultiPoint has Z or M ordinates that the ZM policy does not allow to
+// be dropped.
+func NewOrbMultiPointFromGeomMultiPointE(geomMultiPoint *geom.MultiPoint, options ...Option) (orb.MultiPoint, error) {
+	c := newConverter(options)
+	if err := c.checkGeomTForOrb(geomMultiPoint); err != nil {
+		return nil, err
+	}
+	return c.orbMultiPointFromGeomMultiPoint(geomMultiPoint), nil
+}
+
+func NewOrbMultiLineStringFromGeomMultiLineString(geomMultiLineString *geom.MultiLineString, options ...Option) orb.MultiLineString {
+	orbMultiLineString, err := NewOrbMultiLineStringFromGeomMultiLineStringE(geomMultiLineString, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbMultiLineString
+}
+
+// NewOrbMultiLineStringFromGeomMultiLineStringE returns a new
+// orb.MultiLineString converted from geomMultiLineString. It returns a
+// *ConvertError wrapping ErrUnsupportedLayout if geomMultiLineString has Z or M
+// ordinates that the ZM policy does not allow to be dropped.
+func NewOrbMultiLineStringFromGeomMultiLineStringE(geomMultiLineString *geom.MultiLineString, options ...Option) (orb.MultiLineString, error) {
+	c := newConverter(options)
+	if err := c.checkGeomTForOrb(geomMultiLineString); err != nil {
+		return nil, err
+	}
+	return c.orbMultiLineStringFromGeomMultiLineString(geomMultiLineString), nil
+}
+
+func NewOrbMultiPolygonFromGeomMultiPolygon(geomMultiPolygon *geom.MultiPolygon, options ...Option) orb.MultiPolygon {
+	orbMultiPolygon, err := NewOrbMultiPolygonFromGeomMultiPolygonE(geomMultiPolygon, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbMultiPolygon
+}
+
+// NewOrbMultiPolygonFromGeomMultiPolygonE returns a new orb.MultiPolygon
+// converted from geomMultiPolygon. It returns a *ConvertError wrapping
+// ErrUnsupportedLayout if geomMultiPolygon has Z or M ordinates that the ZM
+// policy does not allow to be dropped.
+func NewOrbMultiPolygonFromGeomMultiPolygonE(geomMultiPolygon *geom.MultiPolygon, options ...Option) (orb.MultiPolygon, error) {
+	c := newConverter(options)
+	if err := c.checkGeomTForOrb(geomMultiPolygon); err != nil {
+		return nil, err
+	}
+	return c.orbMultiPolygonFromGeomMultiPolygon(geomMultiPolygon), nil
+}
+
+func NewOrbCollectionFromGeomGeometryCollection(geomGeometryCollection *geom.GeometryCollection, options ...Option) orb.Collection {
+	orbCollection, err := NewOrbCollectionFromGeomGeometryCollectionE(geomGeometryCollection, options...)
+	if err != nil {
+		panic(err)
 	}
+	return orbCollection
+}
+
+// NewOrbCollectionFromGeomGeometryCollectionE returns a new orb.Collection
+// converted from geomGeometryCollection. It returns a *ConvertError if any
+// member of geomGeometryCollection cannot be converted.
+func NewOrbCollectionFromGeomGeometryCollectionE(geomGeometryCollection *geom.GeometryCollection, options ...Option) (orb.Collection, error) {
+	return newConverter(options).orbCollectionFromGeomGeometryCollection(geomGeometryCollection)
 }
 
-func orbPointFromGeomPoint(geomPoint *geom.Point) orb.Point {
-	geomFlatCoords := geomPoint.FlatCoords()
-	return orb.Point{geomFlatCoords[0], geomFlatCoords[1]}
+func (c *converter) orbPointFromGeomPoint(geomPoint *geom.Point) orb.Point {
+	return c.orbPointFromGeomFlatCoords(geomPoint.FlatCoords())
 }
 
-func orbLineStringFromGeomLineString(geomLineString *geom.LineString) orb.LineString {
-	geomFlatCoords := geomLineString.FlatCoords()
-	geomStride := geomLineString.Stride()
+func (c *converter) orbLineStringFromGeomLineString(geomLineString *geom.LineString) orb.LineString {
+	return c.orbLineStringFromGeomFlatCoords(geomLineString.FlatCoords(), geomLineString.Stride())
+}
+
+func (c *converter) orbRingFromGeomLinearRing(geomLinearRing *geom.LinearRing) orb.Ring {
+	return c.orbRingFromGeomFlatCoords(geomLinearRing.FlatCoords(), geomLinearRing.Stride())
+}
+
+func (c *converter) orbPolygonFromGeomPolygon(geomPolygon *geom.Polygon) orb.Polygon {
+	return c.orbPolygonFromGeomFlatCoords(geomPolygon.FlatCoords(), geomPolygon.Ends(), geomPolygon.Stride())
+}
+
+func (c *converter) orbMultiPointFromGeomMultiPoint(geomMultiPoint *geom.MultiPoint) orb.MultiPoint {
+	geomNumPoints := geomMultiPoint.NumPoints()
+	orbMultiPoint := make(orb.MultiPoint, 0, geomNumPoints)
+	for i := 0; i < geomNumPoints; i++ {
+		if geomCoord := geomMultiPoint.Coord(i); geomCoord != nil {
+			orbMultiPoint = appendOrbPointFromGeomCoord(orbMultiPoint, geomCoord, c.transform)
+		} else {
+			orbMultiPoint = append(orbMultiPoint, orbEmptyPoint())
+		}
+	}
+	return orbMultiPoint
+}
+
+func (c *converter) orbMultiLineStringFromGeomMultiLineString(geomMultiLineString *geom.MultiLineString) orb.MultiLineString {
+	return c.orbMultiLineStringFromGeomFlatCoords(geomMultiLineString.FlatCoords(), geomMultiLineString.Ends(), geomMultiLineString.Stride())
+}
+
+func (c *converter) orbMultiPolygonFromGeomMultiPolygon(geomMultiPolygon *geom.MultiPolygon) orb.MultiPolygon {
+	return c.orbMultiPolygonFromGeomFlatCoords(geomMultiPolygon.FlatCoords(), geomMultiPolygon.Endss(), geomMultiPolygon.Stride())
+}
+
+func (c *converter) orbCollectionFromGeomGeometryCollection(geomGeometryCollection *geom.GeometryCollection) (orb.Collection, error) {
+	geomNumGeoms := geomGeometryCollection.NumGeoms()
+	orbCollection := make(orb.Collection, 0, geomNumGeoms)
+	for i := 0; i < geomNumGeoms; i++ {
+		geomT := geomGeometryCollection.Geom(i)
+		orbGeometry, err := c.newOrbGeometryFromGeomT(geomT)
+		if err != nil {
+			return nil, withPathIndex(err, i)
+		}
+		orbCollection = append(orbCollection, orbGeometry)
+	}
+	return orbCollection, nil
+}
+
+// NewOrbPointFromGeomFlatCoords returns a new orb.Point converted from the
+// first point of geomFlatCoords. Empty flat coordinates are converted to an
+// empty point.
+func NewOrbPointFromGeomFlatCoords(geomFlatCoords []float64, options ...Option) orb.Point {
+	return newConverter(options).orbPointFromGeomFlatCoords(geomFlatCoords)
+}
+
+// NewOrbLineStringFromGeomFlatCoords returns a new orb.LineString converted
+// from geomFlatCoords, which has stride geomStride.
+func NewOrbLineStringFromGeomFlatCoords(geomFlatCoords []float64, geomStride int, options ...Option) orb.LineString {
+	return newConverter(options).orbLineStringFromGeomFlatCoords(geomFlatCoords, geomStride)
+}
+
+// NewOrbRingFromGeomFlatCoords returns a new orb.Ring converted from
+// geomFlatCoords, which has stride geomStride.
+func NewOrbRingFromGeomFlatCoords(geomFlatCoords []float64, geomStride int, options ...Option) orb.Ring {
+	return newConverter(options).orbRingFromGeomFlatCoords(geomFlatCoords, geomStride)
+}
+
+// NewOrbPolygonFromGeomFlatCoords returns a new orb.Polygon converted from
+// geomFlatCoords with geomEnds, which has stride geomStride.
+func NewOrbPolygonFromGeomFlatCoords(geomFlatCoords []float64, geomEnds []int, geomStride int, options ...Option) orb.Polygon {
+	return newConverter(options).orbPolygonFromGeomFlatCoords(geomFlatCoords, geomEnds, geomStride)
+}
+
+// NewOrbMultiPointFromGeomFlatCoords returns a new orb.MultiPoint converted
+// from geomFlatCoords, which has stride geomStride.
+func NewOrbMultiPointFromGeomFlatCoords(geomFlatCoords []float64, geomStride int, options ...Option) orb.MultiPoint {
+	return newConverter(options).orbMultiPointFromGeomFlatCoords(geomFlatCoords, geomStride)
+}
+
+// NewOrbMultiLineStringFromGeomFlatCoords returns a new orb.MultiLineString
+// converted from geomFlatCoords with geomEnds, which has stride geomStride.
+func NewOrbMultiLineStringFromGeomFlatCoords(geomFlatCoords []float64, geomEnds []int, geomStride int, options ...Option) orb.MultiLineString {
+	return newConverter(options).orbMultiLineStringFromGeomFlatCoords(geomFlatCoords, geomEnds, geomStride)
+}
+
+// NewOrbMultiPolygonFromGeomFlatCoords returns a new orb.MultiPolygon converted
+// from geomFlatCoords with geomEndss, which has stride geomStride.
+func NewOrbMultiPolygonFromGeomFlatCoords(geomFlatCoords []float64, geomEndss [][]int, geomStride int, options ...Option) orb.MultiPolygon {
+	return newConverter(options).orbMultiPolygonFromGeomFlatCoords(geomFlatCoords, geomEndss, geomStride)
+}
+
+func (c *converter) orbPointFromGeomFlatCoords(geomFlatCoords []float64) orb.Point {
+	if len(geomFlatCoords) == 0 {
+		return orbEmptyPoint()
+	}
+	var orbPoints [1]orb.Point
+	return appendOrbPointFromGeomCoord(orbPoints[:0], geomFlatCoords, c.transform)[0]
+}
+
+func (c *converter) orbLineStringFromGeomFlatCoords(geomFlatCoords []float64, geomStride int) orb.LineString {
 	orbLineString := make(orb.LineString, 0, len(geomFlatCoords)/geomStride)
-	for i := 0; i < len(geomFlatCoords); i += geomStride {
-		orbPoint := orb.Point{geomFlatCoords[i], geomFlatCoords[i+1]}
-		orbLineString = append(orbLineString, orbPoint)
+	orbLineString = appendOrbPointsFromGeomFlatCoords(orbLineString, geomFlatCoords, geomStride, c.transform)
+	if c.removeRepeatedPoints() {
+		orbLineString = orbRemoveRepeatedPoints(orbLineString, minLineStringPoints)
 	}
 	return orbLineString
 }
 
-func orbRingFromGeomLinearRing(geomLinearRing *geom.LinearRing) orb.Ring {
-	geomFlatCoords := geomLinearRing.FlatCoords()
-	geomStride := geomLinearRing.Stride()
+func (c *converter) orbRingFromGeomFlatCoords(geomFlatCoords []float64, geomStride int) orb.Ring {
 	orbRing := make(orb.Ring, 0, len(geomFlatCoords)/geomStride)
-	for i := 0; i < len(geomFlatCoords); i += geomStride {
-		orbPoint := orb.Point{geomFlatCoords[i], geomFlatCoords[i+1]}
-		orbRing = append(orbRing, orbPoint)
+	orbRing = appendOrbPointsFromGeomFlatCoords(orbRing, geomFlatCoords, geomStride, c.transform)
+	if c.removeRepeatedPoints() {
+		orbRing = orbRemoveRepeatedPoints(orbRing, minRingPoints)
 	}
 	return orbRing
 }
 
-func orbPolygonFromGeomPolygon(geomPolygon *geom.Polygon) orb.Polygon {
-	geomFlatCoords := geomPolygon.FlatCoords()
-	geomEnds := geomPolygon.Ends()
-	geomStride := geomPolygon.Stride()
-	geomStart := 0
+// orbPolygonFromGeomFlatCoords returns a new orb.Polygon converted from
+// geomFlatCoords with geomEnds. All rings share a single backing array.
+func (c *converter) orbPolygonFromGeomFlatCoords(geomFlatCoords []float64, geomEnds []int, geomStride int) orb.Polygon {
+	orbPoints := appendOrbPointsFromGeomFlatCoords(make([]orb.Point, 0, len(geomFlatCoords)/geomStride), geomFlatCoords, geomStride, c.transform)
 	orbPolygon := make(orb.Polygon, 0, len(geomEnds))
+	orbStart := 0
 	for _, geomEnd := range geomEnds {
-		orbRing := make(orb.Ring, 0, (geomEnd-geomStart)/geomStride)
-		for i := geomStart; i < geomEnd; i += geomStride {
-			orbPoint := orb.Point{geomFlatCoords[i], geomFlatCoords[i+1]}
-			orbRing = append(orbRing, orbPoint)
-		}
-		orbPolygon = append(orbPolygon, orbRing)
-		geomStart = geomEnd
+		orbEnd := geomEnd / geomStride
+		orbPolygon = append(orbPolygon, orb.Ring(orbPoints[orbStart:orbEnd:orbEnd]))
+		orbStart = orbEnd
 	}
+	if c.removeRepeatedPoints() {
+		orbRemoveRepeatedPolygonPoints(orbPolygon)
+	}
+	orbOrientPolygon(orbPolygon, c.orientation)
 	return orbPolygon
 }
 
-func orbMultiPointFromGeomMultiPoint(geomMultiPoint *geom.MultiPoint) orb.MultiPoint {
-	geomFlatCoords := geomMultiPoint.FlatCoords()
-	geomStride := geomMultiPoint.Stride()
+func (c *converter) orbMultiPointFromGeomFlatCoords(geomFlatCoords []float64, geomStride int) orb.MultiPoint {
 	orbMultiPoint := make(orb.MultiPoint, 0, len(geomFlatCoords)/geomStride)
-	for i := 0; i < len(geomFlatCoords); i += geomStride {
-		orbPoint := orb.Point{geomFlatCoords[i], geomFlatCoords[i+1]}
-		orbMultiPoint = append(orbMultiPoint, orbPoint)
-	}
-	return orbMultiPoint
+	return appendOrbPointsFromGeomFlatCoords(orbMultiPoint, geomFlatCoords, geomStride, c.transform)
 }
 
-func orbMultiLineStringFromGeomMultiLineString(geomMultiLineString *geom.MultiLineString) orb.MultiLineString {
-	geomFlatCoords := geomMultiLineString.FlatCoords()
-	geomEnds := geomMultiLineString.Ends()
-	geomStride := geomMultiLineString.Stride()
-	geomStart := 0
+func (c *converter) orbMultiLineStringFromGeomFlatCoords(geomFlatCoords []float64, geomEnds []int, geomStride int) orb.MultiLineString {
 	orbMultiLineString := make(orb.MultiLineString, 0, len(geomEnds))
-	for _, geomEnd := range geomEnds {
-		orbLineString := make(orb.LineString, 0, (geomEnd-geomStart)/geomStride)
-		for i := geomStart; i < geomEnd; i += geomStride {
-			orbPoint := orb.Point{geomFlatCoords[i], geomFlatCoords[i+1]}
-			orbLineString = append(orbLineString, orbPoint)
+	orbMultiLineString = appendOrbMultiLineStringFromGeomFlatCoords(orbMultiLineString, geomFlatCoords, geomEnds, geomStride, c.transform)
+	if c.removeRepeatedPoints() {
+		for i, orbLineString := range orbMultiLineString {
+			orbMultiLineString[i] = orbRemoveRepeatedPoints(orbLineString, minLineStringPoints)
 		}
-		orbMultiLineString = append(orbMultiLineString, orbLineString)
-		geomStart = geomEnd
 	}
 	return orbMultiLineString
 }
 
-func orbMultiPolygonFromGeomMultiPolygon(geomMultiPolygon *geom.MultiPolygon) orb.MultiPolygon {
-	geomFlatCoords := geomMultiPolygon.FlatCoords()
-	geomEndss := geomMultiPolygon.Endss()
-	geomStride := geomMultiPolygon.Stride()
-	geomStart := 0
+func (c *converter) orbMultiPolygonFromGeomFlatCoords(geomFlatCoords []float64, geomEndss [][]int, geomStride int) orb.MultiPolygon {
 	orbMultiPolygon := make(orb.MultiPolygon, 0, len(geomEndss))
-	for _, geomEnds := range geomEndss {
-		orbPolygon := make(orb.Polygon, 0, len(geomEnds))
-		for _, geomEnd := range geomEnds {
-			orbRing := make(orb.Ring, 0, (geomEnd-geomStart)/geomStride)
-			for i := geomStart; i < geomEnd; i += geomStride {
-				orbPoint := orb.Point{geomFlatCoords[i], geomFlatCoords[i+1]}
-				orbRing = append(orbRing, orbPoint)
-			}
-			orbPolygon = append(orbPolygon, orbRing)
-			geomStart = geomEnd
+	orbMultiPolygon = appendOrbMultiPolygonFromGeomFlatCoords(orbMultiPolygon, geomFlatCoords, geomEndss, geomStride, c.transform)
+	for _, orbPolygon := range orbMultiPolygon {
+		if c.removeRepeatedPoints() {
+			orbRemoveRepeatedPolygonPoints(orbPolygon)
 		}
-		orbMultiPolygon = append(orbMultiPolygon, orbPolygon)
+		orbOrientPolygon(orbPolygon, c.orientation)
 	}
 	return orbMultiPolygon
 }
 
-func orbCollectionFromGeomGeometryCollection(geomGeometryCollection *geom.GeometryCollection) orb.Collection {
-	geomNumGeoms := geomGeometryCollection.NumGeoms()
-	orbCollection := make(orb.Collection, 0, geomNumGeoms)
-	for i := 0; i < geomNumGeoms; i++ {
-		geomT := geomGeometryCollection.Geom(i)
-		orbGeometry := NewOrbGeometryFromGeomT(geomT)
-		orbCollection = append(orbCollection, orbGeometry)
-	}
-	return orbCollection
+// orbEmptyPoint returns the orb.Point that represents an empty point.
+func orbEmptyPoint() orb.Point {
+	return orb.Point{math.NaN(), math.NaN()}
 }
@@ -1,108 +1,442 @@
 package geobabel
 
 import (
-	"fmt"
-
 	"github.com/paulmach/orb"
+	"github.com/twpayne/go-geom"
 	"github.com/twpayne/go-geos"
 )
 
-func NewOrbGeometryFromGEOSGeom(geosGeom *geos.Geom) orb.Geometry {
+func NewOrbGeometryFromGEOSGeom(geosGeom *geos.Geom, options ...Option) orb.Geometry {
+	orbGeometry, err := NewOrbGeometryFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbGeometry
+}
+
+// NewOrbGeometryFromGEOSGeomE returns a new orb.Geometry converted from
+// geosGeom. It returns a *ConvertError if geosGeom cannot be converted.
+func NewOrbGeometryFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (orb.Geometry, error) {
+	c := newConverter(options)
+	repairedGeomT, err := c.validateSourceGEOSGeom(geosGeom)
+	switch {
+	case err != nil:
+		return nil, err
+	case repairedGeomT != nil:
+		return c.newOrbGeometryFromGeomT(repairedGeomT)
+	default:
+		return c.newOrbGeometryFromGEOSGeom(geosGeom)
+	}
+}
+
+func (c *converter) newOrbGeometryFromGEOSGeom(geosGeom *geos.Geom) (orb.Geometry, error) {
+	if err := c.checkGEOSGeomForOrb(geosGeom); err != nil {
+		return nil, err
+	}
 	switch geosGeom.TypeID() {
 	case geos.TypeIDPoint:
-		return NewOrbPointFromGEOSGeom(geosGeom)
+		return c.newOrbPointFromGEOSGeom(geosGeom), nil
 	case geos.TypeIDLineString:
-		return NewOrbLineStringFromGEOSGeom(geosGeom)
+		return c.newOrbLineStringFromGEOSGeom(geosGeom), nil
 	case geos.TypeIDLinearRing:
-		return NewOrbRingFromGEOSGeom(geosGeom)
+		return c.newOrbRingFromGEOSGeom(geosGeom), nil
 	case geos.TypeIDPolygon:
-		return NewOrbPolygonFromGEOSGeom(geosGeom)
+		return c.newOrbPolygonFromGEOSGeom(geosGeom), nil
 	case geos.TypeIDMultiPoint:
-		return NewOrbMultiPointFromGEOSGeom(geosGeom)
+		return c.newOrbMultiPointFromGEOSGeom(geosGeom), nil
 	case geos.TypeIDMultiLineString:
-		return NewOrbMultiLineStringFromGEOSGeom(geosGeom)
+		return c.newOrbMultiLineStringFromGEOSGeom(geosGeom), nil
 	case geos.TypeIDMultiPolygon:
-		return NewOrbMultiPolygonFromGEOSGeom(geosGeom)
+		return c.newOrbMultiPolygonFromGEOSGeom(geosGeom), nil
 	case geos.TypeIDGeometryCollection:
-		return NewOrbCollectionFromGEOSGeom(geosGeom)
+		orbCollection, err := c.newOrbCollectionFromGEOSGeom(geosGeom)
+		if err != nil {
+			return nil, err
+		}
+		return orbCollection, nil
 	default:
-		panic(fmt.Sprintf("%s: unsupported GEOS type", geosGeom.Type()))
+		return nil, newUnsupportedTypeError(geosGeom.Type())
 	}
 }
 
-func NewOrbPointFromGEOSGeom(geosGeom *geos.Geom) orb.Point {
-	geosCoords := geosGeom.CoordSeq().ToCoords()
-	return orb.Point{geosCoords[0][0], geosCoords[0][1]}
+func NewOrbPointFromGEOSGeom(geosGeom *geos.Geom, options ...Option) orb.Point {
+	orbPoint, err := NewOrbPointFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbPoint
 }
 
-func NewOrbLineStringFromGEOSGeom(geosGeom *geos.Geom) orb.LineString {
-	geosCoords := geosGeom.CoordSeq().ToCoords()
-	orbLineString := make(orb.LineString, 0, len(geosCoords))
-	for _, coord := range geosCoords {
-		orbPoint := orb.Point{coord[0], coord[1]}
-		orbLineString = append(orbLineString, orbPoint)
+// NewOrbPointFromGEOSGeomE returns a new orb.Point converted from geosGeom. It
+// returns a *ConvertError wrapping ErrUnexpectedType if geosGeom is not a
+// point.
+func NewOrbPointFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (orb.Point, error) {
+	c := newConverter(options)
+	geosGeom, err := c.checkGEOSGeomTypeID(geosGeom, geos.TypeIDPoint)
+	if err != nil {
+		return orb.Point{}, err
+	}
+	return c.newOrbPointFromGEOSGeom(geosGeom), nil
+}
+
+func NewOrbLineStringFromGEOSGeom(geosGeom *geos.Geom, options ...Option) orb.LineString {
+	orbLineString, err := NewOrbLineStringFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
 	}
 	return orbLineString
 }
 
-func NewOrbRingFromGEOSGeom(geosGeom *geos.Geom) orb.Ring {
-	geosCoords := geosGeom.CoordSeq().ToCoords()
-	orbRing := make(orb.Ring, 0, len(geosCoords))
-	for _, coord := range geosCoords {
-		orbPoint := orb.Point{coord[0], coord[1]}
-		orbRing = append(orbRing, orbPoint)
+// NewOrbLineStringFromGEOSGeomE returns a new orb.LineString converted from
+// geosGeom. It returns a *ConvertError wrapping ErrUnexpectedType if geosGeom
+// is not a line string.
+func NewOrbLineStringFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (orb.LineString, error) {
+	c := newConverter(options)
+	geosGeom, err := c.checkGEOSGeomTypeID(geosGeom, geos.TypeIDLineString)
+	if err != nil {
+		return nil, err
+	}
+	return c.newOrbLineStringFromGEOSGeom(geosGeom), nil
+}
+
+func NewOrbRingFromGEOSGeom(geosGeom *geos.Geom, options ...Option) orb.Ring {
+	orbRing, err := NewOrbRingFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbRing
+}
+
+// NewOrbRingFromGEOSGeomE returns a new orb.Ring converted from geosGeom. It
+// returns a *ConvertError wrapping ErrUnexpectedType if geosGeom is not a
+// linear ring.
+func NewOrbRingFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (orb.Ring, error) {
+	c := newConverter(options)
+	geosGeom, err := c.checkGEOSGeomTypeID(geosGeom, geos.TypeIDLinearRing)
+	if err != nil {
+		return nil, err
+	}
+	return c.newOrbRingFromGEOSGeom(geosGeom), nil
+}
+
+func NewOrbPolygonFromGEOSGeom(geosGeom *geos.Geom, options ...Option) orb.Polygon {
+	orbPolygon, err := NewOrbPolygonFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbPolygon
+}
+
+// NewOrbPolygonFromGEOSGeomE returns a new orb.Polygon converted from
+// geosGeom. It returns a *ConvertError wrapping ErrUnexpectedType if geosGeom
+// is not a polygon.
+func NewOrbPolygonFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (orb.Polygon, error) {
+	c := newConverter(options)
+	geosGeom, err := c.checkGEOSGeomTypeID(geosGeom, geos.TypeIDPolygon)
+	if err != nil {
+		return nil, err
+	}
+	return c.newOrbPolygonFromGEOSGeom(geosGeom), nil
+}
+
+func NewOrbMultiPointFromGEOSGeom(geosGeom *geos.Geom, options ...Option) orb.MultiPoint {
+	orbMultiPoint, err := NewOrbMultiPointFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbMultiPoint
+}
+
+// NewOrbMultiPointFromGEOSGeomE returns a new orb.MultiPoint converted from
+// geosGeom. It returns a *ConvertError wrapping ErrUnexpectedType if geosGeom
+// is not a multipoint.
+func NewOrbMultiPointFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (orb.MultiPoint, error) {
+	c := newConverter(options)
+	geosGeom, err := c.checkGEOSGeomTypeID(geosGeom, geos.TypeIDMultiPoint)
+	switch {
+	case err != nil:
+		return nil, err
+	case geosGeom.TypeID() != geos.TypeIDPoint:
+		return c.newOrbMultiPointFromGEOSGeom(geosGeom), nil
+	case geosGeom.IsEmpty():
+		return orb.MultiPoint{}, nil
+	default:
+		return orb.MultiPoint{c.newOrbPointFromGEOSGeom(geosGeom)}, nil
+	}
+}
+
+func NewOrbMultiLineStringFromGEOSGeom(geosGeom *geos.Geom, options ...Option) orb.MultiLineString {
+	orbMultiLineString, err := NewOrbMultiLineStringFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbMultiLineString
+}
+
+// NewOrbMultiLineStringFromGEOSGeomE returns a new orb.MultiLineString
+// converted from geosGeom. It returns a *ConvertError wrapping
+// ErrUnexpectedType if geosGeom is not a multilinestring.
+func NewOrbMultiLineStringFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (orb.MultiLineString, error) {
+	c := newConverter(options)
+	geosGeom, err := c.checkGEOSGeomTypeID(geosGeom, geos.TypeIDMultiLineString)
+	switch {
+	case err != nil:
+		return nil, err
+	case geosGeom.TypeID() != geos.TypeIDLineString:
+		return c.newOrbMultiLineStringFromGEOSGeom(geosGeom), nil
+	case geosGeom.IsEmpty():
+		return orb.MultiLineString{}, nil
+	default:
+		return orb.MultiLineString{c.newOrbLineStringFromGEOSGeom(geosGeom)}, nil
+	}
+}
+
+func NewOrbMultiPolygonFromGEOSGeom(geosGeom *geos.Geom, options ...Option) orb.MultiPolygon {
+	orbMultiPolygon, err := NewOrbMultiPolygonFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbMultiPolygon
+}
+
+// NewOrbMultiPolygonFromGEOSGeomE returns a new orb.MultiPolygon converted
+// from geosGeom. It returns a *ConvertError wrapping ErrUnexpectedType if
+// geosGeom is not a multipolygon.
+func NewOrbMultiPolygonFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (orb.MultiPolygon, error) {
+	c := newConverter(options)
+	geosGeom, err := c.checkGEOSGeomTypeID(geosGeom, geos.TypeIDMultiPolygon)
+	switch {
+	case err != nil:
+		return nil, err
+	case geosGeom.TypeID() != geos.TypeIDPolygon:
+		return c.newOrbMultiPolygonFromGEOSGeom(geosGeom), nil
+	case geosGeom.IsEmpty():
+		return orb.MultiPolygon{}, nil
+	default:
+		return orb.MultiPolygon{c.newOrbPolygonFromGEOSGeom(geosGeom)}, nil
+	}
+}
+
+func NewOrbCollectionFromGEOSGeom(geosGeom *geos.Geom, options ...Option) orb.Collection {
+	orbCollection, err := NewOrbCollectionFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return orbCollection
+}
+
+// NewOrbCollectionFromGEOSGeomE returns a new orb.Collection converted from
+// geosGeom. It returns a *ConvertError wrapping ErrUnexpectedType if geosGeom
+// is not a geometry collection.
+func NewOrbCollectionFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) (orb.Collection, error) {
+	c := newConverter(options)
+	geosGeom, err := c.checkGEOSGeomTypeID(geosGeom, geos.TypeIDGeometryCollection)
+	switch {
+	case err != nil:
+		return nil, err
+	case geosGeom.TypeID() == geos.TypeIDGeometryCollection:
+		return c.newOrbCollectionFromGEOSGeom(geosGeom)
+	default:
+		orbGeometry, err := c.newOrbGeometryFromGEOSGeom(geosGeom)
+		if err != nil {
+			return nil, err
+		}
+		return orb.Collection{orbGeometry}, nil
+	}
+}
+
+func (c *converter) newOrbPointFromGEOSGeom(geosGeom *geos.Geom) orb.Point {
+	if geosGeom.IsEmpty() {
+		return orbEmptyPoint()
+	}
+	var orbPoints [1]orb.Point
+	return appendOrbPointsFromGEOSGeom(orbPoints[:0], geosGeom, c.transform)[0]
+}
+
+func (c *converter) newOrbLineStringFromGEOSGeom(geosGeom *geos.Geom) orb.LineString {
+	orbLineString := appendOrbPointsFromGEOSGeom(orb.LineString(nil), geosGeom, c.transform)
+	if c.removeRepeatedPoints() {
+		orbLineString = orbRemoveRepeatedPoints(orbLineString, minLineStringPoints)
+	}
+	return orbLineString
+}
+
+func (c *converter) newOrbRingFromGEOSGeom(geosGeom *geos.Geom) orb.Ring {
+	orbRing := appendOrbPointsFromGEOSGeom(orb.Ring(nil), geosGeom, c.transform)
+	if c.removeRepeatedPoints() {
+		orbRing = orbRemoveRepeatedPoints(orbRing, minRingPoints)
 	}
 	return orbRing
 }
 
-func NewOrbPolygonFromGEOSGeom(geosGeom *geos.Geom) orb.Polygon {
+func (c *converter) newOrbPolygonFromGEOSGeom(geosGeom *geos.Geom) orb.Polygon {
+	if geosGeom.IsEmpty() {
+		return orb.Polygon{}
+	}
 	geosNumInteriorRings := geosGeom.NumInteriorRings()
 	orbPolygon := make(orb.Polygon, 0, 1+geosNumInteriorRings)
-	orbRing := NewOrbGeometryFromGEOSGeom(geosGeom.ExteriorRing()).(orb.Ring) //nolint:forcetypeassert
-	orbPolygon = append(orbPolygon, orbRing)
+	orbPolygon = append(orbPolygon, c.newOrbRingFromGEOSGeom(geosGeom.ExteriorRing()))
 	for i := 0; i < geosNumInteriorRings; i++ {
-		orbRing := NewOrbGeometryFromGEOSGeom(geosGeom.InteriorRing(i)).(orb.Ring) //nolint:forcetypeassert
-		orbPolygon = append(orbPolygon, orbRing)
+		orbPolygon = append(orbPolygon, c.newOrbRingFromGEOSGeom(geosGeom.InteriorRing(i)))
 	}
+	orbOrientPolygon(orbPolygon, c.orientation)
 	return orbPolygon
 }
 
-func NewOrbMultiPointFromGEOSGeom(geosGeom *geos.Geom) orb.MultiPoint {
+func (c *converter) newOrbMultiPointFromGEOSGeom(geosGeom *geos.Geom) orb.MultiPoint {
 	geosNumGeometries := geosGeom.NumGeometries()
 	orbMultiPoint := make(orb.MultiPoint, 0, geosNumGeometries)
 	for i := 0; i < geosNumGeometries; i++ {
-		orbPoint := NewOrbGeometryFromGEOSGeom(geosGeom.Geometry(i)).(orb.Point) //nolint:forcetypeassert
-		orbMultiPoint = append(orbMultiPoint, orbPoint)
+		orbMultiPoint = append(orbMultiPoint, c.newOrbPointFromGEOSGeom(geosGeom.Geometry(i)))
 	}
 	return orbMultiPoint
 }
 
-func NewOrbMultiLineStringFromGEOSGeom(geosGeom *geos.Geom) orb.MultiLineString {
+func (c *converter) newOrbMultiLineStringFromGEOSGeom(geosGeom *geos.Geom) orb.MultiLineString {
 	geosNumGeometries := geosGeom.NumGeometries()
 	orbMultiLineString := make(orb.MultiLineString, 0, geosNumGeometries)
 	for i := 0; i < geosNumGeometries; i++ {
-		orbLineString := NewOrbGeometryFromGEOSGeom(geosGeom.Geometry(i)).(orb.LineString) //nolint:forcetypeassert
-		orbMultiLineString = append(orbMultiLineString, orbLineString)
+		orbMultiLineString = append(orbMultiLineString, c.newOrbLineStringFromGEOSGeom(geosGeom.Geometry(i)))
 	}
 	return orbMultiLineString
 }
 
-func NewOrbMultiPolygonFromGEOSGeom(geosGeom *geos.Geom) orb.MultiPolygon {
+func (c *converter) newOrbMultiPolygonFromGEOSGeom(geosGeom *geos.Geom) orb.MultiPolygon {
 	geosNumGeometries := geosGeom.NumGeometries()
 	orbMultiPolygon := make(orb.MultiPolygon, 0, geosNumGeometries)
 	for i := 0; i < geosNumGeometries; i++ {
-		orbPolygon := NewOrbGeometryFromGEOSGeom(geosGeom.Geometry(i)).(orb.Polygon) //nolint:forcetypeassert
-		orbMultiPolygon = append(orbMultiPolygon, orbPolygon)
+		orbMultiPolygon = append(orbMultiPolygon, c.newOrbPolygonFromGEOSGeom(geosGeom.Geometry(i)))
 	}
 	return orbMultiPolygon
 }
 
-func NewOrbCollectionFromGEOSGeom(geosGeom *geos.Geom) orb.Collection {
+// appendOrbPointsFromGEOSGeom appends the points of geosGeom's coordinate
+// sequence to dst, applying transform if it is not nil.
+func appendOrbPointsFromGEOSGeom[S ~[]orb.Point](dst S, geosGeom *geos.Geom, transform TransformFunc) S {
+	geosCoords := geosGeom.CoordSeq().ToCoords()
+	if dst == nil {
+		dst = make(S, 0, len(geosCoords))
+	}
+	for _, geosCoord := range geosCoords {
+		dst = appendOrbPointFromGeomCoord(dst, geosCoord, transform)
+	}
+	return dst
+}
+
+func (c *converter) newOrbCollectionFromGEOSGeom(geosGeom *geos.Geom) (orb.Collection, error) {
 	geosNumGeometries := geosGeom.NumGeometries()
 	orbCollection := make(orb.Collection, 0, geosNumGeometries)
 	for i := 0; i < geosNumGeometries; i++ {
-		orbGeometry := NewOrbGeometryFromGEOSGeom(geosGeom.Geometry(i))
+		orbGeometry, err := c.newOrbGeometryFromGEOSGeom(geosGeom.Geometry(i))
+		if err != nil {
+			return nil, withPathIndex(err, i)
+		}
 		orbCollection = append(orbCollection, orbGeometry)
 	}
-	return orbCollection
+	return orbCollection, nil
+}
+
+// geosTypeIDNames are the names of GEOS geometry types, as returned by
+// (*geos.Geom).Type.
+var geosTypeIDNames = map[geos.TypeID]string{
+	geos.TypeIDPoint:              "Point",
+	geos.TypeIDLineString:         "LineString",
+	geos.TypeIDLinearRing:         "LinearRing",
+	geos.TypeIDPolygon:            "Polygon",
+	geos.TypeIDMultiPoint:         "MultiPoint",
+	geos.TypeIDMultiLineString:    "MultiLineString",
+	geos.TypeIDMultiPolygon:       "MultiPolygon",
+	geos.TypeIDGeometryCollection: "GeometryCollection",
+}
+
+// checkGEOSGeomTypeID returns the geometry to convert to an orb geometry with
+// typeID, as returned by geosGeomWithTypeID. It also returns an error if the
+// geometry cannot be converted to orb.
+func (c *converter) checkGEOSGeomTypeID(geosGeom *geos.Geom, typeID geos.TypeID) (*geos.Geom, error) {
+	geosGeom, err := c.geosGeomWithTypeID(geosGeom, typeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkGEOSGeomForOrb(geosGeom); err != nil {
+		return nil, err
+	}
+	return geosGeom, nil
+}
+
+// geosGeomWithTypeID returns the geometry to convert to a geometry with
+// typeID, which is either geosGeom or, if c unwraps singletons, its only
+// member. The returned geometry either has typeID or, if c promotes to multi
+// geometries, a type that can be promoted to typeID. It returns an error if no
+// such geometry exists.
+func (c *converter) geosGeomWithTypeID(geosGeom *geos.Geom, typeID geos.TypeID) (*geos.Geom, error) {
+	for c.unwrapSingletons && geosGeom.TypeID() != typeID && geosTypeIDIsCollection(geosGeom.TypeID()) && geosGeom.NumGeometries() == 1 {
+		geosGeom = geosGeom.Geometry(0)
+	}
+	if geosGeom.TypeID() != typeID && !(c.promoteToMulti && geosTypeIDPromotesTo(geosGeom.TypeID(), typeID)) {
+		return nil, newUnexpectedTypeError(geosGeom.Type(), geosTypeIDNames[typeID])
+	}
+	return geosGeom, nil
+}
+
+// geosTypeIDIsCollection returns whether typeID is a multi geometry or a
+// geometry collection.
+func geosTypeIDIsCollection(typeID geos.TypeID) bool {
+	switch typeID {
+	case geos.TypeIDMultiPoint, geos.TypeIDMultiLineString, geos.TypeIDMultiPolygon, geos.TypeIDGeometryCollection:
+		return true
+	default:
+		return false
+	}
+}
+
+// geosTypeIDPromotesTo returns whether a geometry with typeID can be promoted
+// to a geometry with multiTypeID.
+func geosTypeIDPromotesTo(typeID, multiTypeID geos.TypeID) bool {
+	switch multiTypeID {
+	case geos.TypeIDMultiPoint:
+		return typeID == geos.TypeIDPoint
+	case geos.TypeIDMultiLineString:
+		return typeID == geos.TypeIDLineString
+	case geos.TypeIDMultiPolygon:
+		return typeID == geos.TypeIDPolygon
+	case geos.TypeIDGeometryCollection:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkGEOSGeomForOrb returns an error if geosGeom has Z or M ordinates that
+// would be lost by converting it to an orb.Geometry and c's ZM policy does not
+// allow them to be dropped. Geometry collections are checked element by
+// element.
+func (c *converter) checkGEOSGeomForOrb(geosGeom *geos.Geom) error {
+	if c.zmPolicy == ZMPolicyDrop {
+		return nil
+	}
+	var geosCoordSeqs []*geos.CoordSeq
+	switch geosGeom.TypeID() {
+	case geos.TypeIDPoint, geos.TypeIDLineString, geos.TypeIDLinearRing:
+		geosCoordSeqs = append(geosCoordSeqs, geosGeom.CoordSeq())
+	case geos.TypeIDPolygon:
+		if geosGeom.IsEmpty() {
+			break
+		}
+		geosCoordSeqs = append(geosCoordSeqs, geosGeom.ExteriorRing().CoordSeq())
+		for i := 0; i < geosGeom.NumInteriorRings(); i++ {
+			geosCoordSeqs = append(geosCoordSeqs, geosGeom.InteriorRing(i).CoordSeq())
+		}
+	case geos.TypeIDMultiPoint, geos.TypeIDMultiLineString, geos.TypeIDMultiPolygon:
+		for i := 0; i < geosGeom.NumGeometries(); i++ {
+			if err := c.checkGEOSGeomForOrb(geosGeom.Geometry(i)); err != nil {
+				return withPathIndex(err, i)
+			}
+		}
+	}
+	for _, geosCoordSeq := range geosCoordSeqs {
+		if geomLayout := geomLayoutFromGEOSCoordSeq(geosCoordSeq); geomLayout != geom.XY {
+			return newUnsupportedLayoutError(geosGeom.Type(), geomLayout.String())
+		}
+	}
+	return nil
 }
@@ -0,0 +1,122 @@
+package geobabel
+
+import (
+	"math"
+
+	"github.com/paulmach/orb"
+)
+
+// Rings are oriented in the buffers that conversions copy coordinates into,
+// after each polygon is copied and transformed, so orientation does not
+// require an extra copy of the geometry. Degenerate rings with zero area are
+// never reversed.
+
+// geomOrientPolygonFlatCoords orients the rings of the polygon in flatCoords
+// starting at start with ends according to orientation, in place.
+func geomOrientPolygonFlatCoords(flatCoords []float64, start int, ends []int, stride int, orientation Orientation) {
+	if orientation == OrientationKeep {
+		return
+	}
+	for i, end := range ends {
+		ringFlatCoords := flatCoords[start:end]
+		if orientation.reverseRing(geomFlatCoordsSignedArea(ringFlatCoords, stride), i == 0) {
+			geomReverseFlatCoords(ringFlatCoords, stride)
+		}
+		start = end
+	}
+}
+
+// geomOrientMultiPolygonFlatCoords orients the rings of the multipolygon in
+// flatCoords with endss according to orientation, in place.
+func geomOrientMultiPolygonFlatCoords(flatCoords []float64, endss [][]int, stride int, orientation Orientation) {
+	if orientation == OrientationKeep {
+		return
+	}
+	start := 0
+	for _, ends := range endss {
+		geomOrientPolygonFlatCoords(flatCoords, start, ends, stride, orientation)
+		if len(ends) > 0 {
+			start = ends[len(ends)-1]
+		}
+	}
+}
+
+// geomFlatCoordsSignedArea returns twice the signed area of the ring in
+// flatCoords, which is positive if the ring is counterclockwise.
+func geomFlatCoordsSignedArea(flatCoords []float64, stride int) float64 {
+	signedArea := 0.0
+	for i := stride; i < len(flatCoords); i += stride {
+		signedArea += flatCoords[i-stride]*flatCoords[i+1] - flatCoords[i]*flatCoords[i-stride+1]
+	}
+	return signedArea
+}
+
+// geomReverseFlatCoords reverses the order of the coordinates in flatCoords in
+// place.
+func geomReverseFlatCoords(flatCoords []float64, stride int) {
+	for i, j := 0, len(flatCoords)-stride; i < j; i, j = i+stride, j-stride {
+		for k := 0; k < stride; k++ {
+			flatCoords[i+k], flatCoords[j+k] = flatCoords[j+k], flatCoords[i+k]
+		}
+	}
+}
+
+// orbOrientPolygon orients the rings of orbPolygon according to orientation,
+// in place.
+func orbOrientPolygon(orbPolygon orb.Polygon, orientation Orientation) {
+	if orientation == OrientationKeep {
+		return
+	}
+	for i, orbRing := range orbPolygon {
+		if orientation.reverseRing(orbRingSignedArea(orbRing), i == 0) {
+			orbRing.Reverse()
+		}
+	}
+}
+
+// orbRingSignedArea returns twice the signed area of orbRing, which is
+// positive if orbRing is counterclockwise.
+func orbRingSignedArea(orbRing orb.Ring) float64 {
+	signedArea := 0.0
+	for i := 1; i < len(orbRing); i++ {
+		signedArea += orbRing[i-1][0]*orbRing[i][1] - orbRing[i][0]*orbRing[i-1][1]
+	}
+	return signedArea
+}
+
+// geosOrientPolygonCoords orients the rings of the polygon in geosCoordss
+// according to orientation, in place. Only the slices of coordinates are
+// reordered, so coordinates that alias a source geometry are not modified.
+func geosOrientPolygonCoords(geosCoordss [][][]float64, orientation Orientation) {
+	if orientation == OrientationKeep {
+		return
+	}
+	for i, geosCoords := range geosCoordss {
+		signedArea := 0.0
+		for j := 1; j < len(geosCoords); j++ {
+			signedArea += geosCoords[j-1][0]*geosCoords[j][1] - geosCoords[j][0]*geosCoords[j-1][1]
+		}
+		if orientation.reverseRing(signedArea, i == 0) {
+			for j, k := 0, len(geosCoords)-1; j < k; j, k = j+1, k-1 {
+				geosCoords[j], geosCoords[k] = geosCoords[k], geosCoords[j]
+			}
+		}
+	}
+}
+
+// reverseRing returns whether a ring with signedArea, which is positive if the
+// ring is counterclockwise, must be reversed to follow o.
+func (o Orientation) reverseRing(signedArea float64, exterior bool) bool {
+	if signedArea == 0 || math.IsNaN(signedArea) {
+		return false
+	}
+	counterclockwise := signedArea > 0
+	switch o {
+	case OrientationCounterclockwise:
+		return counterclockwise != exterior
+	case OrientationClockwise:
+		return counterclockwise == exterior
+	default:
+		return false
+	}
+}
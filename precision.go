@@ -0,0 +1,186 @@
+package geobabel
+
+import (
+	"math"
+
+	"github.com/paulmach/orb"
+	"github.com/twpayne/go-geos"
+)
+
+// Coordinates are snapped by a TransformFunc applied after any user
+// transformation, so snapping uses the same copy pass as transformations.
+// Repeated points, which snapping can create, are then removed from each line
+// string and ring in the buffer it was copied into.
+
+// Minimum number of points that removing repeated points leaves in line
+// strings and rings. Line strings and rings that would have fewer points are
+// left unchanged, like PostGIS's ST_RemoveRepeatedPoints.
+const (
+	minLineStringPoints = 2
+	minRingPoints       = 4
+)
+
+// newSnapTransform returns a TransformFunc that snaps X and Y ordinates to the
+// nearest multiple of gridSize. Grid sizes that are the reciprocal of an
+// integer, for example 0.001, snap by scaling up instead, so that snapped
+// ordinates are the closest float64s to their decimal values, like GEOS's
+// precision models.
+func newSnapTransform(gridSize float64) TransformFunc {
+	if scale := 1 / gridSize; scale > 1 && math.Abs(scale-math.Round(scale)) < 1e-9*scale {
+		scale = math.Round(scale)
+		return func(x, y float64) (float64, float64) {
+			return math.Round(x*scale) / scale, math.Round(y*scale) / scale
+		}
+	}
+	return func(x, y float64) (float64, float64) {
+		return math.Round(x/gridSize) * gridSize, math.Round(y/gridSize) * gridSize
+	}
+}
+
+// composeTransforms returns a TransformFunc that applies first and then second.
+// Either may be nil.
+func composeTransforms(first, second TransformFunc) TransformFunc {
+	switch {
+	case first == nil:
+		return second
+	case second == nil:
+		return first
+	default:
+		return func(x, y float64) (float64, float64) {
+			return second(first(x, y))
+		}
+	}
+}
+
+// removeRepeatedPoints returns whether c removes repeated points.
+func (c *converter) removeRepeatedPoints() bool {
+	return c.gridSize != 0
+}
+
+// setGEOSPrecision sets the precision of geosGeom to c's grid size, if
+// requested.
+func (c *converter) setGEOSPrecision(geosGeom *geos.Geom) *geos.Geom {
+	if !c.geosPrecision || c.gridSize == 0 {
+		return geosGeom
+	}
+	return geosGeom.SetPrecision(c.gridSize, geos.PrecisionRulePointwise)
+}
+
+// geomRemoveRepeatedPoints removes repeated points from the line string or
+// ring in flatCoords, in place, and returns the remaining flat coordinates.
+func geomRemoveRepeatedPoints(flatCoords []float64, stride, minPoints int) []float64 {
+	return flatCoords[:geomCompactFlatCoords(flatCoords, 0, 0, len(flatCoords), stride, minPoints)]
+}
+
+// geomRemoveRepeatedPointsEnds removes repeated points from the line strings or
+// rings in flatCoords starting at start with ends, moving them to w, in place.
+// ends are updated in place. It returns the new end of the last line string or
+// ring.
+func geomRemoveRepeatedPointsEnds(flatCoords []float64, w, start int, ends []int, stride, minPoints int) int {
+	for i, end := range ends {
+		w = geomCompactFlatCoords(flatCoords, w, start, end, stride, minPoints)
+		start = end
+		ends[i] = w
+	}
+	return w
+}
+
+// geomRemoveRepeatedPointsEndss removes repeated points from the rings of the
+// polygons in flatCoords with endss, in place, and returns the remaining flat
+// coordinates. endss are updated in place.
+func geomRemoveRepeatedPointsEndss(flatCoords []float64, endss [][]int, stride int) []float64 {
+	w, start := 0, 0
+	for _, ends := range endss {
+		if len(ends) == 0 {
+			continue
+		}
+		end := ends[len(ends)-1]
+		w = geomRemoveRepeatedPointsEnds(flatCoords, w, start, ends, stride, minRingPoints)
+		start = end
+	}
+	return flatCoords[:w]
+}
+
+// geomCompactFlatCoords copies the points of flatCoords[start:end] to
+// flatCoords[w:], omitting points with the same X and Y ordinates as their
+// predecessor, and returns the new end. w must not be greater than start. If
+// fewer than minPoints points would remain then all points are copied.
+func geomCompactFlatCoords(flatCoords []float64, w, start, end, stride, minPoints int) int {
+	numPoints := 0
+	for i := start; i < end; i += stride {
+		if i == start || flatCoords[i] != flatCoords[i-stride] || flatCoords[i+1] != flatCoords[i-stride+1] {
+			numPoints++
+		}
+	}
+	if numPoints < minPoints || numPoints == (end-start)/stride {
+		return w + copy(flatCoords[w:], flatCoords[start:end])
+	}
+	for i := start; i < end; i += stride {
+		if i == start || flatCoords[i] != flatCoords[i-stride] || flatCoords[i+1] != flatCoords[i-stride+1] {
+			w += copy(flatCoords[w:w+stride], flatCoords[i:i+stride])
+		}
+	}
+	return w
+}
+
+// orbRemoveRepeatedPoints removes repeated points from orbPoints in place.
+func orbRemoveRepeatedPoints[S ~[]orb.Point](orbPoints S, minPoints int) S {
+	numPoints := 0
+	for i := range orbPoints {
+		if i == 0 || orbPoints[i] != orbPoints[i-1] {
+			numPoints++
+		}
+	}
+	if numPoints < minPoints || numPoints == len(orbPoints) {
+		return orbPoints
+	}
+	n := 1
+	for _, orbPoint := range orbPoints[1:] {
+		if orbPoint != orbPoints[n-1] {
+			orbPoints[n] = orbPoint
+			n++
+		}
+	}
+	return orbPoints[:n]
+}
+
+// geosRemoveRepeatedCoords removes repeated coordinates from geosCoords in
+// place. Only the slice of coordinates is modified.
+func geosRemoveRepeatedCoords(geosCoords [][]float64, minPoints int) [][]float64 {
+	repeated := func(a, b []float64) bool {
+		return a[0] == b[0] && a[1] == b[1]
+	}
+	numPoints := 0
+	for i := range geosCoords {
+		if i == 0 || !repeated(geosCoords[i], geosCoords[i-1]) {
+			numPoints++
+		}
+	}
+	if numPoints < minPoints || numPoints == len(geosCoords) {
+		return geosCoords
+	}
+	n := 1
+	for _, geosCoord := range geosCoords[1:] {
+		if !repeated(geosCoord, geosCoords[n-1]) {
+			geosCoords[n] = geosCoord
+			n++
+		}
+	}
+	return geosCoords[:n]
+}
+
+// orbRemoveRepeatedPolygonPoints removes repeated points from the rings of
+// orbPolygon in place.
+func orbRemoveRepeatedPolygonPoints(orbPolygon orb.Polygon) {
+	for i, orbRing := range orbPolygon {
+		orbPolygon[i] = orbRemoveRepeatedPoints(orbRing, minRingPoints)
+	}
+}
+
+// geosRemoveRepeatedCoordss removes repeated coordinates from each element of
+// geosCoordss in place.
+func geosRemoveRepeatedCoordss(geosCoordss [][][]float64, minPoints int) {
+	for i, geosCoords := range geosCoordss {
+		geosCoordss[i] = geosRemoveRepeatedCoords(geosCoords, minPoints)
+	}
+}
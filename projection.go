@@ -0,0 +1,255 @@
+package geobabel
+
+import (
+	"fmt"
+	"math"
+)
+
+// SRIDs of supported coordinate reference systems. WGS 84 UTM zones are also
+// supported, see UTMSRID.
+const (
+	SRIDWGS84       = 4326
+	SRIDWebMercator = 3857
+)
+
+// WGS 84 UTM SRIDs.
+const (
+	sridUTMNorth = 32600
+	sridUTMSouth = 32700
+	utmNumZones  = 60
+)
+
+// WGS 84 ellipsoid.
+const (
+	wgs84SemiMajorAxis = 6378137
+	wgs84Flattening    = 1 / 298.257223563
+)
+
+// webMercatorMaxY is the maximum Web Mercator Y ordinate. Like orb's project
+// package, Y ordinates are clamped to ±webMercatorMaxY so that the poles are
+// finite.
+const webMercatorMaxY = wgs84SemiMajorAxis * math.Pi
+
+// A Projection transforms coordinates from one coordinate reference system to
+// another.
+type Projection struct {
+	fromSRID  int
+	toSRID    int
+	toWGS84   TransformFunc
+	fromWGS84 TransformFunc
+}
+
+// NewProjection returns a new Projection from the coordinate reference system
+// with SRID fromSRID to the one with SRID toSRID. Projections between two
+// projected coordinate reference systems go via WGS 84. It returns an error
+// wrapping ErrUnsupportedSRID if either SRID is not supported.
+func NewProjection(fromSRID, toSRID int) (*Projection, error) {
+	toWGS84, err := transformToWGS84(fromSRID)
+	if err != nil {
+		return nil, err
+	}
+	fromWGS84, err := transformFromWGS84(toSRID)
+	if err != nil {
+		return nil, err
+	}
+	if fromSRID == toSRID {
+		toWGS84, fromWGS84 = nil, nil
+	}
+	return &Projection{
+		fromSRID:  fromSRID,
+		toSRID:    toSRID,
+		toWGS84:   toWGS84,
+		fromWGS84: fromWGS84,
+	}, nil
+}
+
+// FromSRID returns the SRID of p's source coordinate reference system.
+func (p *Projection) FromSRID() int {
+	return p.fromSRID
+}
+
+// ToSRID returns the SRID of p's target coordinate reference system.
+func (p *Projection) ToSRID() int {
+	return p.toSRID
+}
+
+// Transform transforms x and y. It is a TransformFunc.
+func (p *Projection) Transform(x, y float64) (float64, float64) {
+	if p.toWGS84 != nil {
+		x, y = p.toWGS84(x, y)
+	}
+	if p.fromWGS84 != nil {
+		x, y = p.fromWGS84(x, y)
+	}
+	return x, y
+}
+
+// UTMSRID returns the SRID of the WGS 84 UTM zone with the given number in
+// the northern or southern hemisphere.
+func UTMSRID(zone int, north bool) (int, error) {
+	if zone < 1 || zone > utmNumZones {
+		return 0, fmt.Errorf("%d: invalid UTM zone", zone)
+	}
+	if north {
+		return sridUTMNorth + zone, nil
+	}
+	return sridUTMSouth + zone, nil
+}
+
+// UTMSRIDFromLonLat returns the SRID of the WGS 84 UTM zone that contains the
+// point lon, lat. The exceptions to the regular zones around Norway and
+// Svalbard are not applied.
+func UTMSRIDFromLonLat(lon, lat float64) int {
+	zone := int(math.Floor((lon+180)/6))%utmNumZones + 1
+	if zone < 1 {
+		zone += utmNumZones
+	}
+	if lat < 0 {
+		return sridUTMSouth + zone
+	}
+	return sridUTMNorth + zone
+}
+
+// transformToWGS84 returns the transformation from srid to WGS 84, or nil if
+// srid is WGS 84.
+func transformToWGS84(srid int) (TransformFunc, error) {
+	switch zone, north, ok := utmZoneFromSRID(srid); {
+	case srid == SRIDWGS84:
+		return nil, nil
+	case srid == SRIDWebMercator:
+		return wgs84FromWebMercator, nil
+	case ok:
+		return newTransverseMercator(zone, north).toWGS84, nil
+	default:
+		return nil, fmt.Errorf("%d: %w", srid, ErrUnsupportedSRID)
+	}
+}
+
+// transformFromWGS84 returns the transformation from WGS 84 to srid, or nil if
+// srid is WGS 84.
+func transformFromWGS84(srid int) (TransformFunc, error) {
+	switch zone, north, ok := utmZoneFromSRID(srid); {
+	case srid == SRIDWGS84:
+		return nil, nil
+	case srid == SRIDWebMercator:
+		return webMercatorFromWGS84, nil
+	case ok:
+		return newTransverseMercator(zone, north).fromWGS84, nil
+	default:
+		return nil, fmt.Errorf("%d: %w", srid, ErrUnsupportedSRID)
+	}
+}
+
+// utmZoneFromSRID returns the WGS 84 UTM zone and hemisphere of srid.
+func utmZoneFromSRID(srid int) (int, bool, bool) {
+	switch {
+	case sridUTMNorth < srid && srid <= sridUTMNorth+utmNumZones:
+		return srid - sridUTMNorth, true, true
+	case sridUTMSouth < srid && srid <= sridUTMSouth+utmNumZones:
+		return srid - sridUTMSouth, false, true
+	default:
+		return 0, false, false
+	}
+}
+
+func webMercatorFromWGS84(lon, lat float64) (float64, float64) {
+	x := webMercatorMaxY / 180 * lon
+	y := math.Log(math.Tan((90+lat)*math.Pi/360)) * wgs84SemiMajorAxis
+	return x, math.Max(-webMercatorMaxY, math.Min(y, webMercatorMaxY))
+}
+
+func wgs84FromWebMercator(x, y float64) (float64, float64) {
+	lon := 180 * x / webMercatorMaxY
+	lat := 180 / math.Pi * (2*math.Atan(math.Exp(y/wgs84SemiMajorAxis)) - math.Pi/2)
+	return lon, lat
+}
+
+// A transverseMercator is a UTM zone's transverse Mercator projection of the
+// WGS 84 ellipsoid. It uses Krüger's series to fourth order in the third
+// flattening, which is accurate to well under a millimeter within a
+// zone.
+type transverseMercator struct {
+	centralMeridian float64
+	falseNorthing   float64
+}
+
+// UTM parameters.
+const (
+	utmScaleFactor        = 0.9996
+	utmFalseEasting       = 500000
+	utmFalseNorthingSouth = 10000000
+)
+
+// Krüger series coefficients for the WGS 84 ellipsoid.
+var (
+	tmN           = wgs84Flattening / (2 - wgs84Flattening)
+	tmN2          = tmN * tmN
+	tmN3          = tmN2 * tmN
+	tmN4          = tmN3 * tmN
+	tmRectifyingA = wgs84SemiMajorAxis / (1 + tmN) * (1 + tmN2/4 + tmN4/64)
+	tmE           = 2 * math.Sqrt(tmN) / (1 + tmN)
+	tmAlpha       = [...]float64{
+		tmN/2 - 2*tmN2/3 + 5*tmN3/16 + 41*tmN4/180,
+		13*tmN2/48 - 3*tmN3/5 + 557*tmN4/1440,
+		61*tmN3/240 - 103*tmN4/140,
+		49561 * tmN4 / 161280,
+	}
+	tmBeta = [...]float64{
+		tmN/2 - 2*tmN2/3 + 37*tmN3/96 - tmN4/360,
+		tmN2/48 + tmN3/15 - 437*tmN4/1440,
+		17*tmN3/480 - 37*tmN4/840,
+		4397 * tmN4 / 161280,
+	}
+	tmDelta = [...]float64{
+		2*tmN - 2*tmN2/3 - 2*tmN3 + 116*tmN4/45,
+		7*tmN2/3 - 8*tmN3/5 - 227*tmN4/45,
+		56*tmN3/15 - 136*tmN4/35,
+		4279 * tmN4 / 630,
+	}
+)
+
+func newTransverseMercator(zone int, north bool) *transverseMercator {
+	tm := &transverseMercator{
+		centralMeridian: float64(6*zone - 183),
+	}
+	if !north {
+		tm.falseNorthing = utmFalseNorthingSouth
+	}
+	return tm
+}
+
+func (tm *transverseMercator) fromWGS84(lon, lat float64) (float64, float64) {
+	phi := lat * math.Pi / 180
+	lambda := (lon - tm.centralMeridian) * math.Pi / 180
+	sinPhi := math.Sin(phi)
+	t := math.Sinh(math.Atanh(sinPhi) - tmE*math.Atanh(tmE*sinPhi))
+	xi := math.Atan2(t, math.Cos(lambda))
+	eta := math.Atanh(math.Sin(lambda) / math.Sqrt(1+t*t))
+	easting, northing := eta, xi
+	for j, alpha := range tmAlpha {
+		k := 2 * float64(j+1)
+		easting += alpha * math.Cos(k*xi) * math.Sinh(k*eta)
+		northing += alpha * math.Sin(k*xi) * math.Cosh(k*eta)
+	}
+	x := utmFalseEasting + utmScaleFactor*tmRectifyingA*easting
+	y := tm.falseNorthing + utmScaleFactor*tmRectifyingA*northing
+	return x, y
+}
+
+func (tm *transverseMercator) toWGS84(x, y float64) (float64, float64) {
+	xi := (y - tm.falseNorthing) / (utmScaleFactor * tmRectifyingA)
+	eta := (x - utmFalseEasting) / (utmScaleFactor * tmRectifyingA)
+	xiPrime, etaPrime := xi, eta
+	for j, beta := range tmBeta {
+		k := 2 * float64(j+1)
+		xiPrime -= beta * math.Sin(k*xi) * math.Cosh(k*eta)
+		etaPrime -= beta * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+	chi := math.Asin(math.Sin(xiPrime) / math.Cosh(etaPrime))
+	phi := chi
+	for j, delta := range tmDelta {
+		phi += delta * math.Sin(2*float64(j+1)*chi)
+	}
+	lambda := math.Atan2(math.Sinh(etaPrime), math.Cos(xiPrime))
+	return tm.centralMeridian + lambda*180/math.Pi, phi * 180 / math.Pi
+}
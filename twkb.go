@@ -0,0 +1,731 @@
+package geobabel
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/paulmach/orb"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geos"
+)
+
+// TWKB, or Tiny WKB, is a compressed format that encodes coordinates as
+// variable-length integers scaled by a power of ten and delta-encoded from the
+// previous point. It is encoded and decoded like PostGIS's ST_AsTWKB and
+// ST_GeomFromTWKB. As with WKB, all TWKB is encoded from and decoded to geom.Ts
+// so that the result does not depend on which library produced a geometry.
+// TWKB has no linear ring type, so linear rings are handled according to the
+// linear ring policy.
+
+// TWKB geometry types.
+const (
+	twkbTypePoint              = 1
+	twkbTypeLineString         = 2
+	twkbTypePolygon            = 3
+	twkbTypeMultiPoint         = 4
+	twkbTypeMultiLineString    = 5
+	twkbTypeMultiPolygon       = 6
+	twkbTypeGeometryCollection = 7
+)
+
+// TWKB metadata flags.
+const (
+	twkbFlagBBox         = 0x01
+	twkbFlagSize         = 0x02
+	twkbFlagIDList       = 0x04
+	twkbFlagExtendedDims = 0x08
+	twkbFlagEmpty        = 0x10
+)
+
+// errTWKBTruncated is returned when TWKB ends unexpectedly.
+var errTWKBTruncated = errors.New("truncated TWKB")
+
+// NewGEOSGeomFromTWKB returns a new *geos.Geom parsed from twkb.
+func NewGEOSGeomFromTWKB(geosContext *geos.Context, twkb []byte, options ...Option) (*geos.Geom, error) {
+	geomT, err := NewGeomTFromTWKB(twkb, options...)
+	if err != nil {
+		return nil, err
+	}
+	return NewGEOSGeomFromGeomTE(geosContext, geomT, options...)
+}
+
+// NewGeomTFromTWKB returns a new geom.T parsed from twkb. Linear rings are
+// decoded according to the linear ring policy.
+func NewGeomTFromTWKB(twkb []byte, options ...Option) (geom.T, error) {
+	r := &twkbReader{data: twkb}
+	geomT, err := r.readGeomT()
+	if err != nil {
+		return nil, err
+	}
+	if len(r.data) != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after TWKB", len(r.data))
+	}
+	return newConverter(options).geomTWithLinearRings(geomT), nil
+}
+
+// NewOrbGeometryFromTWKB returns a new orb.Geometry parsed from twkb. Z and M
+// ordinates are handled according to options.
+func NewOrbGeometryFromTWKB(twkb []byte, options ...Option) (orb.Geometry, error) {
+	geomT, err := NewGeomTFromTWKB(twkb, options...)
+	if err != nil {
+		return nil, err
+	}
+	return NewOrbGeometryFromGeomTE(geomT, options...)
+}
+
+// TWKBIDs returns the IDs of the members of the multi geometry or geometry
+// collection encoded in twkb, or nil if twkb has no ID list.
+func TWKBIDs(twkb []byte) ([]int64, error) {
+	r := &twkbReader{data: twkb}
+	header, err := r.readHeader()
+	if err != nil {
+		return nil, err
+	}
+	if header.flags&twkbFlagIDList == 0 || header.flags&twkbFlagEmpty != 0 {
+		return nil, nil
+	}
+	switch header.typ {
+	case twkbTypeMultiPoint, twkbTypeMultiLineString, twkbTypeMultiPolygon, twkbTypeGeometryCollection:
+	default:
+		return nil, nil
+	}
+	n, err := r.readCount()
+	if err != nil {
+		return nil, err
+	}
+	return r.readIDs(n)
+}
+
+// TWKBFromGEOSGeom returns geosGeom encoded as TWKB. It returns a
+// *ConvertError if geosGeom cannot be encoded.
+func TWKBFromGEOSGeom(geosGeom *geos.Geom, options ...Option) ([]byte, error) {
+	c := newConverter(options)
+	geomT, err := c.newGeomTFromGEOSGeomE(geosGeom)
+	if err != nil {
+		return nil, err
+	}
+	return c.marshalTWKB(geomT)
+}
+
+// TWKBFromGeomT returns geomT encoded as TWKB. The encoding is set with
+// WithTWKBBBox, WithTWKBIDs, WithTWKBPrecision, and WithTWKBSize. It returns a
+// *ConvertError if geomT cannot be encoded.
+func TWKBFromGeomT(geomT geom.T, options ...Option) ([]byte, error) {
+	return newConverter(options).twkbFromGeomT(geomT)
+}
+
+// TWKBFromOrbGeometry returns orbGeometry encoded as TWKB. It returns a
+// *ConvertError if orbGeometry cannot be encoded.
+func TWKBFromOrbGeometry(orbGeometry orb.Geometry, options ...Option) ([]byte, error) {
+	c := newConverter(options)
+	geomT, err := c.newGeomTFromOrbGeometryE(orbGeometry)
+	if err != nil {
+		return nil, err
+	}
+	return c.marshalTWKB(geomT)
+}
+
+// A twkbWriter encodes geom.Ts as TWKB.
+type twkbWriter struct {
+	precisionXY      int
+	precisionZ       int
+	precisionM       int
+	bbox             bool
+	size             bool
+	linearRingPolicy LinearRingPolicy
+}
+
+// twkbBounds are the bounds of the scaled ordinates of a geometry.
+type twkbBounds struct {
+	ndims    int
+	min, max [4]int64
+}
+
+// twkbFromGeomT returns geomT encoded as TWKB with c's TWKB options,
+// transformed like conversions from GEOS and orb.
+func (c *converter) twkbFromGeomT(geomT geom.T) ([]byte, error) {
+	return c.marshalTWKB(c.transformGeomT(geomT))
+}
+
+// marshalTWKB returns geomT, which has already been transformed, encoded as
+// TWKB with c's TWKB options.
+func (c *converter) marshalTWKB(geomT geom.T) ([]byte, error) {
+	switch {
+	case c.twkbPrecisionXY < -8 || 7 < c.twkbPrecisionXY:
+		return nil, fmt.Errorf("%d: invalid TWKB XY precision", c.twkbPrecisionXY)
+	case c.twkbPrecisionZ < 0 || 7 < c.twkbPrecisionZ:
+		return nil, fmt.Errorf("%d: invalid TWKB Z precision", c.twkbPrecisionZ)
+	case c.twkbPrecisionM < 0 || 7 < c.twkbPrecisionM:
+		return nil, fmt.Errorf("%d: invalid TWKB M precision", c.twkbPrecisionM)
+	}
+	w := &twkbWriter{
+		precisionXY:      c.twkbPrecisionXY,
+		precisionZ:       c.twkbPrecisionZ,
+		precisionM:       c.twkbPrecisionM,
+		bbox:             c.twkbBBox,
+		size:             c.twkbSize,
+		linearRingPolicy: c.linearRingPolicy,
+	}
+	return w.appendGeomT(nil, geomT, c.twkbIDs, nil)
+}
+
+// appendGeomT appends the TWKB encoding of geomT with ids to dst and extends
+// parentBounds, if not nil, with the bounds of geomT.
+func (w *twkbWriter) appendGeomT(dst []byte, geomT geom.T, ids []int64, parentBounds *twkbBounds) ([]byte, error) {
+	if geomLinearRing, ok := geomT.(*geom.LinearRing); ok {
+		switch w.linearRingPolicy {
+		case LinearRingPolicyLineString:
+			geomT = geom.NewLineStringFlat(geomLinearRing.Layout(), geomLinearRing.FlatCoords())
+		case LinearRingPolicyPolygon:
+			if geomLinearRing.Empty() {
+				geomT = geom.NewPolygon(geomLinearRing.Layout())
+			} else {
+				geomT = geom.NewPolygonFlat(geomLinearRing.Layout(), geomLinearRing.FlatCoords(), []int{len(geomLinearRing.FlatCoords())})
+			}
+		default:
+			return nil, newUnsupportedTypeError(fmt.Sprintf("%T", geomT))
+		}
+	}
+
+	layout := geomT.Layout()
+	if layout == geom.NoLayout {
+		// Empty geometry collections have no layout.
+		layout = geom.XY
+	}
+	e := w.newTWKBEncoder(layout)
+
+	var twkbType byte
+	var numMembers int
+	switch geomT := geomT.(type) {
+	case *geom.Point:
+		twkbType = twkbTypePoint
+		if !geomT.Empty() {
+			numMembers = 1
+		}
+	case *geom.LineString:
+		twkbType, numMembers = twkbTypeLineString, geomT.NumCoords()
+	case *geom.Polygon:
+		twkbType, numMembers = twkbTypePolygon, geomT.NumLinearRings()
+	case *geom.MultiPoint:
+		twkbType, numMembers = twkbTypeMultiPoint, geomT.NumPoints()
+	case *geom.MultiLineString:
+		twkbType, numMembers = twkbTypeMultiLineString, geomT.NumLineStrings()
+	case *geom.MultiPolygon:
+		twkbType, numMembers = twkbTypeMultiPolygon, geomT.NumPolygons()
+	case *geom.GeometryCollection:
+		twkbType, numMembers = twkbTypeGeometryCollection, geomT.NumGeoms()
+	default:
+		return nil, newUnsupportedTypeError(fmt.Sprintf("%T", geomT))
+	}
+
+	var flags byte
+	if ids != nil {
+		if twkbType < twkbTypeMultiPoint {
+			return nil, &ConvertError{
+				Type: fmt.Sprintf("%T", geomT),
+				Err:  errors.New("TWKB IDs require a multi geometry or geometry collection"),
+			}
+		}
+		if len(ids) != numMembers {
+			return nil, &ConvertError{
+				Type: fmt.Sprintf("%T", geomT),
+				Err:  fmt.Errorf("%d TWKB IDs for %d geometries", len(ids), numMembers),
+			}
+		}
+		if numMembers != 0 {
+			flags |= twkbFlagIDList
+		}
+	}
+
+	var body []byte
+	switch geomT := geomT.(type) {
+	case *geom.Point:
+		body = e.appendPoints(body, geomT.FlatCoords(), 1, false)
+	case *geom.LineString:
+		body = e.appendPoints(body, geomT.FlatCoords(), 2, true)
+	case *geom.Polygon:
+		body = e.appendRings(body, geomT.FlatCoords(), 0, geomT.Ends())
+	case *geom.MultiPoint:
+		body = binary.AppendUvarint(body, uint64(numMembers))
+		body = appendTWKBIDs(body, flags, ids)
+		for i := 0; i < numMembers; i++ {
+			geomCoord := geomT.Coord(i)
+			if geomCoord == nil {
+				return nil, &ConvertError{
+					Type: fmt.Sprintf("%T", geomT),
+					Path: []int{i},
+					Err:  errors.New("TWKB cannot encode empty points in multi points"),
+				}
+			}
+			body = e.appendPoints(body, geomCoord, 1, false)
+		}
+	case *geom.MultiLineString:
+		body = binary.AppendUvarint(body, uint64(numMembers))
+		body = appendTWKBIDs(body, flags, ids)
+		geomFlatCoords := geomT.FlatCoords()
+		geomStart := 0
+		for _, geomEnd := range geomT.Ends() {
+			body = e.appendPoints(body, geomFlatCoords[geomStart:geomEnd], 2, true)
+			geomStart = geomEnd
+		}
+	case *geom.MultiPolygon:
+		body = binary.AppendUvarint(body, uint64(numMembers))
+		body = appendTWKBIDs(body, flags, ids)
+		geomStart := 0
+		for _, geomEnds := range geomT.Endss() {
+			body = e.appendRings(body, geomT.FlatCoords(), geomStart, geomEnds)
+			if len(geomEnds) > 0 {
+				geomStart = geomEnds[len(geomEnds)-1]
+			}
+		}
+	case *geom.GeometryCollection:
+		body = binary.AppendUvarint(body, uint64(numMembers))
+		body = appendTWKBIDs(body, flags, ids)
+		for i, geomGeom := range geomT.Geoms() {
+			var err error
+			if body, err = w.appendGeomT(body, geomGeom, nil, &e.bounds); err != nil {
+				return nil, withPathIndex(err, i)
+			}
+		}
+	}
+
+	empty := numMembers == 0
+	if empty {
+		flags = twkbFlagEmpty
+	} else {
+		// Geometries whose members are all empty have no bounding box.
+		if w.bbox && e.bounds.min[0] <= e.bounds.max[0] {
+			flags |= twkbFlagBBox
+		}
+		if w.size {
+			flags |= twkbFlagSize
+		}
+		if parentBounds != nil {
+			parentBounds.extend(&e.bounds)
+		}
+	}
+	if layout != geom.XY {
+		flags |= twkbFlagExtendedDims
+	}
+
+	dst = append(dst, twkbType|byte(twkbZigZag(w.precisionXY))<<4, flags)
+	if flags&twkbFlagExtendedDims != 0 {
+		var extendedDims byte
+		if layout.ZIndex() != -1 {
+			extendedDims |= 0x01 | byte(w.precisionZ)<<2
+		}
+		if layout.MIndex() != -1 {
+			extendedDims |= 0x02 | byte(w.precisionM)<<5
+		}
+		dst = append(dst, extendedDims)
+	}
+	if empty {
+		return dst, nil
+	}
+	var bbox []byte
+	if flags&twkbFlagBBox != 0 {
+		for i := 0; i < e.bounds.ndims; i++ {
+			bbox = binary.AppendVarint(bbox, e.bounds.min[i])
+			bbox = binary.AppendVarint(bbox, e.bounds.max[i]-e.bounds.min[i])
+		}
+	}
+	if flags&twkbFlagSize != 0 {
+		dst = binary.AppendUvarint(dst, uint64(len(bbox)+len(body)))
+	}
+	dst = append(dst, bbox...)
+	return append(dst, body...), nil
+}
+
+// appendTWKBIDs appends ids to dst if flags include an ID list.
+func appendTWKBIDs(dst []byte, flags byte, ids []int64) []byte {
+	if flags&twkbFlagIDList == 0 {
+		return dst
+	}
+	for _, id := range ids {
+		dst = binary.AppendVarint(dst, id)
+	}
+	return dst
+}
+
+// A twkbEncoder encodes the points of a single TWKB geometry.
+type twkbEncoder struct {
+	ndims    int
+	stride   int
+	scales   [4]float64
+	previous [4]int64
+	bounds   twkbBounds
+	deltas   [][4]int64
+}
+
+// newTWKBEncoder returns a new twkbEncoder for points with layout.
+func (w *twkbWriter) newTWKBEncoder(layout geom.Layout) *twkbEncoder {
+	e := &twkbEncoder{
+		ndims:  layout.Stride(),
+		stride: layout.Stride(),
+		scales: [4]float64{math.Pow10(w.precisionXY), math.Pow10(w.precisionXY)},
+	}
+	if zIndex := layout.ZIndex(); zIndex != -1 {
+		e.scales[zIndex] = math.Pow10(w.precisionZ)
+	}
+	if mIndex := layout.MIndex(); mIndex != -1 {
+		e.scales[mIndex] = math.Pow10(w.precisionM)
+	}
+	e.bounds.ndims = e.ndims
+	for i := 0; i < e.ndims; i++ {
+		e.bounds.min[i] = math.MaxInt64
+		e.bounds.max[i] = math.MinInt64
+	}
+	return e
+}
+
+// appendRings appends the number of rings in geomFlatCoords starting at
+// geomStart with geomEnds, and the rings, to dst.
+func (e *twkbEncoder) appendRings(dst []byte, geomFlatCoords []float64, geomStart int, geomEnds []int) []byte {
+	dst = binary.AppendUvarint(dst, uint64(len(geomEnds)))
+	for _, geomEnd := range geomEnds {
+		dst = e.appendPoints(dst, geomFlatCoords[geomStart:geomEnd], 4, true)
+		geomStart = geomEnd
+	}
+	return dst
+}
+
+// appendPoints appends the points in geomFlatCoords, preceded by their number
+// if count is true, to dst. Points that are repeated after scaling are
+// dropped, like PostGIS, as long as at least minPoints points remain.
+func (e *twkbEncoder) appendPoints(dst []byte, geomFlatCoords []float64, minPoints int, count bool) []byte {
+	e.deltas = e.deltas[:0]
+	numPoints := len(geomFlatCoords) / e.stride
+	for i := 0; i < numPoints; i++ {
+		var delta [4]int64
+		repeated := true
+		for j := 0; j < e.ndims; j++ {
+			value := int64(math.Round(geomFlatCoords[i*e.stride+j] * e.scales[j]))
+			delta[j] = value - e.previous[j]
+			if delta[j] != 0 {
+				repeated = false
+			}
+		}
+		if i > 0 && repeated && len(e.deltas)+numPoints-i-1 >= minPoints {
+			continue
+		}
+		for j := 0; j < e.ndims; j++ {
+			e.previous[j] += delta[j]
+			if e.previous[j] < e.bounds.min[j] {
+				e.bounds.min[j] = e.previous[j]
+			}
+			if e.previous[j] > e.bounds.max[j] {
+				e.bounds.max[j] = e.previous[j]
+			}
+		}
+		e.deltas = append(e.deltas, delta)
+	}
+	if count {
+		dst = binary.AppendUvarint(dst, uint64(len(e.deltas)))
+	}
+	for _, delta := range e.deltas {
+		for j := 0; j < e.ndims; j++ {
+			dst = binary.AppendVarint(dst, delta[j])
+		}
+	}
+	return dst
+}
+
+// extend extends b with other.
+func (b *twkbBounds) extend(other *twkbBounds) {
+	for i := 0; i < b.ndims && i < other.ndims; i++ {
+		if other.min[i] < b.min[i] {
+			b.min[i] = other.min[i]
+		}
+		if other.max[i] > b.max[i] {
+			b.max[i] = other.max[i]
+		}
+	}
+}
+
+// A twkbHeader is the header of a TWKB geometry.
+type twkbHeader struct {
+	typ    byte
+	flags  byte
+	layout geom.Layout
+	scales [4]float64
+}
+
+// A twkbReader decodes TWKB.
+type twkbReader struct {
+	data []byte
+}
+
+// readHeader reads a header, including the size and bounding box, if any.
+func (r *twkbReader) readHeader() (*twkbHeader, error) {
+	if len(r.data) < 2 {
+		return nil, errTWKBTruncated
+	}
+	header := &twkbHeader{
+		typ:    r.data[0] & 0x0f,
+		flags:  r.data[1],
+		layout: geom.XY,
+	}
+	scaleXY := math.Pow10(twkbUnZigZag(r.data[0] >> 4))
+	header.scales = [4]float64{scaleXY, scaleXY}
+	r.data = r.data[2:]
+	if header.typ < twkbTypePoint || twkbTypeGeometryCollection < header.typ {
+		return nil, newUnsupportedTypeError(fmt.Sprintf("TWKB type %d", header.typ))
+	}
+	if header.flags&twkbFlagExtendedDims != 0 {
+		if len(r.data) < 1 {
+			return nil, errTWKBTruncated
+		}
+		extendedDims := r.data[0]
+		r.data = r.data[1:]
+		switch extendedDims & 0x03 {
+		case 0x01:
+			header.layout = geom.XYZ
+		case 0x02:
+			header.layout = geom.XYM
+		case 0x03:
+			header.layout = geom.XYZM
+		}
+		if zIndex := header.layout.ZIndex(); zIndex != -1 {
+			header.scales[zIndex] = math.Pow10(int(extendedDims >> 2 & 0x07))
+		}
+		if mIndex := header.layout.MIndex(); mIndex != -1 {
+			header.scales[mIndex] = math.Pow10(int(extendedDims >> 5 & 0x07))
+		}
+	}
+	if header.flags&twkbFlagSize != 0 {
+		size, err := r.readCount()
+		if err != nil {
+			return nil, err
+		}
+		if size > len(r.data) {
+			return nil, errTWKBTruncated
+		}
+	}
+	if header.flags&twkbFlagBBox != 0 {
+		for i := 0; i < 2*header.layout.Stride(); i++ {
+			if _, err := r.readVarint(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return header, nil
+}
+
+// readGeomT reads a geom.T.
+func (r *twkbReader) readGeomT() (geom.T, error) {
+	header, err := r.readHeader()
+	if err != nil {
+		return nil, err
+	}
+	layout := header.layout
+	if header.flags&twkbFlagEmpty != 0 {
+		switch header.typ {
+		case twkbTypePoint:
+			return geom.NewPointEmpty(layout), nil
+		case twkbTypeLineString:
+			return geom.NewLineStringFlat(layout, []float64{}), nil
+		case twkbTypePolygon:
+			return geom.NewPolygonFlat(layout, []float64{}, []int{}), nil
+		case twkbTypeMultiPoint:
+			return geom.NewMultiPointFlat(layout, []float64{}), nil
+		case twkbTypeMultiLineString:
+			return geom.NewMultiLineStringFlat(layout, []float64{}, []int{}), nil
+		case twkbTypeMultiPolygon:
+			return geom.NewMultiPolygonFlat(layout, []float64{}, [][]int{}), nil
+		default:
+			geomGeometryCollection := geom.NewGeometryCollection()
+			if layout != geom.XY {
+				if err := geomGeometryCollection.SetLayout(layout); err != nil {
+					return nil, err
+				}
+			}
+			return geomGeometryCollection, nil
+		}
+	}
+
+	d := &twkbDecoder{
+		twkbReader: r,
+		stride:     layout.Stride(),
+		scales:     header.scales,
+	}
+	switch header.typ {
+	case twkbTypePoint:
+		geomFlatCoords, err := d.readPoints(nil, 1)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewPointFlat(layout, geomFlatCoords), nil
+	case twkbTypeLineString:
+		geomFlatCoords, err := d.readLineString(nil)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewLineStringFlat(layout, geomFlatCoords), nil
+	case twkbTypePolygon:
+		geomFlatCoords, geomEnds, err := d.readPolygon(nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewPolygonFlat(layout, geomFlatCoords, geomEnds), nil
+	}
+
+	n, err := r.readCount()
+	if err != nil {
+		return nil, err
+	}
+	if header.flags&twkbFlagIDList != 0 {
+		if _, err := r.readIDs(n); err != nil {
+			return nil, err
+		}
+	}
+	switch header.typ {
+	case twkbTypeMultiPoint:
+		geomFlatCoords, err := d.readPoints(nil, n)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewMultiPointFlat(layout, geomFlatCoords), nil
+	case twkbTypeMultiLineString:
+		geomFlatCoords := []float64{}
+		geomEnds := make([]int, 0, n)
+		for i := 0; i < n; i++ {
+			if geomFlatCoords, err = d.readLineString(geomFlatCoords); err != nil {
+				return nil, err
+			}
+			geomEnds = append(geomEnds, len(geomFlatCoords))
+		}
+		return geom.NewMultiLineStringFlat(layout, geomFlatCoords, geomEnds), nil
+	case twkbTypeMultiPolygon:
+		geomFlatCoords := []float64{}
+		geomEndss := make([][]int, 0, n)
+		for i := 0; i < n; i++ {
+			var geomEnds []int
+			if geomFlatCoords, geomEnds, err = d.readPolygon(geomFlatCoords, []int{}); err != nil {
+				return nil, err
+			}
+			geomEndss = append(geomEndss, geomEnds)
+		}
+		return geom.NewMultiPolygonFlat(layout, geomFlatCoords, geomEndss), nil
+	default:
+		geomGeoms := make([]geom.T, 0, n)
+		for i := 0; i < n; i++ {
+			geomGeom, err := r.readGeomT()
+			if err != nil {
+				return nil, err
+			}
+			geomGeoms = append(geomGeoms, geomGeom)
+		}
+		geomGeometryCollection := geom.NewGeometryCollection()
+		if err := geomGeometryCollection.Push(geomGeoms...); err != nil {
+			return nil, err
+		}
+		return geomGeometryCollection, nil
+	}
+}
+
+// readCount reads a number of elements. Every element is at least one byte,
+// so the count cannot exceed the remaining data.
+func (r *twkbReader) readCount() (int, error) {
+	count, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		return 0, errTWKBTruncated
+	}
+	r.data = r.data[n:]
+	if count > uint64(len(r.data)) {
+		return 0, errTWKBTruncated
+	}
+	return int(count), nil
+}
+
+// readIDs reads n IDs.
+func (r *twkbReader) readIDs(n int) ([]int64, error) {
+	ids := make([]int64, 0, n)
+	for i := 0; i < n; i++ {
+		id, err := r.readVarint()
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// readVarint reads a signed variable-length integer.
+func (r *twkbReader) readVarint() (int64, error) {
+	value, n := binary.Varint(r.data)
+	if n <= 0 {
+		return 0, errTWKBTruncated
+	}
+	r.data = r.data[n:]
+	return value, nil
+}
+
+// A twkbDecoder decodes the points of a single TWKB geometry.
+type twkbDecoder struct {
+	*twkbReader
+	stride   int
+	scales   [4]float64
+	previous [4]int64
+}
+
+// readPoints reads n points and appends them to geomFlatCoords.
+func (d *twkbDecoder) readPoints(geomFlatCoords []float64, n int) ([]float64, error) {
+	if geomFlatCoords == nil {
+		geomFlatCoords = make([]float64, 0, n*d.stride)
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < d.stride; j++ {
+			delta, err := d.readVarint()
+			if err != nil {
+				return nil, err
+			}
+			d.previous[j] += delta
+			geomFlatCoords = append(geomFlatCoords, float64(d.previous[j])/d.scales[j])
+		}
+	}
+	return geomFlatCoords, nil
+}
+
+// readLineString reads the number of points and the points of a line string
+// and appends them to geomFlatCoords.
+func (d *twkbDecoder) readLineString(geomFlatCoords []float64) ([]float64, error) {
+	n, err := d.readCount()
+	if err != nil {
+		return nil, err
+	}
+	return d.readPoints(geomFlatCoords, n)
+}
+
+// readPolygon reads the number of rings and the rings of a polygon and appends
+// them to geomFlatCoords and geomEnds.
+func (d *twkbDecoder) readPolygon(geomFlatCoords []float64, geomEnds []int) ([]float64, []int, error) {
+	n, err := d.readCount()
+	if err != nil {
+		return nil, nil, err
+	}
+	if geomFlatCoords == nil {
+		geomFlatCoords = []float64{}
+	}
+	if geomEnds == nil {
+		geomEnds = make([]int, 0, n)
+	}
+	for i := 0; i < n; i++ {
+		if geomFlatCoords, err = d.readLineString(geomFlatCoords); err != nil {
+			return nil, nil, err
+		}
+		geomEnds = append(geomEnds, len(geomFlatCoords))
+	}
+	return geomFlatCoords, geomEnds, nil
+}
+
+// twkbZigZag returns the zigzag encoding of a precision.
+func twkbZigZag(precision int) int {
+	return precision<<1 ^ precision>>31
+}
+
+// twkbUnZigZag returns the precision with zigzag encoding zigZag.
+func twkbUnZigZag(zigZag byte) int {
+	return int(zigZag>>1) ^ -int(zigZag&1)
+}
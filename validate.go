@@ -0,0 +1,663 @@
+package geobabel
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/paulmach/orb"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geos"
+)
+
+// Geometries are validated in two stages. The structure of orb and go-geom
+// source geometries, which GEOS would reject or misinterpret, is checked and
+// repaired without GEOS, so that errors identify the ring or line string
+// within the source geometry. The OGC validity of the result is then checked
+// and repaired with GEOS, which reports at most one location.
+
+// Reasons for structural problems.
+const (
+	validityReasonTooFewPoints = "too few points"
+	validityReasonUnclosedRing = "unclosed ring"
+)
+
+// validateOrbGeometry checks the structure of orbGeometry according to c's
+// validity policy and returns the geometry to convert.
+func (c *converter) validateOrbGeometry(orbGeometry orb.Geometry) (orb.Geometry, error) {
+	if c.validityPolicy == ValidityPolicyIgnore {
+		return orbGeometry, nil
+	}
+	switch err := checkOrbGeometry(orbGeometry); {
+	case err == nil:
+		return orbGeometry, nil
+	case c.validityPolicy == ValidityPolicyRepair:
+		return repairOrbGeometry(orbGeometry), nil
+	default:
+		return nil, err
+	}
+}
+
+// validateGeomT checks the structure of geomT according to c's validity
+// policy and returns the geometry to convert.
+func (c *converter) validateGeomT(geomT geom.T) (geom.T, error) {
+	if c.validityPolicy == ValidityPolicyIgnore {
+		return geomT, nil
+	}
+	switch err := checkGeomT(geomT); {
+	case err == nil:
+		return geomT, nil
+	case c.validityPolicy == ValidityPolicyRepair:
+		return repairGeomT(geomT), nil
+	default:
+		return nil, err
+	}
+}
+
+// validateGeomTWithGEOS checks the validity of geomT, which was converted
+// from a geometry of type typ, with c's GEOS context, if any, and returns the
+// geometry to use.
+func (c *converter) validateGeomTWithGEOS(geomT geom.T, typ string) (geom.T, error) {
+	if c.validityPolicy == ValidityPolicyIgnore || c.geosContext == nil {
+		return geomT, nil
+	}
+	geosGeom, err := newConverter([]Option{WithZMPolicy(ZMPolicyKeep)}).newGEOSGeomFromGeomTE(c.geosContext, geomT)
+	if err != nil {
+		return nil, err
+	}
+	switch err := checkGEOSGeomValidity(geosGeom, typ); {
+	case err == nil:
+		return geomT, nil
+	case c.validityPolicy == ValidityPolicyRepair:
+		return repairGEOSGeom(geosGeom)
+	default:
+		return nil, err
+	}
+}
+
+// validateSourceGEOSGeom checks the validity of geosGeom according to c's
+// validity policy. If geosGeom was repaired, it returns the repaired geometry
+// without c's transformation applied, otherwise it returns nil.
+func (c *converter) validateSourceGEOSGeom(geosGeom *geos.Geom) (geom.T, error) {
+	if c.validityPolicy == ValidityPolicyIgnore {
+		return nil, nil
+	}
+	switch err := checkGEOSGeomValidity(geosGeom, geosGeom.Type()); {
+	case err == nil:
+		return nil, nil
+	case c.validityPolicy == ValidityPolicyRepair:
+		return repairGEOSGeom(geosGeom)
+	default:
+		return nil, err
+	}
+}
+
+// transformGeomT returns geomT with c's transformation applied, repeated
+// points removed, polygons oriented, and c's SRID, like conversions from GEOS.
+// Only the top-level geometry has an SRID. geomT is not modified.
+func (c *converter) transformGeomT(geomT geom.T) geom.T {
+	srid := c.targetSRID(geomT.SRID())
+	if c.transform == nil && c.orientation == OrientationKeep && srid == geomT.SRID() {
+		return geomT
+	}
+	switch geomT := geomT.(type) {
+	case *geom.Point:
+		geomFlatCoords := c.transformGeomFlatCoords(geomT.FlatCoords(), geomT.Stride())
+		return geom.NewPointFlat(geomT.Layout(), geomFlatCoords).SetSRID(srid)
+	case *geom.LineString:
+		geomFlatCoords := c.transformGeomFlatCoords(geomT.FlatCoords(), geomT.Stride())
+		if c.removeRepeatedPoints() {
+			geomFlatCoords = geomRemoveRepeatedPoints(geomFlatCoords, geomT.Stride(), minLineStringPoints)
+		}
+		return geom.NewLineStringFlat(geomT.Layout(), geomFlatCoords).SetSRID(srid)
+	case *geom.LinearRing:
+		geomFlatCoords := c.transformGeomFlatCoords(geomT.FlatCoords(), geomT.Stride())
+		if c.removeRepeatedPoints() {
+			geomFlatCoords = geomRemoveRepeatedPoints(geomFlatCoords, geomT.Stride(), minRingPoints)
+		}
+		return geom.NewLinearRingFlat(geomT.Layout(), geomFlatCoords).SetSRID(srid)
+	case *geom.Polygon:
+		geomFlatCoords := c.transformGeomFlatCoords(geomT.FlatCoords(), geomT.Stride())
+		geomEnds := append([]int(nil), geomT.Ends()...)
+		if c.removeRepeatedPoints() {
+			geomFlatCoords = geomFlatCoords[:geomRemoveRepeatedPointsEnds(geomFlatCoords, 0, 0, geomEnds, geomT.Stride(), minRingPoints)]
+		}
+		geomOrientPolygonFlatCoords(geomFlatCoords, 0, geomEnds, geomT.Stride(), c.orientation)
+		return geom.NewPolygonFlat(geomT.Layout(), geomFlatCoords, geomEnds).SetSRID(srid)
+	case *geom.MultiPoint:
+		geomFlatCoords := c.transformGeomFlatCoords(geomT.FlatCoords(), geomT.Stride())
+		geomEnds := append([]int(nil), geomT.Ends()...)
+		return geom.NewMultiPointFlat(geomT.Layout(), geomFlatCoords, geom.NewMultiPointFlatOptionWithEnds(geomEnds)).SetSRID(srid)
+	case *geom.MultiLineString:
+		geomFlatCoords := c.transformGeomFlatCoords(geomT.FlatCoords(), geomT.Stride())
+		geomEnds := append([]int(nil), geomT.Ends()...)
+		if c.removeRepeatedPoints() {
+			geomFlatCoords = geomFlatCoords[:geomRemoveRepeatedPointsEnds(geomFlatCoords, 0, 0, geomEnds, geomT.Stride(), minLineStringPoints)]
+		}
+		return geom.NewMultiLineStringFlat(geomT.Layout(), geomFlatCoords, geomEnds).SetSRID(srid)
+	case *geom.MultiPolygon:
+		geomFlatCoords := c.transformGeomFlatCoords(geomT.FlatCoords(), geomT.Stride())
+		geomEndss := make([][]int, 0, len(geomT.Endss()))
+		for _, geomEnds := range geomT.Endss() {
+			geomEndss = append(geomEndss, append([]int(nil), geomEnds...))
+		}
+		if c.removeRepeatedPoints() {
+			geomFlatCoords = geomRemoveRepeatedPointsEndss(geomFlatCoords, geomEndss, geomT.Stride())
+		}
+		geomOrientMultiPolygonFlatCoords(geomFlatCoords, geomEndss, geomT.Stride(), c.orientation)
+		return geom.NewMultiPolygonFlat(geomT.Layout(), geomFlatCoords, geomEndss).SetSRID(srid)
+	case *geom.GeometryCollection:
+		geomGeometryCollection := geom.NewGeometryCollection()
+		if geomT.NumGeoms() == 0 {
+			geomGeometryCollection.MustSetLayout(geomT.Layout())
+		}
+		for _, geomGeom := range geomT.Geoms() {
+			newGeomGeom, _ := geom.SetSRID(c.transformGeomT(geomGeom), 0)
+			geomGeometryCollection.MustPush(newGeomGeom)
+		}
+		return geomGeometryCollection.SetSRID(srid)
+	default:
+		return geomT
+	}
+}
+
+// transformGeomFlatCoords returns a copy of flatCoords with c's transformation
+// applied.
+func (c *converter) transformGeomFlatCoords(flatCoords []float64, stride int) []float64 {
+	newFlatCoords := make([]float64, len(flatCoords))
+	copy(newFlatCoords, flatCoords)
+	if c.transform != nil {
+		for i := 0; i+1 < len(newFlatCoords); i += stride {
+			newFlatCoords[i], newFlatCoords[i+1] = c.transform(newFlatCoords[i], newFlatCoords[i+1])
+		}
+	}
+	return newFlatCoords
+}
+
+// validateTargetGEOSGeom checks the validity of geosGeom, which was converted
+// from a geometry of type typ, according to c's validity policy and returns
+// the geometry to return.
+func (c *converter) validateTargetGEOSGeom(geosContext *geos.Context, geosGeom *geos.Geom, typ string) (*geos.Geom, error) {
+	if c.validityPolicy == ValidityPolicyIgnore {
+		return geosGeom, nil
+	}
+	switch err := checkGEOSGeomValidity(geosGeom, typ); {
+	case err == nil:
+		return geosGeom, nil
+	case c.validityPolicy == ValidityPolicyRepair:
+		geomT, err := repairGEOSGeom(geosGeom)
+		if err != nil {
+			return nil, err
+		}
+		return newConverter([]Option{WithZMPolicy(ZMPolicyKeep)}).newGEOSGeomFromGeomTE(geosContext, geomT)
+	default:
+		return nil, err
+	}
+}
+
+func checkOrbGeometry(orbGeometry orb.Geometry) error {
+	switch orbGeometry := orbGeometry.(type) {
+	case orb.LineString:
+		return checkOrbLineString(orbGeometry)
+	case orb.Ring:
+		return checkOrbRing(orbGeometry)
+	case orb.Polygon:
+		return checkOrbPolygon(orbGeometry)
+	case orb.MultiLineString:
+		for i, orbLineString := range orbGeometry {
+			if err := checkOrbLineString(orbLineString); err != nil {
+				return withPathIndex(err, i)
+			}
+		}
+	case orb.MultiPolygon:
+		for i, orbPolygon := range orbGeometry {
+			if err := checkOrbPolygon(orbPolygon); err != nil {
+				return withPathIndex(err, i)
+			}
+		}
+	case orb.Collection:
+		for i, orbGeometry := range orbGeometry {
+			if err := checkOrbGeometry(orbGeometry); err != nil {
+				return withPathIndex(err, i)
+			}
+		}
+	}
+	return nil
+}
+
+func checkOrbLineString(orbLineString orb.LineString) error {
+	if len(orbLineString) == 1 {
+		return newValidityError("orb.LineString", validityReasonTooFewPoints, orbPointLocation(orbLineString[0]))
+	}
+	return nil
+}
+
+func checkOrbRing(orbRing orb.Ring) error {
+	switch {
+	case len(orbRing) == 0:
+		return nil
+	case orbRing[0] != orbRing[len(orbRing)-1]:
+		return newValidityError("orb.Ring", validityReasonUnclosedRing, orbPointLocation(orbRing[0]))
+	case len(orbRing) < 4:
+		return newValidityError("orb.Ring", validityReasonTooFewPoints, orbPointLocation(orbRing[0]))
+	default:
+		return nil
+	}
+}
+
+func checkOrbPolygon(orbPolygon orb.Polygon) error {
+	for i, orbRing := range orbPolygon {
+		if err := checkOrbRing(orbRing); err != nil {
+			return withPathIndex(err, i)
+		}
+	}
+	return nil
+}
+
+// repairOrbGeometry returns a copy of orbGeometry with unclosed rings closed
+// and rings and line strings with too few points removed. Members of
+// orbGeometry that are not changed are not copied.
+func repairOrbGeometry(orbGeometry orb.Geometry) orb.Geometry {
+	switch orbGeometry := orbGeometry.(type) {
+	case orb.LineString:
+		if _, ok := repairOrbLineString(orbGeometry); !ok {
+			return orb.LineString{}
+		}
+	case orb.Ring:
+		orbRing, ok := repairOrbRing(orbGeometry)
+		if !ok {
+			return orb.Ring{}
+		}
+		return orbRing
+	case orb.Polygon:
+		orbPolygon, ok := repairOrbPolygon(orbGeometry)
+		if !ok {
+			return orb.Polygon{}
+		}
+		return orbPolygon
+	case orb.MultiLineString:
+		orbMultiLineString := make(orb.MultiLineString, 0, len(orbGeometry))
+		for _, orbLineString := range orbGeometry {
+			if orbLineString, ok := repairOrbLineString(orbLineString); ok {
+				orbMultiLineString = append(orbMultiLineString, orbLineString)
+			}
+		}
+		return orbMultiLineString
+	case orb.MultiPolygon:
+		orbMultiPolygon := make(orb.MultiPolygon, 0, len(orbGeometry))
+		for _, orbPolygon := range orbGeometry {
+			if orbPolygon, ok := repairOrbPolygon(orbPolygon); ok {
+				orbMultiPolygon = append(orbMultiPolygon, orbPolygon)
+			}
+		}
+		return orbMultiPolygon
+	case orb.Collection:
+		orbCollection := make(orb.Collection, 0, len(orbGeometry))
+		for _, orbGeometry := range orbGeometry {
+			orbCollection = append(orbCollection, repairOrbGeometry(orbGeometry))
+		}
+		return orbCollection
+	}
+	return orbGeometry
+}
+
+func repairOrbLineString(orbLineString orb.LineString) (orb.LineString, bool) {
+	return orbLineString, len(orbLineString) != 1
+}
+
+func repairOrbRing(orbRing orb.Ring) (orb.Ring, bool) {
+	if len(orbRing) == 0 {
+		return orbRing, true
+	}
+	if orbRing[0] != orbRing[len(orbRing)-1] {
+		// Copy orbRing so that the caller's spare capacity is not modified.
+		orbRing = append(orbRing[:len(orbRing):len(orbRing)], orbRing[0])
+	}
+	return orbRing, len(orbRing) >= 4
+}
+
+// repairOrbPolygon returns orbPolygon with its rings repaired. Interior rings
+// that cannot be repaired are removed. It returns false if the exterior ring
+// cannot be repaired.
+func repairOrbPolygon(orbPolygon orb.Polygon) (orb.Polygon, bool) {
+	if len(orbPolygon) == 0 {
+		return orbPolygon, true
+	}
+	orbExteriorRing, ok := repairOrbRing(orbPolygon[0])
+	if !ok {
+		return nil, false
+	}
+	repairedOrbPolygon := make(orb.Polygon, 0, len(orbPolygon))
+	repairedOrbPolygon = append(repairedOrbPolygon, orbExteriorRing)
+	for _, orbRing := range orbPolygon[1:] {
+		if orbRing, ok := repairOrbRing(orbRing); ok {
+			repairedOrbPolygon = append(repairedOrbPolygon, orbRing)
+		}
+	}
+	return repairedOrbPolygon, true
+}
+
+func checkGeomT(geomT geom.T) error {
+	switch geomT := geomT.(type) {
+	case *geom.LineString:
+		return checkGeomLineStringFlatCoords(geomT.FlatCoords(), geomT.Stride())
+	case *geom.LinearRing:
+		return checkGeomRingFlatCoords(geomT.FlatCoords(), geomT.Stride())
+	case *geom.Polygon:
+		return checkGeomPolygonFlatCoords(geomT.FlatCoords(), 0, geomT.Ends(), geomT.Stride())
+	case *geom.MultiLineString:
+		geomFlatCoords, geomStride := geomT.FlatCoords(), geomT.Stride()
+		geomStart := 0
+		for i, geomEnd := range geomT.Ends() {
+			if err := checkGeomLineStringFlatCoords(geomFlatCoords[geomStart:geomEnd], geomStride); err != nil {
+				return withPathIndex(err, i)
+			}
+			geomStart = geomEnd
+		}
+	case *geom.MultiPolygon:
+		geomFlatCoords, geomStride := geomT.FlatCoords(), geomT.Stride()
+		geomStart := 0
+		for i, geomEnds := range geomT.Endss() {
+			if err := checkGeomPolygonFlatCoords(geomFlatCoords, geomStart, geomEnds, geomStride); err != nil {
+				return withPathIndex(err, i)
+			}
+			if len(geomEnds) > 0 {
+				geomStart = geomEnds[len(geomEnds)-1]
+			}
+		}
+	case *geom.GeometryCollection:
+		for i, geomT := range geomT.Geoms() {
+			if err := checkGeomT(geomT); err != nil {
+				return withPathIndex(err, i)
+			}
+		}
+	}
+	return nil
+}
+
+func checkGeomLineStringFlatCoords(geomFlatCoords []float64, geomStride int) error {
+	if len(geomFlatCoords) == geomStride {
+		return newValidityError("*geom.LineString", validityReasonTooFewPoints, geomCoordLocation(geomFlatCoords))
+	}
+	return nil
+}
+
+func checkGeomRingFlatCoords(geomFlatCoords []float64, geomStride int) error {
+	switch {
+	case len(geomFlatCoords) == 0:
+		return nil
+	case !geomFlatCoordsClosed(geomFlatCoords, geomStride):
+		return newValidityError("*geom.LinearRing", validityReasonUnclosedRing, geomCoordLocation(geomFlatCoords))
+	case len(geomFlatCoords) < 4*geomStride:
+		return newValidityError("*geom.LinearRing", validityReasonTooFewPoints, geomCoordLocation(geomFlatCoords))
+	default:
+		return nil
+	}
+}
+
+func checkGeomPolygonFlatCoords(geomFlatCoords []float64, geomStart int, geomEnds []int, geomStride int) error {
+	for i, geomEnd := range geomEnds {
+		if err := checkGeomRingFlatCoords(geomFlatCoords[geomStart:geomEnd], geomStride); err != nil {
+			return withPathIndex(err, i)
+		}
+		geomStart = geomEnd
+	}
+	return nil
+}
+
+// repairGeomT returns a copy of geomT with unclosed rings closed and rings and
+// line strings with too few points removed.
+func repairGeomT(geomT geom.T) geom.T {
+	geomLayout, geomStride := geomT.Layout(), geomT.Stride()
+	switch geomT := geomT.(type) {
+	case *geom.LineString:
+		if geomT.NumCoords() == 1 {
+			return geom.NewLineString(geomLayout).SetSRID(geomT.SRID())
+		}
+	case *geom.LinearRing:
+		geomFlatCoords, ok := appendRepairedGeomRingFlatCoords(nil, geomT.FlatCoords(), geomStride)
+		if !ok {
+			return geom.NewLinearRing(geomLayout).SetSRID(geomT.SRID())
+		}
+		return geom.NewLinearRingFlat(geomLayout, geomFlatCoords).SetSRID(geomT.SRID())
+	case *geom.Polygon:
+		geomFlatCoords, geomEnds, ok := appendRepairedGeomPolygonFlatCoords(nil, nil, geomT.FlatCoords(), 0, geomT.Ends(), geomStride)
+		if !ok {
+			return geom.NewPolygon(geomLayout).SetSRID(geomT.SRID())
+		}
+		return geom.NewPolygonFlat(geomLayout, geomFlatCoords, geomEnds).SetSRID(geomT.SRID())
+	case *geom.MultiLineString:
+		var geomFlatCoords []float64
+		var geomEnds []int
+		srcFlatCoords := geomT.FlatCoords()
+		srcStart := 0
+		for _, srcEnd := range geomT.Ends() {
+			if srcEnd-srcStart != geomStride {
+				geomFlatCoords = append(geomFlatCoords, srcFlatCoords[srcStart:srcEnd]...)
+				geomEnds = append(geomEnds, len(geomFlatCoords))
+			}
+			srcStart = srcEnd
+		}
+		return geom.NewMultiLineStringFlat(geomLayout, geomFlatCoords, geomEnds).SetSRID(geomT.SRID())
+	case *geom.MultiPolygon:
+		var geomFlatCoords []float64
+		var geomEndss [][]int
+		srcFlatCoords := geomT.FlatCoords()
+		srcStart := 0
+		for _, srcEnds := range geomT.Endss() {
+			if repairedFlatCoords, geomEnds, ok := appendRepairedGeomPolygonFlatCoords(geomFlatCoords, nil, srcFlatCoords, srcStart, srcEnds, geomStride); ok {
+				geomFlatCoords = repairedFlatCoords
+				geomEndss = append(geomEndss, geomEnds)
+			}
+			if len(srcEnds) > 0 {
+				srcStart = srcEnds[len(srcEnds)-1]
+			}
+		}
+		return geom.NewMultiPolygonFlat(geomLayout, geomFlatCoords, geomEndss).SetSRID(geomT.SRID())
+	case *geom.GeometryCollection:
+		geomGeometries := make([]geom.T, 0, geomT.NumGeoms())
+		for _, geomT := range geomT.Geoms() {
+			geomGeometries = append(geomGeometries, repairGeomT(geomT))
+		}
+		return geom.NewGeometryCollection().MustPush(geomGeometries...).SetSRID(geomT.SRID())
+	}
+	return geomT
+}
+
+// appendRepairedGeomRingFlatCoords appends the ring geomFlatCoords to dst,
+// closing it if necessary. It returns false if the ring has too few points.
+func appendRepairedGeomRingFlatCoords(dst, geomFlatCoords []float64, geomStride int) ([]float64, bool) {
+	if len(geomFlatCoords) == 0 {
+		return dst, true
+	}
+	dst = append(dst, geomFlatCoords...)
+	numCoords := len(geomFlatCoords) / geomStride
+	if !geomFlatCoordsClosed(geomFlatCoords, geomStride) {
+		dst = append(dst, geomFlatCoords[:geomStride]...)
+		numCoords++
+	}
+	return dst, numCoords >= 4
+}
+
+// appendRepairedGeomPolygonFlatCoords appends the repaired rings of the polygon
+// in geomFlatCoords to dst and their ends to dstEnds. Interior rings that
+// cannot be repaired are removed. It returns false if the exterior ring cannot
+// be repaired.
+func appendRepairedGeomPolygonFlatCoords(dst []float64, dstEnds []int, geomFlatCoords []float64, geomStart int, geomEnds []int, geomStride int) ([]float64, []int, bool) {
+	for i, geomEnd := range geomEnds {
+		n := len(dst)
+		var ok bool
+		dst, ok = appendRepairedGeomRingFlatCoords(dst, geomFlatCoords[geomStart:geomEnd], geomStride)
+		switch {
+		case ok:
+			dstEnds = append(dstEnds, len(dst))
+		case i == 0:
+			return dst[:n], nil, false
+		default:
+			dst = dst[:n]
+		}
+		geomStart = geomEnd
+	}
+	return dst, dstEnds, true
+}
+
+// geomFlatCoordsClosed returns whether the first and last coordinates of
+// geomFlatCoords have the same X and Y ordinates.
+func geomFlatCoordsClosed(geomFlatCoords []float64, geomStride int) bool {
+	n := len(geomFlatCoords)
+	return geomFlatCoords[0] == geomFlatCoords[n-geomStride] && geomFlatCoords[1] == geomFlatCoords[n-geomStride+1]
+}
+
+func geomCoordLocation(geomCoord []float64) []float64 {
+	return []float64{geomCoord[0], geomCoord[1]}
+}
+
+func orbPointLocation(orbPoint orb.Point) []float64 {
+	return []float64{orbPoint[0], orbPoint[1]}
+}
+
+// checkGEOSGeomValidity returns an error for a geometry of type typ if
+// geosGeom is invalid.
+func checkGEOSGeomValidity(geosGeom *geos.Geom, typ string) error {
+	if geosGeom.IsValid() {
+		return nil
+	}
+	reason, location := parseGEOSValidityReason(geosGeom.IsValidReason())
+	return newValidityError(typ, reason, location)
+}
+
+// parseGEOSValidityReason splits a reason returned by GEOS's IsValidReason,
+// for example "Self-intersection[1 2]", into the reason and location.
+func parseGEOSValidityReason(geosReason string) (string, []float64) {
+	i := strings.LastIndexByte(geosReason, '[')
+	if i == -1 || !strings.HasSuffix(geosReason, "]") {
+		return geosReason, nil
+	}
+	fields := strings.Fields(geosReason[i+1 : len(geosReason)-1])
+	location := make([]float64, 0, len(fields))
+	for _, field := range fields {
+		ordinate, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return geosReason, nil
+		}
+		location = append(location, ordinate)
+	}
+	return geosReason[:i], location
+}
+
+// repairGEOSGeom returns geosGeom made valid by GEOS, converted to a geom.T
+// with geosGeom's type where possible.
+func repairGEOSGeom(geosGeom *geos.Geom) (geom.T, error) {
+	geomT, err := newConverter(nil).newGeomTFromGEOSGeom(geosGeom.MakeValid())
+	if err != nil {
+		return nil, err
+	}
+	return geomTWithGEOSTypeID(geomT, geosGeom.TypeID()), nil
+}
+
+// geomTWithGEOSTypeID returns geomT, which was made valid by GEOS, with the
+// type identified by typeID where possible. GEOS's MakeValid can split a
+// geometry into several parts and collapse parts to lower dimensions. Parts
+// with a different dimension to typeID are dropped, and several parts of a
+// single geometry are returned as a multi geometry.
+func geomTWithGEOSTypeID(geomT geom.T, typeID geos.TypeID) geom.T {
+	geomLayout, srid := geomT.Layout(), geomT.SRID()
+	var geomParts []geom.T
+	switch typeID {
+	case geos.TypeIDPoint, geos.TypeIDMultiPoint:
+		geomParts = appendGeomTParts(nil, geomT, 0, geomLayout)
+		switch {
+		case typeID == geos.TypeIDPoint && len(geomParts) == 0:
+			return geom.NewPointEmpty(geomLayout).SetSRID(srid)
+		case typeID == geos.TypeIDPoint && len(geomParts) == 1:
+			return geomParts[0].(*geom.Point).SetSRID(srid)
+		}
+		geomMultiPoint := geom.NewMultiPoint(geomLayout).SetSRID(srid)
+		for _, geomPart := range geomParts {
+			_ = geomMultiPoint.Push(geomPart.(*geom.Point))
+		}
+		return geomMultiPoint
+	case geos.TypeIDLineString, geos.TypeIDLinearRing, geos.TypeIDMultiLineString:
+		geomParts = appendGeomTParts(nil, geomT, 1, geomLayout)
+		switch {
+		case typeID == geos.TypeIDLineString && len(geomParts) == 0:
+			return geom.NewLineString(geomLayout).SetSRID(srid)
+		case typeID == geos.TypeIDLinearRing && len(geomParts) == 0:
+			return geom.NewLinearRing(geomLayout).SetSRID(srid)
+		case typeID == geos.TypeIDLineString && len(geomParts) == 1:
+			return geom.NewLineStringFlat(geomLayout, geomParts[0].FlatCoords()).SetSRID(srid)
+		case typeID == geos.TypeIDLinearRing && len(geomParts) == 1:
+			return geom.NewLinearRingFlat(geomLayout, geomParts[0].FlatCoords()).SetSRID(srid)
+		}
+		geomMultiLineString := geom.NewMultiLineString(geomLayout).SetSRID(srid)
+		for _, geomPart := range geomParts {
+			_ = geomMultiLineString.Push(geom.NewLineStringFlat(geomLayout, geomPart.FlatCoords()))
+		}
+		return geomMultiLineString
+	case geos.TypeIDPolygon, geos.TypeIDMultiPolygon:
+		geomParts = appendGeomTParts(nil, geomT, 2, geomLayout)
+		switch {
+		case typeID == geos.TypeIDPolygon && len(geomParts) == 0:
+			return geom.NewPolygon(geomLayout).SetSRID(srid)
+		case typeID == geos.TypeIDPolygon && len(geomParts) == 1:
+			return geomParts[0].(*geom.Polygon).SetSRID(srid)
+		}
+		geomMultiPolygon := geom.NewMultiPolygon(geomLayout).SetSRID(srid)
+		for _, geomPart := range geomParts {
+			_ = geomMultiPolygon.Push(geomPart.(*geom.Polygon))
+		}
+		return geomMultiPolygon
+	default:
+		return geomT
+	}
+}
+
+// appendGeomTParts appends the non-empty points, line strings, or polygons of
+// geomT with dimension to dst, converted to geomLayout.
+func appendGeomTParts(dst []geom.T, geomT geom.T, dimension int, geomLayout geom.Layout) []geom.T {
+	appendPart := func(geomPart geom.T) {
+		if geomPart.Empty() {
+			return
+		}
+		if geomPart.Layout() != geomLayout {
+			geomPart = geomTWithLayout(geomPart, geomLayout)
+		}
+		dst = append(dst, geomPart)
+	}
+	switch geomT := geomT.(type) {
+	case *geom.Point:
+		if dimension == 0 {
+			appendPart(geomT)
+		}
+	case *geom.MultiPoint:
+		if dimension == 0 {
+			for i := 0; i < geomT.NumPoints(); i++ {
+				appendPart(geomT.Point(i))
+			}
+		}
+	case *geom.LineString, *geom.LinearRing:
+		if dimension == 1 {
+			appendPart(geomT)
+		}
+	case *geom.MultiLineString:
+		if dimension == 1 {
+			for i := 0; i < geomT.NumLineStrings(); i++ {
+				appendPart(geomT.LineString(i))
+			}
+		}
+	case *geom.Polygon:
+		if dimension == 2 {
+			appendPart(geomT)
+		}
+	case *geom.MultiPolygon:
+		if dimension == 2 {
+			for i := 0; i < geomT.NumPolygons(); i++ {
+				appendPart(geomT.Polygon(i))
+			}
+		}
+	case *geom.GeometryCollection:
+		for _, geomT := range geomT.Geoms() {
+			dst = appendGeomTParts(dst, geomT, dimension, geomLayout)
+		}
+	}
+	return dst
+}
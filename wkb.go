@@ -7,31 +7,150 @@ import (
 	orbwkb "github.com/paulmach/orb/encoding/wkb"
 	"github.com/twpayne/go-geom"
 	geomwkb "github.com/twpayne/go-geom/encoding/wkb"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
 	"github.com/twpayne/go-geos"
 )
 
-var wkbByteOrder = binary.LittleEndian
+var (
+	wkbByteOrder = binary.LittleEndian
 
-func NewGEOSGeomFromWKB(geosContext *geos.Context, wkb []byte) (*geos.Geom, error) {
-	return geosContext.NewGeomFromWKB(wkb)
+	// geomWKBEmptyPointHandling encodes empty points as points with NaN
+	// coordinates, like GEOS and PostGIS.
+	geomWKBEmptyPointHandling = wkbcommon.WKBOptionEmptyPointHandling(wkbcommon.EmptyPointHandlingNaN)
+)
+
+// NewGEOSGeomFromWKB returns a new *geos.Geom parsed from wkb. Linear rings
+// are decoded according to the linear ring policy.
+func NewGEOSGeomFromWKB(geosContext *geos.Context, wkb []byte, options ...Option) (*geos.Geom, error) {
+	geosGeom, err := geosContext.NewGeomFromWKB(wkb)
+	if err != nil {
+		return nil, err
+	}
+	return newConverter(options).geosGeomWithLinearRings(geosContext, geosGeom), nil
+}
+
+// NewGeomTFromWKB returns a new geom.T parsed from wkb. Linear rings are
+// decoded according to the linear ring policy.
+func NewGeomTFromWKB(wkb []byte, options ...Option) (geom.T, error) {
+	geomT, err := geomwkb.Unmarshal(wkb, geomWKBEmptyPointHandling)
+	if err != nil {
+		return nil, err
+	}
+	return newConverter(options).geomTWithLinearRings(geomNormalizeEmptyPoints(geomT)), nil
 }
 
-func NewGeomTFromWKB(wkb []byte) (geom.T, error) {
-	return geomwkb.Unmarshal(wkb)
+// NewOrbGeometryFromWKB returns a new orb.Geometry parsed from wkb. Linear
+// rings are decoded according to the linear ring policy.
+func NewOrbGeometryFromWKB(wkb []byte, options ...Option) (orb.Geometry, error) {
+	orbGeometry, err := orbwkb.Unmarshal(wkb)
+	if err != nil {
+		return nil, err
+	}
+	return newConverter(options).orbGeometryWithRings(orbGeometry), nil
+}
+
+// The WKBFrom functions encode the same geometry to the same bytes regardless
+// of the library that it came from. The encoding is set with WithWKBByteOrder,
+// WithWKBFlavor, WithWKBLayout, and WithWKBSRID, and linear rings are encoded
+// according to WithLinearRingPolicy. For compatibility, WKBFromGEOSGeom and
+// WKBFromOrbGeometry encode linear rings like GEOS and orb respectively unless
+// another policy is given.
+
+func WKBFromGEOSGeom(geosGeom *geos.Geom, options ...Option) []byte {
+	options = append([]Option{WithLinearRingPolicy(LinearRingPolicyLineString)}, options...)
+	wkb, err := WKBFromGEOSGeomE(geosGeom, options...)
+	if err != nil {
+		panic(err)
+	}
+	return wkb
 }
 
-func NewOrbGeometryFromWKB(wkb []byte) (orb.Geometry, error) {
-	return orbwkb.Unmarshal(wkb)
+// WKBFromGEOSGeomE returns geosGeom encoded as WKB. It returns a *ConvertError
+// if geosGeom cannot be encoded.
+func WKBFromGEOSGeomE(geosGeom *geos.Geom, options ...Option) ([]byte, error) {
+	c := newConverter(options)
+	geomT, err := c.newGeomTFromGEOSGeomE(geosGeom)
+	if err != nil {
+		return nil, err
+	}
+	return c.marshalWKB(geomT)
 }
 
-func WKBFromGEOSGeom(geosGeom *geos.Geom) []byte {
-	return geosGeom.ToWKB()
+// WKBFromGeomT returns geomT encoded as WKB. It returns a *ConvertError if
+// geomT cannot be encoded.
+func WKBFromGeomT(geomT geom.T, options ...Option) ([]byte, error) {
+	return newConverter(options).wkbFromGeomT(geomT)
 }
 
-func WKBFromGeomT(geomT geom.T) ([]byte, error) {
-	return geomwkb.Marshal(geomT, wkbByteOrder)
+func WKBFromOrbGeometry(orbGeometry orb.Geometry, options ...Option) []byte {
+	options = append([]Option{WithLinearRingPolicy(LinearRingPolicyPolygon)}, options...)
+	wkb, err := WKBFromOrbGeometryE(orbGeometry, options...)
+	if err != nil {
+		panic(err)
+	}
+	return wkb
 }
 
-func WKBFromOrbGeometry(orbGeometry orb.Geometry) []byte {
-	return orbwkb.MustMarshal(orbGeometry, wkbByteOrder)
+// WKBFromOrbGeometryE returns orbGeometry encoded as WKB. It returns a
+// *ConvertError if orbGeometry cannot be encoded.
+func WKBFromOrbGeometryE(orbGeometry orb.Geometry, options ...Option) ([]byte, error) {
+	c := newConverter(options)
+	geomT, err := c.newGeomTFromOrbGeometryE(orbGeometry)
+	if err != nil {
+		return nil, err
+	}
+	return c.marshalWKB(geomT)
 }
+
+// geomNormalizeEmptyPoints returns geomT with points whose coordinates are all
+// NaN replaced by empty points. go-geom only recognizes empty points encoded
+// with its own NaN bit pattern, which differs from the one used by GEOS.
+func geomNormalizeEmptyPoints(geomT geom.T) geom.T {
+	switch geomT := geomT.(type) {
+	case *geom.Point:
+		if !geomT.Empty() && geomFlatCoordsAllNaN(geomT.FlatCoords(), 1, 0) {
+			return geom.NewPointEmpty(geomT.Layout())
+		}
+	case *geom.MultiPoint:
+		geomNumPoints := geomT.NumPoints()
+		geomFlatCoords := make([]float64, 0, len(geomT.FlatCoords()))
+		geomEnds := make([]int, 0, geomNumPoints)
+		normalized := false
+		for i := 0; i < geomNumPoints; i++ {
+			switch geomCoord := geomT.Coord(i); {
+			case geomCoord == nil:
+			case geomFlatCoordsAllNaN(geomCoord, 1, 0):
+				normalized = true
+			default:
+				geomFlatCoords = append(geomFlatCoords, geomCoord...)
+			}
+			geomEnds = append(geomEnds, len(geomFlatCoords))
+		}
+		if normalized {
+			return geom.NewMultiPointFlat(geomT.Layout(), geomFlatCoords, geom.NewMultiPointFlatOptionWithEnds(geomEnds))
+		}
+	case *geom.GeometryCollection:
+		for i, geomGeom := range geomT.Geoms() {
+			geomT.Geoms()[i] = geomNormalizeEmptyPoints(geomGeom)
+		}
+	}
+	return geomT
+}
+
+// WKB geometry types.
+const (
+	wkbTypePoint              = 1
+	wkbTypeLineString         = 2
+	wkbTypePolygon            = 3
+	wkbTypeMultiPoint         = 4
+	wkbTypeMultiLineString    = 5
+	wkbTypeMultiPolygon       = 6
+	wkbTypeGeometryCollection = 7
+)
+
+// EWKB flags in the geometry type.
+const (
+	ewkbFlagZ    = 0x80000000
+	ewkbFlagM    = 0x40000000
+	ewkbFlagSRID = 0x20000000
+)
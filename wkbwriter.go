@@ -0,0 +1,240 @@
+package geobabel
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// All WKB encoders convert their source to a geom.T and encode it with a
+// wkbWriter, so the same geometry is encoded to the same bytes regardless of
+// the library that it came from. NaNs, including the coordinates of empty
+// points, are always encoded with the same bit pattern as GEOS and PostGIS.
+
+// wkbNaNBits is the bit pattern of NaNs in encoded WKB.
+const wkbNaNBits = 0x7ff8000000000000
+
+// A wkbWriter encodes geom.Ts as WKB.
+type wkbWriter struct {
+	byteOrder        binary.AppendByteOrder
+	byteOrderMarker  byte
+	flavor           WKBFlavor
+	layout           geom.Layout
+	srid             bool
+	linearRingPolicy LinearRingPolicy
+}
+
+// newWKBWriter returns a new wkbWriter with c's WKB options.
+func (c *converter) newWKBWriter() *wkbWriter {
+	w := &wkbWriter{
+		byteOrder:        binary.LittleEndian,
+		byteOrderMarker:  1,
+		flavor:           c.wkbFlavor,
+		layout:           c.wkbLayout,
+		srid:             c.wkbSRID && c.wkbFlavor == WKBFlavorExtended,
+		linearRingPolicy: c.linearRingPolicy,
+	}
+	if c.wkbByteOrder == binary.BigEndian {
+		w.byteOrder = binary.BigEndian
+		w.byteOrderMarker = 0
+	}
+	return w
+}
+
+// wkbFromGeomT returns geomT encoded as WKB with c's WKB options, transformed
+// like conversions from GEOS and orb.
+func (c *converter) wkbFromGeomT(geomT geom.T) ([]byte, error) {
+	return c.marshalWKB(c.transformGeomT(geomT))
+}
+
+// marshalWKB returns geomT, which has already been transformed, encoded as WKB
+// with c's WKB options.
+func (c *converter) marshalWKB(geomT geom.T) ([]byte, error) {
+	w := c.newWKBWriter()
+	layout := w.layout
+	if layout == geom.NoLayout {
+		layout = geomT.Layout()
+	}
+	if layout == geom.NoLayout {
+		// Empty geometry collections have no layout.
+		layout = geom.XY
+	}
+	srid := 0
+	if w.srid {
+		srid = geomT.SRID()
+	}
+	return w.appendGeomT(nil, geomT, layout, srid)
+}
+
+// appendGeomT appends the WKB encoding of geomT with layout to dst. Only the
+// top-level geometry has an SRID.
+func (w *wkbWriter) appendGeomT(dst []byte, geomT geom.T, layout geom.Layout, srid int) ([]byte, error) {
+	switch geomT := geomT.(type) {
+	case *geom.Point:
+		dst = w.appendHeader(dst, wkbTypePoint, layout, srid)
+		if geomT.Empty() {
+			return w.appendCoord(dst, nil, geomT.Layout(), layout), nil
+		}
+		return w.appendCoord(dst, geomT.FlatCoords(), geomT.Layout(), layout), nil
+	case *geom.LineString:
+		dst = w.appendHeader(dst, wkbTypeLineString, layout, srid)
+		return w.appendFlatCoords(dst, geomT.FlatCoords(), geomT.Layout(), layout), nil
+	case *geom.LinearRing:
+		switch w.linearRingPolicy {
+		case LinearRingPolicyLineString:
+			dst = w.appendHeader(dst, wkbTypeLineString, layout, srid)
+			return w.appendFlatCoords(dst, geomT.FlatCoords(), geomT.Layout(), layout), nil
+		case LinearRingPolicyPolygon:
+			dst = w.appendHeader(dst, wkbTypePolygon, layout, srid)
+			if geomT.Empty() {
+				return w.byteOrder.AppendUint32(dst, 0), nil
+			}
+			return w.appendFlatCoordsEnds(dst, geomT.FlatCoords(), 0, []int{len(geomT.FlatCoords())}, geomT.Layout(), layout), nil
+		default:
+			return nil, newUnsupportedTypeError(fmt.Sprintf("%T", geomT))
+		}
+	case *geom.Polygon:
+		dst = w.appendHeader(dst, wkbTypePolygon, layout, srid)
+		return w.appendFlatCoordsEnds(dst, geomT.FlatCoords(), 0, geomT.Ends(), geomT.Layout(), layout), nil
+	case *geom.MultiPoint:
+		dst = w.appendHeader(dst, wkbTypeMultiPoint, layout, srid)
+		geomNumPoints := geomT.NumPoints()
+		dst = w.byteOrder.AppendUint32(dst, uint32(geomNumPoints))
+		for i := 0; i < geomNumPoints; i++ {
+			dst = w.appendHeader(dst, wkbTypePoint, layout, 0)
+			dst = w.appendCoord(dst, geomT.Coord(i), geomT.Layout(), layout)
+		}
+		return dst, nil
+	case *geom.MultiLineString:
+		dst = w.appendHeader(dst, wkbTypeMultiLineString, layout, srid)
+		geomFlatCoords := geomT.FlatCoords()
+		geomEnds := geomT.Ends()
+		dst = w.byteOrder.AppendUint32(dst, uint32(len(geomEnds)))
+		geomStart := 0
+		for _, geomEnd := range geomEnds {
+			dst = w.appendHeader(dst, wkbTypeLineString, layout, 0)
+			dst = w.appendFlatCoords(dst, geomFlatCoords[geomStart:geomEnd], geomT.Layout(), layout)
+			geomStart = geomEnd
+		}
+		return dst, nil
+	case *geom.MultiPolygon:
+		dst = w.appendHeader(dst, wkbTypeMultiPolygon, layout, srid)
+		geomFlatCoords := geomT.FlatCoords()
+		geomEndss := geomT.Endss()
+		dst = w.byteOrder.AppendUint32(dst, uint32(len(geomEndss)))
+		geomStart := 0
+		for _, geomEnds := range geomEndss {
+			dst = w.appendHeader(dst, wkbTypePolygon, layout, 0)
+			dst = w.appendFlatCoordsEnds(dst, geomFlatCoords, geomStart, geomEnds, geomT.Layout(), layout)
+			if len(geomEnds) > 0 {
+				geomStart = geomEnds[len(geomEnds)-1]
+			}
+		}
+		return dst, nil
+	case *geom.GeometryCollection:
+		dst = w.appendHeader(dst, wkbTypeGeometryCollection, layout, srid)
+		dst = w.byteOrder.AppendUint32(dst, uint32(geomT.NumGeoms()))
+		for i, geomGeom := range geomT.Geoms() {
+			var err error
+			if dst, err = w.appendGeomT(dst, geomGeom, layout, 0); err != nil {
+				return nil, withPathIndex(err, i)
+			}
+		}
+		return dst, nil
+	default:
+		return nil, newUnsupportedTypeError(fmt.Sprintf("%T", geomT))
+	}
+}
+
+// appendHeader appends the byte order and geometry type of a geometry with
+// wkbType and layout, and srid if it is not zero, to dst.
+func (w *wkbWriter) appendHeader(dst []byte, wkbType uint32, layout geom.Layout, srid int) []byte {
+	dst = append(dst, w.byteOrderMarker)
+	switch w.flavor {
+	case WKBFlavorExtended:
+		if layout.ZIndex() != -1 {
+			wkbType |= ewkbFlagZ
+		}
+		if layout.MIndex() != -1 {
+			wkbType |= ewkbFlagM
+		}
+		if srid != 0 {
+			wkbType |= ewkbFlagSRID
+		}
+		dst = w.byteOrder.AppendUint32(dst, wkbType)
+		if srid != 0 {
+			dst = w.byteOrder.AppendUint32(dst, uint32(srid))
+		}
+		return dst
+	default:
+		switch layout {
+		case geom.XYZ:
+			wkbType += 1000
+		case geom.XYM:
+			wkbType += 2000
+		case geom.XYZM:
+			wkbType += 3000
+		}
+		return w.byteOrder.AppendUint32(dst, wkbType)
+	}
+}
+
+// appendCoord appends geomCoord, which has geomLayout, to dst with layout. An
+// empty geomCoord is appended as NaNs.
+func (w *wkbWriter) appendCoord(dst []byte, geomCoord []float64, geomLayout, layout geom.Layout) []byte {
+	if len(geomCoord) == 0 {
+		for i := 0; i < layout.Stride(); i++ {
+			dst = w.appendFloat64(dst, math.NaN())
+		}
+		return dst
+	}
+	dst = w.appendFloat64(dst, geomCoord[0])
+	dst = w.appendFloat64(dst, geomCoord[1])
+	if layout.ZIndex() != -1 {
+		dst = w.appendOrdinate(dst, geomCoord, geomLayout.ZIndex())
+	}
+	if layout.MIndex() != -1 {
+		dst = w.appendOrdinate(dst, geomCoord, geomLayout.MIndex())
+	}
+	return dst
+}
+
+// appendFlatCoords appends the number of points in geomFlatCoords, which has
+// geomLayout, and the points to dst with layout.
+func (w *wkbWriter) appendFlatCoords(dst []byte, geomFlatCoords []float64, geomLayout, layout geom.Layout) []byte {
+	geomStride := geomLayout.Stride()
+	dst = w.byteOrder.AppendUint32(dst, uint32(len(geomFlatCoords)/geomStride))
+	for i := 0; i < len(geomFlatCoords); i += geomStride {
+		dst = w.appendCoord(dst, geomFlatCoords[i:i+geomStride], geomLayout, layout)
+	}
+	return dst
+}
+
+// appendFlatCoordsEnds appends the number of rings in geomFlatCoords starting
+// at geomStart with geomEnds, and the rings, to dst with layout.
+func (w *wkbWriter) appendFlatCoordsEnds(dst []byte, geomFlatCoords []float64, geomStart int, geomEnds []int, geomLayout, layout geom.Layout) []byte {
+	dst = w.byteOrder.AppendUint32(dst, uint32(len(geomEnds)))
+	for _, geomEnd := range geomEnds {
+		dst = w.appendFlatCoords(dst, geomFlatCoords[geomStart:geomEnd], geomLayout, layout)
+		geomStart = geomEnd
+	}
+	return dst
+}
+
+// appendOrdinate appends the ordinate of geomCoord at index, or zero if index
+// is -1, to dst.
+func (w *wkbWriter) appendOrdinate(dst []byte, geomCoord []float64, index int) []byte {
+	if index == -1 {
+		return w.appendFloat64(dst, 0)
+	}
+	return w.appendFloat64(dst, geomCoord[index])
+}
+
+func (w *wkbWriter) appendFloat64(dst []byte, f float64) []byte {
+	if math.IsNaN(f) {
+		return w.byteOrder.AppendUint64(dst, wkbNaNBits)
+	}
+	return w.byteOrder.AppendUint64(dst, math.Float64bits(f))
+}
@@ -0,0 +1,181 @@
+package geobabel
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/paulmach/orb"
+	"github.com/twpayne/go-geom"
+	geomwkt "github.com/twpayne/go-geom/encoding/wkt"
+	"github.com/twpayne/go-geos"
+)
+
+// All WKT is parsed and formatted by go-geom so that the result does not
+// depend on which library produced a geometry. Coordinates are formatted with
+// trailing zeros removed, empty geometries are written as EMPTY, and
+// LinearRings are written as LINESTRINGs.
+
+// ewktSRIDPrefix is the prefix of the SRID in EWKT.
+const ewktSRIDPrefix = "SRID="
+
+// NewGEOSGeomFromWKT returns a new *geos.Geom parsed from wkt.
+func NewGEOSGeomFromWKT(geosContext *geos.Context, wkt string, options ...Option) (*geos.Geom, error) {
+	geomT, err := NewGeomTFromWKT(wkt)
+	if err != nil {
+		return nil, err
+	}
+	return NewGEOSGeomFromGeomTE(geosContext, geomT, options...)
+}
+
+// NewGeomTFromWKT returns a new geom.T parsed from wkt.
+func NewGeomTFromWKT(wkt string) (geom.T, error) {
+	return geomwkt.Unmarshal(wkt)
+}
+
+// NewOrbGeometryFromWKT returns a new orb.Geometry parsed from wkt.
+func NewOrbGeometryFromWKT(wkt string, options ...Option) (orb.Geometry, error) {
+	geomT, err := NewGeomTFromWKT(wkt)
+	if err != nil {
+		return nil, err
+	}
+	return NewOrbGeometryFromGeomTE(geomT, options...)
+}
+
+// WKTFromGEOSGeom returns geosGeom encoded as WKT.
+func WKTFromGEOSGeom(geosGeom *geos.Geom, options ...Option) (string, error) {
+	c := newConverter(options)
+	geomT, err := c.newGeomTFromGEOSGeomE(geosGeom)
+	if err != nil {
+		return "", err
+	}
+	return c.marshalWKT(geomT)
+}
+
+// WKTFromGeomT returns geomT encoded as WKT.
+func WKTFromGeomT(geomT geom.T, options ...Option) (string, error) {
+	return newConverter(options).wktFromGeomT(geomT)
+}
+
+// WKTFromOrbGeometry returns orbGeometry encoded as WKT.
+func WKTFromOrbGeometry(orbGeometry orb.Geometry, options ...Option) (string, error) {
+	c := newConverter(options)
+	geomT, err := c.newGeomTFromOrbGeometryE(orbGeometry)
+	if err != nil {
+		return "", err
+	}
+	return c.marshalWKT(geomT)
+}
+
+// NewGEOSGeomFromEWKT returns a new *geos.Geom parsed from ewkt. The SRID, if
+// any, is set on the returned geometry.
+func NewGEOSGeomFromEWKT(geosContext *geos.Context, ewkt string, options ...Option) (*geos.Geom, error) {
+	geomT, err := NewGeomTFromEWKT(ewkt)
+	if err != nil {
+		return nil, err
+	}
+	return NewGEOSGeomFromGeomTE(geosContext, geomT, options...)
+}
+
+// NewGeomTFromEWKT returns a new geom.T parsed from ewkt. The SRID, if any, is
+// set on the returned geometry.
+func NewGeomTFromEWKT(ewkt string) (geom.T, error) {
+	srid, wkt, err := splitEWKT(ewkt)
+	if err != nil {
+		return nil, err
+	}
+	geomT, err := NewGeomTFromWKT(wkt)
+	if err != nil {
+		return nil, err
+	}
+	return geom.SetSRID(geomT, srid)
+}
+
+// NewOrbGeometryFromEWKT returns a new orb.Geometry and SRID parsed from ewkt.
+func NewOrbGeometryFromEWKT(ewkt string, options ...Option) (orb.Geometry, int, error) {
+	srid, wkt, err := splitEWKT(ewkt)
+	if err != nil {
+		return nil, 0, err
+	}
+	orbGeometry, err := NewOrbGeometryFromWKT(wkt, options...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return orbGeometry, srid, nil
+}
+
+// EWKTFromGEOSGeom returns geosGeom encoded as EWKT.
+func EWKTFromGEOSGeom(geosGeom *geos.Geom, options ...Option) (string, error) {
+	c := newConverter(options)
+	geomT, err := c.newGeomTFromGEOSGeomE(geosGeom)
+	if err != nil {
+		return "", err
+	}
+	return c.marshalEWKT(geomT, geomT.SRID())
+}
+
+// EWKTFromGeomT returns geomT encoded as EWKT.
+func EWKTFromGeomT(geomT geom.T, options ...Option) (string, error) {
+	c := newConverter(options)
+	geomT = c.transformGeomT(geomT)
+	return c.marshalEWKT(geomT, geomT.SRID())
+}
+
+// EWKTFromOrbGeometry returns orbGeometry encoded as EWKT with srid.
+func EWKTFromOrbGeometry(orbGeometry orb.Geometry, srid int, options ...Option) (string, error) {
+	c := newConverter(options)
+	geomT, err := c.newGeomTFromOrbGeometryE(orbGeometry)
+	if err != nil {
+		return "", err
+	}
+	return c.marshalEWKT(geomT, c.targetSRID(srid))
+}
+
+// wktFromGeomT returns geomT encoded as WKT, transformed like conversions
+// from GEOS and orb.
+func (c *converter) wktFromGeomT(geomT geom.T) (string, error) {
+	return c.marshalWKT(c.transformGeomT(geomT))
+}
+
+// marshalEWKT returns geomT, which has already been transformed, encoded as
+// EWKT with srid.
+func (c *converter) marshalEWKT(geomT geom.T, srid int) (string, error) {
+	wkt, err := c.marshalWKT(geomT)
+	if err != nil {
+		return "", err
+	}
+	return joinEWKT(srid, wkt), nil
+}
+
+// marshalWKT returns geomT, which has already been transformed, encoded as
+// WKT.
+func (c *converter) marshalWKT(geomT geom.T) (string, error) {
+	return geomwkt.Marshal(geomT, geomwkt.EncodeOptionWithMaxDecimalDigits(c.maxDecimalDigits))
+}
+
+// joinEWKT returns the EWKT for wkt with srid. The SRID prefix is omitted if
+// srid is zero.
+func joinEWKT(srid int, wkt string) string {
+	if srid == 0 {
+		return wkt
+	}
+	return ewktSRIDPrefix + strconv.Itoa(srid) + ";" + wkt
+}
+
+// splitEWKT splits ewkt into its SRID and WKT. The SRID is zero if ewkt has no
+// SRID prefix.
+func splitEWKT(ewkt string) (int, string, error) {
+	if len(ewkt) < len(ewktSRIDPrefix) || !strings.EqualFold(ewkt[:len(ewktSRIDPrefix)], ewktSRIDPrefix) {
+		return 0, ewkt, nil
+	}
+	sridStr, wkt, ok := strings.Cut(ewkt[len(ewktSRIDPrefix):], ";")
+	if !ok {
+		return 0, "", errors.New("invalid EWKT: missing semicolon after SRID")
+	}
+	srid, err := strconv.Atoi(strings.TrimSpace(sridStr))
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid EWKT SRID: %w", err)
+	}
+	return srid, wkt, nil
+}